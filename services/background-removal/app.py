@@ -32,7 +32,9 @@ def remove_bg():
 
     image = request.files['image']
     original_filename = image.filename
-    app.logger.info(f"Image received: {original_filename}")
+    # alpha_matting tinh chỉnh viền chủ thể (tóc, lông) nhưng chậm hơn
+    alpha_matting = request.form.get('alpha_matting', '').lower() in ('1', 'true')
+    app.logger.info(f"Image received: {original_filename}, alpha_matting={alpha_matting}")
 
     # Đường dẫn thư mục lưu ảnh đã xử lý
     today = datetime.now().strftime('%Y-%m-%d')
@@ -54,7 +56,7 @@ def remove_bg():
         app.logger.info("Removing background...")
         with open(image_path, 'rb') as input_file:
             input_data = input_file.read()
-            output_data = remove(input_data)
+            output_data = remove(input_data, alpha_matting=alpha_matting)
         app.logger.info("Background removed successfully")
 
         # Lưu ảnh đã xử lý
@@ -0,0 +1,44 @@
+// fake-backends chạy các backend AI giả (testing/fakes) để chạy management-api ở máy local không cần model.
+// Lệnh in ra các biến môi trường trỏ management-api tới backend giả và chạy cho tới khi bị dừng (Ctrl+C).
+//
+//	go run ./cmd/fake-backends -shared ./shared
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"management-api/testing/fakes"
+)
+
+func main() {
+	shared := flag.String("shared", "./shared", "thư mục ảnh dùng chung, đặt giống SHARED_IMAGE_PATH của management-api")
+	flag.Parse()
+
+	if err := os.MkdirAll(*shared, 0755); err != nil {
+		log.Fatalf("Could not create shared image directory: %v", err)
+	}
+	backends := fakes.Start(*shared)
+	defer backends.Close()
+
+	cfg := backends.Config()
+	for _, env := range []struct{ key, value string }{
+		{"TEXT_TO_VOICE_URL", cfg.TextToVoiceURL},
+		{"VOICE_TO_TEXT_URL", cfg.VoiceToTextURL},
+		{"BACKGROUND_REMOVAL_URL", cfg.BackgroundRemovalURL},
+		{"SPEECH_RECOGNITION_URL", cfg.SpeechRecognitionURL},
+		{"FACE_RECOGNITION_URL", cfg.FaceRecognitionURL},
+		{"OCR_URL", cfg.OCRURL},
+		{"TRANSLATION_URL", cfg.TranslationURL},
+		{"SHARED_IMAGE_PATH", *shared},
+	} {
+		fmt.Printf("export %s=%s\n", env.key, env.value)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+}
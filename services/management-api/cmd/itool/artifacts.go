@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// runArtifact in thông tin artifact và tải file của artifact nếu có -o
+func runArtifact(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("artifact")
+	output := fs.String("o", "", "save the file to this path (- for stdout)")
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		return errors.New("usage: itool artifact <id> [-o file]")
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	c := opts.client()
+	artifact, err := c.GetArtifact(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		if err := download(ctx, c, artifact.URL, *output); err != nil {
+			return fmt.Errorf("failed to download artifact: %w", err)
+		}
+	}
+	if opts.json {
+		return printJSON(artifact)
+	}
+	fields := [][2]string{
+		{"Artifact", artifact.ID},
+		{"Task", strconv.Itoa(artifact.TaskID)},
+		{"Type", artifact.ContentType},
+		{"Bytes", strconv.FormatInt(artifact.Size, 10)},
+	}
+	if artifact.Width > 0 {
+		fields = append(fields, [2]string{"Size", fmt.Sprintf("%dx%d", artifact.Width, artifact.Height)})
+	}
+	fields = append(fields, [2]string{"URL", artifact.URL})
+	if *output != "" && *output != "-" {
+		fields = append(fields, [2]string{"Saved to", *output})
+	}
+	return printFields(fields)
+}
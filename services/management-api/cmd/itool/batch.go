@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"management-api/pkg/client"
+
+	"gopkg.in/yaml.v3"
+)
+
+const batchUsage = "usage: itool batch run <manifest.yaml> [--no-wait] | get <id>"
+
+// manifest là file YAML mô tả một batch, ví dụ:
+//
+//	tool: translate
+//	params:
+//	  dest_lang: vi
+//	inputs:
+//	  - text: Hello
+//	  - url: http://example.com/page.txt
+//	files:
+//	  - page1.png
+//
+// Đường dẫn trong files là tương đối với thư mục chứa manifest.
+type manifest struct {
+	Tool   string             `yaml:"tool"`
+	Params client.ToolInput   `yaml:"params"`
+	Inputs []client.ToolInput `yaml:"inputs"`
+	Files  []string           `yaml:"files"`
+}
+
+func runBatch(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(batchUsage)
+	}
+	switch args[0] {
+	case "run":
+		return runBatchRun(ctx, args[1:])
+	case "get":
+		return runBatchGet(ctx, args[1:])
+	}
+	return fmt.Errorf("unknown batch command '%s'\n%s", args[0], batchUsage)
+}
+
+func runBatchRun(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("batch run")
+	noWait := fs.Bool("no-wait", false, "print the batch and exit without waiting for it to finish")
+	interval := fs.Duration("interval", 2*time.Second, "polling interval")
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		return errors.New(batchUsage)
+	}
+
+	m, err := loadManifest(positional[0])
+	if err != nil {
+		return err
+	}
+
+	req := client.BatchRequest{Tool: m.Tool, Params: m.Params, Inputs: m.Inputs}
+	dir := filepath.Dir(positional[0])
+	for _, name := range m.Files {
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		req.Files = append(req.Files, client.File{Name: filepath.Base(path), Reader: f})
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	c := opts.client()
+	batch, err := c.CreateBatch(ctx, req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Created batch %d (%s, %d tasks)\n", batch.ID, batch.Tool, batch.Progress.Total)
+	if *noWait {
+		if opts.json {
+			return printJSON(batch)
+		}
+		return printBatch(&client.BatchDetail{Batch: *batch})
+	}
+
+	for {
+		detail, err := c.GetBatch(ctx, batch.ID)
+		if err != nil {
+			return err
+		}
+		p := detail.Batch.Progress
+		fmt.Fprintf(os.Stderr, "\r%5.1f%%  %d/%d done, %d failed", p.Percent, p.Completed+p.Failed+p.Cancelled+p.Expired, p.Total, p.Failed)
+		if client.BatchFinished(detail.Batch.Status) {
+			fmt.Fprintln(os.Stderr)
+			if opts.json {
+				return printJSON(detail)
+			}
+			return printBatch(detail)
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(os.Stderr)
+			return ctx.Err()
+		case <-time.After(*interval):
+		}
+	}
+}
+
+func runBatchGet(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("batch get")
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		return errors.New(batchUsage)
+	}
+	id, err := strconv.Atoi(positional[0])
+	if err != nil || id <= 0 {
+		return fmt.Errorf("invalid batch ID '%s'", positional[0])
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	detail, err := opts.client().GetBatch(ctx, id)
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(detail)
+	}
+	return printBatch(detail)
+}
+
+func loadManifest(path string) (*manifest, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := yaml.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	if m.Tool == "" {
+		return nil, fmt.Errorf("invalid manifest %s: missing 'tool'", path)
+	}
+	if len(m.Inputs) == 0 && len(m.Files) == 0 {
+		return nil, fmt.Errorf("invalid manifest %s: missing 'inputs' or 'files'", path)
+	}
+	return &m, nil
+}
+
+func printBatch(detail *client.BatchDetail) error {
+	b := detail.Batch
+	p := b.Progress
+	err := printFields([][2]string{
+		{"Batch", strconv.Itoa(b.ID)},
+		{"Tool", b.Tool},
+		{"Status", b.Status},
+		{"Progress", fmt.Sprintf("%.1f%% (%d completed, %d failed, %d cancelled of %d)", p.Percent, p.Completed, p.Failed, p.Cancelled, p.Total)},
+	})
+	if err != nil || len(detail.Tasks) == 0 {
+		return err
+	}
+
+	fmt.Println()
+	rows := make([][]string, 0, len(detail.Tasks))
+	for _, task := range detail.Tasks {
+		rows = append(rows, append(taskRow(task), compactJSON(task.OutputData)))
+	}
+	return printTable(append(append([]string{}, taskHeader...), "OUTPUT"), rows)
+}
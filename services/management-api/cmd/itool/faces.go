@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"management-api/pkg/client"
+)
+
+const facesUsage = "usage: itool faces list | create <gallery> [--description d] | delete <gallery> | enroll <gallery> <person_id> <image> [--metadata json] | enrollments <gallery> [--person id] | forget <gallery> <person_id> | search <gallery> <image> [--top-k n] [--min-similarity f]"
+
+func runFaces(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(facesUsage)
+	}
+	switch args[0] {
+	case "list":
+		return runFacesList(ctx, args[1:])
+	case "create":
+		return runFacesCreate(ctx, args[1:])
+	case "delete":
+		return runFacesDelete(ctx, args[1:])
+	case "enroll":
+		return runFacesEnroll(ctx, args[1:])
+	case "enrollments":
+		return runFacesEnrollments(ctx, args[1:])
+	case "forget":
+		return runFacesForget(ctx, args[1:])
+	case "search":
+		return runFacesSearch(ctx, args[1:])
+	}
+	return fmt.Errorf("unknown faces command '%s'\n%s", args[0], facesUsage)
+}
+
+func runFacesList(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("faces list")
+	parseArgs(fs, args)
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	galleries, err := opts.client().ListFaceGalleries(ctx)
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(galleries)
+	}
+	rows := make([][]string, 0, len(galleries))
+	for _, g := range galleries {
+		rows = append(rows, []string{g.Name, strconv.Itoa(g.PersonCount), strconv.Itoa(g.EnrollmentCount), g.Description})
+	}
+	return printTable([]string{"GALLERY", "PEOPLE", "FACES", "DESCRIPTION"}, rows)
+}
+
+func runFacesCreate(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("faces create")
+	description := fs.String("description", "", "gallery description")
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		return errors.New("usage: itool faces create <gallery> [--description d]")
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	gallery, err := opts.client().CreateFaceGallery(ctx, positional[0], *description)
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(gallery)
+	}
+	return printFields([][2]string{{"Gallery", gallery.Name}, {"ID", strconv.Itoa(gallery.ID)}})
+}
+
+func runFacesDelete(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("faces delete")
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		return errors.New("usage: itool faces delete <gallery>")
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	return opts.client().DeleteFaceGallery(ctx, positional[0])
+}
+
+func runFacesEnroll(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("faces enroll")
+	metadataFlag := fs.String("metadata", "", `JSON object stored with the face, e.g. '{"name": "An"}'`)
+	positional := parseArgs(fs, args)
+	if len(positional) != 3 {
+		return errors.New("usage: itool faces enroll <gallery> <person_id> <image> [--metadata json]")
+	}
+	var metadata map[string]interface{}
+	if *metadataFlag != "" {
+		if err := json.Unmarshal([]byte(*metadataFlag), &metadata); err != nil {
+			return fmt.Errorf("invalid --metadata: %w", err)
+		}
+	}
+
+	file, closeFile, err := openFile(positional[2])
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	enrollment, err := opts.client().EnrollFace(ctx, positional[0], client.EnrollRequest{Image: file, PersonID: positional[1], Metadata: metadata})
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(enrollment)
+	}
+	return printFields([][2]string{{"Enrollment", strconv.Itoa(enrollment.ID)}, {"Person", enrollment.PersonID}})
+}
+
+func runFacesEnrollments(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("faces enrollments")
+	person := fs.String("person", "", "only show faces of this person")
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		return errors.New("usage: itool faces enrollments <gallery> [--person id]")
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	enrollments, err := opts.client().ListFaceEnrollments(ctx, positional[0], *person)
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(enrollments)
+	}
+	rows := make([][]string, 0, len(enrollments))
+	for _, e := range enrollments {
+		rows = append(rows, []string{strconv.Itoa(e.ID), e.PersonID, formatTime(&e.CreatedAt)})
+	}
+	return printTable([]string{"ID", "PERSON", "CREATED"}, rows)
+}
+
+func runFacesForget(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("faces forget")
+	positional := parseArgs(fs, args)
+	if len(positional) != 2 {
+		return errors.New("usage: itool faces forget <gallery> <person_id>")
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	deleted, err := opts.client().DeleteFacePerson(ctx, positional[0], positional[1])
+	if err != nil {
+		return err
+	}
+	return printFields([][2]string{{"Deleted", strconv.Itoa(deleted)}})
+}
+
+func runFacesSearch(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("faces search")
+	topK := fs.Int("top-k", 0, "number of people to return for each face (default 5)")
+	minSimilarity := fs.Float64("min-similarity", -1, "skip people with a lower similarity (-1 to 1)")
+	positional := parseArgs(fs, args)
+	if len(positional) != 2 {
+		return errors.New("usage: itool faces search <gallery> <image> [--top-k n] [--min-similarity f]")
+	}
+
+	file, closeFile, err := openFile(positional[1])
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	res, err := opts.client().SearchFaces(ctx, positional[0], client.FaceSearchRequest{Image: file, TopK: *topK, MinSimilarity: minSimilarity})
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(res)
+	}
+	var rows [][]string
+	for i, face := range res.Faces {
+		for _, match := range face.Matches {
+			rows = append(rows, []string{strconv.Itoa(i + 1), match.PersonID, strconv.FormatFloat(match.Similarity, 'f', 3, 64)})
+		}
+	}
+	return printTable([]string{"FACE", "PERSON", "SIMILARITY"}, rows)
+}
@@ -0,0 +1,227 @@
+// itool là CLI gọi management-api, thay cho các lệnh curl trong README.
+//
+//	itool tts "hello" -o out.mp3
+//	itool ocr scan.png
+//	itool ocr --preprocess auto_orient,deskew,binarize photo.jpg
+//	itool remove-bg in.png -o out.png
+//	itool translate --to vi "Hello"
+//	itool tasks list|get|watch|cancel|retry
+//	itool batch run manifest.yaml
+//	itool faces search staff photo.jpg
+//	itool face-verify selfie.jpg id-card.jpg
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"management-api/pkg/client"
+)
+
+// defaultServer là địa chỉ management-api khi không có --server và ITOOL_SERVER
+const defaultServer = "http://localhost:81"
+
+const usage = `Usage: itool <command> [flags] [args]
+
+Commands:
+  tts <text>                 Chuyển text thành giọng nói (-o lưu file audio)
+  vts <audio>                Chuyển audio (file hoặc URL) thành text
+  speech-recognition <audio> Nhận diện giọng nói từ audio (file hoặc URL)
+  ocr <image>                Nhận dạng chữ trong ảnh hoặc PDF/TIFF nhiều trang
+  face-recognition <image>   Đếm khuôn mặt trong ảnh
+  face-verify <image> <reference_image>
+                             So khớp 1:1 hai khuôn mặt (hoặc --gallery g --person id)
+  face-anonymize <image>     Che khuôn mặt trong ảnh (-o lưu ảnh kết quả)
+  remove-bg <image>          Xoá nền ảnh (-o lưu ảnh kết quả, --bg-color/--bg-image thay nền, --crop, --mask)
+  translate --to <lang> <text>
+                             Dịch text (đọc từ stdin nếu không có text)
+  tasks list|get|watch|cancel|retry
+                             Xem và quản lý task
+  batch run <manifest.yaml>  Chạy một tool trên nhiều input
+  batch get <id>             Xem tiến độ batch
+  faces list|create|delete|enroll|enrollments|forget|search
+                             Quản lý gallery khuôn mặt và tìm người trong ảnh
+  artifact <id>              Xem file kết quả của task (-o tải file)
+
+Global flags (đặt được ở mọi vị trí):
+  --server URL   Địa chỉ management-api (mặc định $ITOOL_SERVER hoặc http://localhost:81)
+  --json         In kết quả dạng JSON
+  --timeout D    Thời gian chờ tối đa, ví dụ 30s (mặc định không giới hạn)
+
+Dùng "-" thay cho file hoặc text để đọc từ stdin.
+`
+
+// command là một lệnh con; args là các tham số sau tên lệnh
+type command func(ctx context.Context, args []string) error
+
+var commands = map[string]command{
+	"tts":                runTextToVoice,
+	"vts":                runVoiceToText,
+	"speech-recognition": runSpeechRecognition,
+	"ocr":                runOCR,
+	"face-recognition":   runFaceRecognition,
+	"face-verify":        runFaceVerify,
+	"face-anonymize":     runFaceAnonymize,
+	"remove-bg":          runRemoveBackground,
+	"translate":          runTranslate,
+	"tasks":              runTasks,
+	"batch":              runBatch,
+	"faces":              runFaces,
+	"artifact":           runArtifact,
+}
+
+// options là các flag dùng chung cho mọi lệnh
+type options struct {
+	server  string
+	json    bool
+	timeout time.Duration
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "itool: unknown command '%s'\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd(ctx, os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "itool: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newFlagSet tạo FlagSet cho lệnh con kèm các flag dùng chung
+func newFlagSet(name string) (*flag.FlagSet, *options) {
+	opts := &options{}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	server := os.Getenv("ITOOL_SERVER")
+	if server == "" {
+		server = defaultServer
+	}
+	fs.StringVar(&opts.server, "server", server, "management-api address")
+	fs.BoolVar(&opts.json, "json", false, "print output as JSON")
+	fs.DurationVar(&opts.timeout, "timeout", 0, "maximum time to wait, e.g. 30s")
+	return fs, opts
+}
+
+// parseArgs cho phép flag nằm sau tham số, ví dụ `itool tts "hello" -o out.mp3`.
+// Trả về các tham số không phải flag theo đúng thứ tự.
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func (o *options) client() *client.Client {
+	return client.New(o.server)
+}
+
+// withTimeout áp dụng --timeout cho ctx
+func (o *options) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, o.timeout)
+}
+
+// textArg ghép các tham số thành text, hoặc đọc stdin khi tham số là "-"
+// hay khi không có tham số và stdin không phải terminal (dữ liệu được pipe vào)
+func textArg(args []string) (string, error) {
+	if (len(args) == 0 && !stdinIsTerminal()) || (len(args) == 1 && args[0] == "-") {
+		raw, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(raw)), nil
+	}
+	return strings.Join(args, " "), nil
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// openFile mở file cần tải lên; "-" là stdin
+func openFile(path string) (client.File, func(), error) {
+	if path == "-" {
+		return client.File{Name: "stdin", Reader: os.Stdin}, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return client.File{}, nil, err
+	}
+	return client.File{Name: path, Reader: f}, func() { f.Close() }, nil
+}
+
+// download lưu file từ management-api vào path; "-" là stdout
+func download(ctx context.Context, c *client.Client, pathOrURL, path string) error {
+	if path == "-" {
+		return c.Download(ctx, pathOrURL, os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.Download(ctx, pathOrURL, f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printFields in các cặp tên/giá trị thành hai cột
+func printFields(fields [][2]string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s:\t%s\n", f[0], f[1])
+	}
+	return w.Flush()
+}
+
+// printTable in bảng có tiêu đề
+func printTable(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// formatTime in thời gian theo giờ địa phương, "-" nếu nil
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"management-api/pkg/client"
+)
+
+const tasksUsage = "usage: itool tasks list [--status s] [--service s] [--limit n] | get <id> | watch <id> | cancel <id> | retry <id> [--override key=value] [--callback-url url]"
+
+func runTasks(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(tasksUsage)
+	}
+	switch args[0] {
+	case "list":
+		return runTasksList(ctx, args[1:])
+	case "get":
+		return runTasksGet(ctx, args[1:])
+	case "watch":
+		return runTasksWatch(ctx, args[1:])
+	case "cancel":
+		return runTasksCancel(ctx, args[1:])
+	case "retry":
+		return runTasksRetry(ctx, args[1:])
+	}
+	return fmt.Errorf("unknown tasks command '%s'\n%s", args[0], tasksUsage)
+}
+
+func runTasksList(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tasks list")
+	status := fs.String("status", "", "only show tasks with this status")
+	service := fs.String("service", "", "only show tasks of this service")
+	limit := fs.Int("limit", 50, "maximum number of tasks to show (0 for all)")
+	parseArgs(fs, args)
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	tasks, err := opts.client().ListTasks(ctx)
+	if err != nil {
+		return err
+	}
+
+	filtered := []client.Task{}
+	for _, task := range tasks {
+		if *status != "" && string(task.Status) != *status {
+			continue
+		}
+		if *service != "" && task.ServiceName != *service {
+			continue
+		}
+		filtered = append(filtered, task)
+		if *limit > 0 && len(filtered) == *limit {
+			break
+		}
+	}
+
+	if opts.json {
+		return printJSON(filtered)
+	}
+	rows := make([][]string, 0, len(filtered))
+	for _, task := range filtered {
+		rows = append(rows, taskRow(task))
+	}
+	return printTable(taskHeader, rows)
+}
+
+func runTasksGet(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tasks get")
+	id, err := taskIDArg(parseArgs(fs, args))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	task, err := opts.client().GetTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printTask(opts, task)
+}
+
+// runTasksWatch in mỗi lần task đổi trạng thái cho tới khi task kết thúc
+func runTasksWatch(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tasks watch")
+	interval := fs.Duration("interval", time.Second, "polling interval")
+	id, err := taskIDArg(parseArgs(fs, args))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	c := opts.client()
+
+	var last client.TaskStatus
+	for {
+		task, err := c.GetTask(ctx, id)
+		if err != nil {
+			return err
+		}
+		if task.Status != last {
+			fmt.Fprintf(os.Stderr, "%s  task %d is %s\n", time.Now().Format("15:04:05"), id, task.Status)
+			last = task.Status
+		}
+		if task.Status.IsTerminal() {
+			if err := printTask(opts, task); err != nil {
+				return err
+			}
+			if task.Status != client.TaskStatusCompleted {
+				return fmt.Errorf("task %d is %s", id, task.Status)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(*interval):
+		}
+	}
+}
+
+func runTasksCancel(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tasks cancel")
+	id, err := taskIDArg(parseArgs(fs, args))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	task, err := opts.client().CancelTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printTask(opts, task)
+}
+
+func runTasksRetry(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tasks retry")
+	callbackURL := fs.String("callback-url", "", "webhook called when the new task finishes")
+	overrides := overrideFlag{}
+	fs.Var(overrides, "override", "override an input field, e.g. --override language=fr (repeatable)")
+	id, err := taskIDArg(parseArgs(fs, args))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	task, err := opts.client().RetryTask(ctx, id, client.RetryRequest{Overrides: overrides, CallbackURL: *callbackURL})
+	if err != nil {
+		return err
+	}
+	return printTask(opts, task)
+}
+
+// overrideFlag đọc các flag dạng key=value lặp lại nhiều lần
+type overrideFlag map[string]interface{}
+
+func (f overrideFlag) String() string {
+	return ""
+}
+
+func (f overrideFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got '%s'", value)
+	}
+	f[key] = val
+	return nil
+}
+
+func taskIDArg(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.New(tasksUsage)
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid task ID '%s'", args[0])
+	}
+	return id, nil
+}
+
+var taskHeader = []string{"ID", "SERVICE", "STATUS", "ATTEMPT", "CREATED", "FINISHED"}
+
+func taskRow(task client.Task) []string {
+	return []string{
+		strconv.Itoa(task.ID),
+		task.ServiceName,
+		string(task.Status),
+		strconv.Itoa(task.Attempt),
+		formatTime(&task.CreatedAt),
+		formatTime(task.FinishedAt),
+	}
+}
+
+func printTask(opts *options, task *client.Task) error {
+	if opts.json {
+		return printJSON(task)
+	}
+	fields := [][2]string{
+		{"ID", strconv.Itoa(task.ID)},
+		{"Service", task.ServiceName},
+		{"Status", string(task.Status)},
+		{"Attempt", strconv.Itoa(task.Attempt)},
+	}
+	if task.ParentTaskID != nil {
+		fields = append(fields, [2]string{"Parent", strconv.Itoa(*task.ParentTaskID)})
+	}
+	if task.BatchID != nil {
+		fields = append(fields, [2]string{"Batch", strconv.Itoa(*task.BatchID)})
+	}
+	if task.PipelineID != nil {
+		fields = append(fields, [2]string{"Pipeline", strconv.Itoa(*task.PipelineID)})
+	}
+	fields = append(fields,
+		[2]string{"Created", formatTime(&task.CreatedAt)},
+		[2]string{"Started", formatTime(task.StartedAt)},
+		[2]string{"Finished", formatTime(task.FinishedAt)},
+		[2]string{"Input", compactJSON(task.InputData)},
+		[2]string{"Output", compactJSON(task.OutputData)},
+	)
+	return printFields(fields)
+}
+
+// compactJSON in JSON trên một dòng, "-" nếu rỗng
+func compactJSON(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "-"
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw)
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
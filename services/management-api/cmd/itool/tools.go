@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"management-api/pkg/client"
+)
+
+func runTextToVoice(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tts")
+	language := fs.String("lang", "", "language of the text, e.g. vi (default en)")
+	output := fs.String("o", "", "save the audio to this file")
+	callbackURL := fs.String("callback-url", "", "webhook called when the task finishes")
+	text, err := textArg(parseArgs(fs, args))
+	if err != nil {
+		return err
+	}
+	if text == "" {
+		return errors.New("usage: itool tts <text> [--lang vi] [-o out.mp3]")
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	c := opts.client()
+	res, err := c.TextToVoice(ctx, client.TextToVoiceRequest{Text: text, Language: *language, CallbackURL: *callbackURL})
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		if err := download(ctx, c, res.AudioURL, *output); err != nil {
+			return fmt.Errorf("failed to download audio: %w", err)
+		}
+	}
+
+	if opts.json {
+		return printJSON(res)
+	}
+	fields := [][2]string{{"Task", strconv.Itoa(res.TaskID)}, {"Audio URL", res.AudioURL}, {"Marks", strconv.Itoa(len(res.Marks))}}
+	if *output != "" && *output != "-" {
+		fields = append(fields, [2]string{"Saved to", *output})
+	}
+	return printFields(fields)
+}
+
+func runVoiceToText(ctx context.Context, args []string) error {
+	return runAudioTool(ctx, "vts", args, (*client.Client).VoiceToText)
+}
+
+func runSpeechRecognition(ctx context.Context, args []string) error {
+	return runAudioTool(ctx, "speech-recognition", args, (*client.Client).SpeechRecognition)
+}
+
+// runAudioTool chạy tool nhận audio; file cục bộ được tải lên qua /upload-audio trước
+func runAudioTool(ctx context.Context, name string, args []string, call func(*client.Client, context.Context, client.AudioRequest) (*client.TextResponse, error)) error {
+	fs, opts := newFlagSet(name)
+	callbackURL := fs.String("callback-url", "", "webhook called when the task finishes")
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: itool %s <audio file or URL>", name)
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	c := opts.client()
+
+	audioURL := positional[0]
+	if !strings.HasPrefix(audioURL, "http://") && !strings.HasPrefix(audioURL, "https://") {
+		file, closeFile, err := openFile(audioURL)
+		if err != nil {
+			return err
+		}
+		uploaded, err := c.UploadAudio(ctx, file)
+		closeFile()
+		if err != nil {
+			return fmt.Errorf("failed to upload audio: %w", err)
+		}
+		audioURL = uploaded.AudioURL
+	}
+
+	res, err := call(c, ctx, client.AudioRequest{AudioURL: audioURL, CallbackURL: *callbackURL})
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(res)
+	}
+	return printFields([][2]string{{"Task", strconv.Itoa(res.TaskID)}, {"Text", res.Text}})
+}
+
+func runOCR(ctx context.Context, args []string) error {
+	var languages, output string
+	var searchablePDF bool
+	var preprocess preprocessOptions
+	return runImageTool(ctx, "ocr", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&languages, "lang", "", `recognition languages, e.g. "vie+eng" (default "eng")`)
+		fs.StringVar(&output, "output", "", "result format: text, hocr, json or tsv (default text)")
+		fs.BoolVar(&searchablePDF, "searchable-pdf", false, "also produce a PDF with a text layer")
+		preprocess.register(fs, "auto_orient, downscale, grayscale, binarize, deskew")
+	}, func(c *client.Client, ctx context.Context, req client.ImageRequest) (interface{}, [][2]string, error) {
+		res, err := c.OCR(ctx, client.OCRRequest{
+			Image:         req.Image,
+			Languages:     languages,
+			Output:        output,
+			SearchablePDF: searchablePDF,
+			Preprocess:    preprocess.list(),
+			MaxDimension:  preprocess.maxDimension,
+			CallbackURL:   req.CallbackURL,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		fields := [][2]string{{"Task", strconv.Itoa(res.TaskID)}, {"Languages", res.Languages}}
+		if res.Orientation != nil {
+			fields = append(fields, [2]string{"Rotate", strconv.Itoa(res.Orientation.Rotate)})
+		}
+		if len(res.Blocks) > 0 {
+			fields = append(fields, [2]string{"Blocks", strconv.Itoa(len(res.Blocks))})
+		}
+		if res.PageCount > 0 {
+			fields = append(fields, [2]string{"Pages", strconv.Itoa(res.PageCount)})
+		}
+		if res.SearchablePDF != "" {
+			fields = append(fields, [2]string{"PDF", res.SearchablePDFURL()})
+		}
+		switch {
+		case res.HOCR != "":
+			fields = append(fields, [2]string{"hOCR", res.HOCR})
+		case res.TSV != "":
+			fields = append(fields, [2]string{"TSV", res.TSV})
+		default:
+			fields = append(fields, [2]string{"Text", res.Text})
+		}
+		return res, fields, nil
+	})
+}
+
+func runFaceRecognition(ctx context.Context, args []string) error {
+	var preprocess preprocessOptions
+	return runImageTool(ctx, "face-recognition", args, func(fs *flag.FlagSet) {
+		preprocess.register(fs, "auto_orient, downscale")
+	}, func(c *client.Client, ctx context.Context, req client.ImageRequest) (interface{}, [][2]string, error) {
+		req.Preprocess = preprocess.list()
+		req.MaxDimension = preprocess.maxDimension
+		res, err := c.FaceRecognition(ctx, req)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, [][2]string{{"Task", strconv.Itoa(res.TaskID)}, {"Faces", strconv.Itoa(res.FaceCount)}}, nil
+	})
+}
+
+func runFaceVerify(ctx context.Context, args []string) error {
+	const verifyUsage = "usage: itool face-verify <image> <reference_image> | itool face-verify <image> --gallery g --person id [--threshold f]"
+	fs, opts := newFlagSet("face-verify")
+	gallery := fs.String("gallery", "", "compare with a person enrolled in this gallery")
+	person := fs.String("person", "", "person_id in --gallery")
+	threshold := fs.Float64("threshold", 0, "similarity threshold, -1 to 1 (default: server setting)")
+	callbackURL := fs.String("callback-url", "", "webhook called when the task finishes")
+	positional := parseArgs(fs, args)
+	if len(positional) < 1 || len(positional) > 2 || (len(positional) == 2) == (*gallery != "") {
+		return errors.New(verifyUsage)
+	}
+
+	req := client.FaceVerifyRequest{Gallery: *gallery, PersonID: *person, Threshold: *threshold, CallbackURL: *callbackURL}
+	file, closeFile, err := openFile(positional[0])
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+	req.Image = file
+	if len(positional) == 2 {
+		reference, closeReference, err := openFile(positional[1])
+		if err != nil {
+			return err
+		}
+		defer closeReference()
+		req.Reference = reference
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	res, err := opts.client().FaceVerify(ctx, req)
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(res)
+	}
+	fields := [][2]string{
+		{"Task", strconv.Itoa(res.TaskID)},
+		{"Match", strconv.FormatBool(res.Match)},
+		{"Similarity", strconv.FormatFloat(res.Similarity, 'f', 3, 64)},
+		{"Threshold", strconv.FormatFloat(res.Threshold, 'f', 3, 64)},
+	}
+	for _, face := range []struct {
+		name string
+		face client.FaceVerifyFace
+	}{{"Probe", res.Probe}, {"Reference", res.Reference}} {
+		if face.face.Quality != nil {
+			fields = append(fields, [2]string{face.name + " quality", strconv.FormatFloat(face.face.Quality.Score, 'f', 2, 64) + issues(face.face.Quality.Issues)})
+		}
+		if face.face.Liveness != nil && face.face.Liveness.Suspicious {
+			fields = append(fields, [2]string{face.name + " liveness", "suspicious" + issues(face.face.Liveness.Hints)})
+		}
+	}
+	return printFields(fields)
+}
+
+// issues trả về danh sách vấn đề dạng " (a, b)", rỗng nếu không có
+func issues(list []string) string {
+	if len(list) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(list, ", ") + ")"
+}
+
+func runFaceAnonymize(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("face-anonymize")
+	mode := fs.String("mode", "", "blur, pixelate or box (default blur)")
+	strength := fs.Int("strength", 0, "1-100, higher hides more (default 50)")
+	output := fs.String("o", "", "save the anonymized image to this file")
+	callbackURL := fs.String("callback-url", "", "webhook called when the task finishes")
+	var preprocess preprocessOptions
+	preprocess.register(fs, "auto_orient, downscale")
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		return errors.New("usage: itool face-anonymize <image> [--mode blur|pixelate|box] [--strength n] [-o file]")
+	}
+
+	file, closeFile, err := openFile(positional[0])
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	c := opts.client()
+	res, err := c.FaceAnonymize(ctx, client.FaceAnonymizeRequest{
+		Image:        file,
+		Mode:         *mode,
+		Strength:     *strength,
+		Preprocess:   preprocess.list(),
+		MaxDimension: preprocess.maxDimension,
+		CallbackURL:  *callbackURL,
+	})
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		if err := download(ctx, c, res.AnonymizedImageURL(), *output); err != nil {
+			return fmt.Errorf("failed to download image: %w", err)
+		}
+	}
+	if opts.json {
+		return printJSON(res)
+	}
+	fields := [][2]string{{"Task", strconv.Itoa(res.TaskID)}, {"Faces", strconv.Itoa(res.FaceCount)}, {"Image", res.AnonymizedImageURL()}}
+	if *output != "" && *output != "-" {
+		fields = append(fields, [2]string{"Saved to", *output})
+	}
+	return printFields(fields)
+}
+
+func runRemoveBackground(ctx context.Context, args []string) error {
+	var output, backgroundColor, backgroundImage, format string
+	var alphaMatting, crop, maskOnly bool
+	var cropPadding int
+	return runImageTool(ctx, "remove-bg", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&output, "o", "", "save the processed image to this file")
+		fs.StringVar(&backgroundColor, "bg-color", "", `replacement background colour, e.g. "#ffffff"`)
+		fs.StringVar(&backgroundImage, "bg-image", "", "replacement background image file")
+		fs.BoolVar(&alphaMatting, "alpha-matting", false, "refine subject edges such as hair (slower)")
+		fs.BoolVar(&crop, "crop", false, "crop to the subject bounding box")
+		fs.IntVar(&cropPadding, "padding", 0, "pixels kept around the subject with --crop")
+		fs.BoolVar(&maskOnly, "mask", false, "return the subject mask instead of the cut-out")
+		fs.StringVar(&format, "format", "", "output format: png or webp (default png)")
+	}, func(c *client.Client, ctx context.Context, req client.ImageRequest) (interface{}, [][2]string, error) {
+		bgReq := client.RemoveBackgroundRequest{
+			Image:           req.Image,
+			BackgroundColor: backgroundColor,
+			AlphaMatting:    alphaMatting,
+			Crop:            crop,
+			CropPadding:     cropPadding,
+			MaskOnly:        maskOnly,
+			Format:          format,
+			CallbackURL:     req.CallbackURL,
+		}
+		if backgroundImage != "" {
+			file, closeFile, err := openFile(backgroundImage)
+			if err != nil {
+				return nil, nil, err
+			}
+			defer closeFile()
+			bgReq.Background = file
+		}
+		res, err := c.RemoveBackground(ctx, bgReq)
+		if err != nil {
+			return nil, nil, err
+		}
+		fields := [][2]string{{"Task", strconv.Itoa(res.TaskID)}, {"Image", res.ProcessedImageURL()}}
+		if res.ArtifactID != "" {
+			fields = append(fields, [2]string{"Artifact", res.ArtifactID})
+		}
+		if res.Width > 0 {
+			fields = append(fields, [2]string{"Size", fmt.Sprintf("%dx%d", res.Width, res.Height)})
+		}
+		if output != "" {
+			if err := download(ctx, c, res.ProcessedImageURL(), output); err != nil {
+				return nil, nil, fmt.Errorf("failed to download image: %w", err)
+			}
+			if output != "-" {
+				fields = append(fields, [2]string{"Saved to", output})
+			}
+		}
+		return res, fields, nil
+	})
+}
+
+// preprocessOptions là các flag tiền xử lý ảnh của ocr và face-recognition
+type preprocessOptions struct {
+	steps        string
+	maxDimension int
+}
+
+// register đăng ký --preprocess và --max-dimension; steps là các bước mà tool hỗ trợ (cho help)
+func (p *preprocessOptions) register(fs *flag.FlagSet, steps string) {
+	fs.StringVar(&p.steps, "preprocess", "", "comma-separated preprocessing steps: "+steps)
+	fs.IntVar(&p.maxDimension, "max-dimension", 0, "longest side in pixels for the downscale step (default: server setting)")
+}
+
+// list trả về các bước tiền xử lý đã chọn
+func (p *preprocessOptions) list() []string {
+	var steps []string
+	for _, step := range strings.Split(p.steps, ",") {
+		if step = strings.TrimSpace(step); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// runImageTool chạy tool nhận một ảnh; extraFlags đăng ký thêm flag riêng của tool
+func runImageTool(ctx context.Context, name string, args []string, extraFlags func(fs *flag.FlagSet), call func(*client.Client, context.Context, client.ImageRequest) (interface{}, [][2]string, error)) error {
+	fs, opts := newFlagSet(name)
+	callbackURL := fs.String("callback-url", "", "webhook called when the task finishes")
+	if extraFlags != nil {
+		extraFlags(fs)
+	}
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: itool %s <image>", name)
+	}
+
+	file, closeFile, err := openFile(positional[0])
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	res, fields, err := call(opts.client(), ctx, client.ImageRequest{Image: file, CallbackURL: *callbackURL})
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(res)
+	}
+	return printFields(fields)
+}
+
+func runTranslate(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("translate")
+	destLang := fs.String("to", "", "target language, e.g. vi")
+	callbackURL := fs.String("callback-url", "", "webhook called when the task finishes")
+	text, err := textArg(parseArgs(fs, args))
+	if err != nil {
+		return err
+	}
+	if *destLang == "" || text == "" {
+		return errors.New("usage: itool translate --to <lang> <text>")
+	}
+
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	res, err := opts.client().Translate(ctx, client.TranslateRequest{Text: text, DestLang: *destLang, CallbackURL: *callbackURL})
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(res)
+	}
+	if res.TaskID != 0 {
+		fmt.Fprintf(os.Stderr, "Task %d\n", res.TaskID)
+	}
+	fmt.Println(res.TranslatedText)
+	return nil
+}
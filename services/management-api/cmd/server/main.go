@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 
 	"management-api/internal/config"
+	"management-api/internal/migrate"
 	"management-api/internal/repository"
 	"management-api/internal/router"
 	"management-api/internal/service"
@@ -16,6 +19,21 @@ func main() {
 		log.Fatalf("Could not load config: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
+	}
+	// Kiểm tra phiên bản schema trước khi phục vụ request
+	m, err := migrate.New(cfg.Database)
+	if err != nil {
+		log.Fatalf("Could not connect to database: %v", err)
+	}
+	err = m.CheckSchema()
+	m.Close()
+	if err != nil {
+		log.Fatalf("Database schema mismatch: %v", err)
+	}
+
 	// Kết nối đến cơ sở dữ liệu
 	repo, err := repository.NewTaskRepository(cfg.Database)
 	if err != nil {
@@ -24,10 +42,24 @@ func main() {
 	defer repo.Close()
 
 	// Khởi tạo service
-	taskService := service.NewTaskService(repo)
+	webhookService := service.NewWebhookService(repo, cfg)
+	taskService := service.NewTaskService(repo, webhookService, cfg)
+	batchService := service.NewBatchService(repo, taskService, cfg)
+	pipelineService := service.NewPipelineService(repo, taskService, cfg)
+	templateService := service.NewTemplateService(repo, pipelineService)
+	galleryService := service.NewGalleryService(repo, taskService)
+	artifactService := service.NewArtifactService(repo, cfg)
+
+	// Quét các task bị treo ở background
+	reaperService := service.NewReaperService(repo, taskService, webhookService, cfg)
+	go reaperService.Run(context.Background())
+
+	// Dọn dẹp file và task hết hạn ở background
+	retentionService := service.NewRetentionService(repo, cfg)
+	go retentionService.Run(context.Background())
 
 	// Khởi tạo router
-	r := router.SetupRouter(taskService, cfg)
+	r := router.SetupRouter(taskService, batchService, pipelineService, templateService, galleryService, artifactService, retentionService, cfg)
 
 	// Chạy server
 	if err := r.Run(cfg.Server.Port); err != nil {
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"management-api/internal/config"
+	"management-api/internal/migrate"
+)
+
+// runMigrate xử lý lệnh "management-api migrate up|down [n]|status"
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		log.Fatal("Usage: management-api migrate up|down [n]|status")
+	}
+
+	m, err := migrate.New(cfg.Database)
+	if err != nil {
+		log.Fatalf("Could not connect to database: %v", err)
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		err = m.Up()
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			n, err = strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Invalid number of migrations: %s", args[1])
+			}
+		}
+		err = m.Down(n)
+	case "status":
+		err = printStatus(m)
+	default:
+		log.Fatalf("Unknown migrate command: %s", args[0])
+	}
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
+
+func printStatus(m *migrate.Migrator) error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return nil
+}
@@ -1,17 +1,32 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Uploads  UploadConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Uploads    UploadConfig
+	Batch      BatchConfig
+	Webhook    WebhookConfig
+	Reaper     ReaperConfig
+	Retention  RetentionConfig
+	Backends   BackendConfig
+	OCR        OCRConfig
+	Preprocess PreprocessConfig
+	Face       FaceConfig
 }
 
 type ServerConfig struct {
 	Port string
+	// AdminToken là bearer token của các endpoint cho người vận hành; rỗng thì các endpoint này bị tắt
+	AdminToken string
 }
 
 type DatabaseConfig struct {
@@ -27,10 +42,134 @@ type UploadConfig struct {
 	ImagePath string
 }
 
+// Contains kiểm tra path có nằm trong thư mục upload ảnh hoặc audio hay không
+func (c UploadConfig) Contains(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, root := range []string{c.ImagePath, c.AudioPath} {
+		rootAbs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(rootAbs, abs)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+type BatchConfig struct {
+	// Concurrency là số task con của một batch được xử lý đồng thời
+	Concurrency int
+}
+
+type WebhookConfig struct {
+	// Secret là khoá HMAC-SHA256 dùng để ký payload gửi tới callback_url
+	Secret         string
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	Timeout        time.Duration
+}
+
+type ReaperConfig struct {
+	// Interval là khoảng thời gian giữa hai lần quét task bị treo
+	Interval time.Duration
+	// Timeout là thời gian processing tối đa mặc định của một task
+	Timeout time.Duration
+	// ServiceTimeouts ghi đè Timeout theo service_name, ví dụ "remove-bg=15m,ocr=5m"
+	ServiceTimeouts map[string]time.Duration
+	// MaxAttempts là số lần chạy tối đa của một task; lớn hơn 1 thì task bị treo được đưa lại vào hàng đợi
+	MaxAttempts int
+}
+
+// TimeoutFor trả về thời gian processing tối đa của service
+func (c ReaperConfig) TimeoutFor(serviceName string) time.Duration {
+	if timeout, ok := c.ServiceTimeouts[serviceName]; ok {
+		return timeout
+	}
+	return c.Timeout
+}
+
+// RetentionConfig là thời gian giữ file và task. Thời gian tính từ lúc task kết thúc;
+// các map ghi đè giá trị mặc định theo tool (service_name), ví dụ "remove-bg=168h".
+// Thời gian giữ file bằng 0 là không xoá file, giống cleanup của text-to-voice.
+type RetentionConfig struct {
+	Interval time.Duration
+	FileTTL  time.Duration
+	FileTTLs map[string]time.Duration
+	TaskTTL  time.Duration
+	TaskTTLs map[string]time.Duration
+	// Archive chuyển task hết hạn sang tasks_archive thay vì xoá hẳn
+	Archive bool
+	// SharedImagePath là thư mục ảnh dùng chung với service background-removal
+	SharedImagePath string
+}
+
+// FileTTLFor trả về thời gian giữ file của tool
+func (c RetentionConfig) FileTTLFor(tool string) time.Duration {
+	if ttl, ok := c.FileTTLs[tool]; ok {
+		return ttl
+	}
+	return c.FileTTL
+}
+
+// FileExpired cho biết file của task tool kết thúc lúc finished đã hết thời gian giữ tại now;
+// luôn false nếu thời gian giữ file của tool bằng 0
+func (c RetentionConfig) FileExpired(tool string, finished, now time.Time) bool {
+	ttl := c.FileTTLFor(tool)
+	return ttl > 0 && now.Sub(finished) >= ttl
+}
+
+// TaskTTLFor trả về thời gian giữ task của tool
+func (c RetentionConfig) TaskTTLFor(tool string) time.Duration {
+	if ttl, ok := c.TaskTTLs[tool]; ok {
+		return ttl
+	}
+	return c.TaskTTL
+}
+
+// BackendConfig là địa chỉ gốc của các service AI phía sau
+type BackendConfig struct {
+	TextToVoiceURL       string
+	VoiceToTextURL       string
+	BackgroundRemovalURL string
+	SpeechRecognitionURL string
+	FaceRecognitionURL   string
+	OCRURL               string
+	TranslationURL       string
+}
+
+// OCRConfig là cấu hình OCR tài liệu nhiều trang (PDF, TIFF)
+type OCRConfig struct {
+	// PageConcurrency là số trang của một tài liệu được OCR đồng thời
+	PageConcurrency int
+	// MaxPages là số trang tối đa của một tài liệu
+	MaxPages int
+	// PDFDPI là độ phân giải khi render trang PDF thành ảnh
+	PDFDPI int
+}
+
+// PreprocessConfig là cấu hình tiền xử lý ảnh trước OCR và face-recognition
+type PreprocessConfig struct {
+	// MaxDimension là cạnh dài tối đa mặc định của bước downscale
+	MaxDimension int
+}
+
+// FaceConfig là cấu hình so khớp khuôn mặt của face-verify
+type FaceConfig struct {
+	// VerifyThreshold là ngưỡng cosine similarity mặc định để hai khuôn mặt được coi là cùng một người;
+	// 0.82 tương ứng khoảng cách Euclid 0.6 (tolerance mặc định của face_recognition)
+	VerifyThreshold float64
+}
+
 func LoadConfig() (*Config, error) {
-	return &Config{
+	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", ":81"),
+			Port:       getEnv("SERVER_PORT", ":81"),
+			AdminToken: getEnv("ADMIN_TOKEN", ""),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -43,7 +182,66 @@ func LoadConfig() (*Config, error) {
 			AudioPath: getEnv("UPLOAD_AUDIO_PATH", "./uploads/audio/"),
 			ImagePath: getEnv("UPLOAD_IMAGE_PATH", "./uploads/images/"),
 		},
-	}, nil
+		Batch: BatchConfig{
+			Concurrency: getEnvInt("BATCH_CONCURRENCY", 4),
+		},
+		Webhook: WebhookConfig{
+			Secret:         getEnv("WEBHOOK_SECRET", ""),
+			MaxAttempts:    getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+			InitialBackoff: getEnvDuration("WEBHOOK_INITIAL_BACKOFF", 2*time.Second),
+			Timeout:        getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		},
+		Reaper: ReaperConfig{
+			Interval:        getEnvDuration("REAPER_INTERVAL", time.Minute),
+			Timeout:         getEnvDuration("REAPER_TIMEOUT", 10*time.Minute),
+			ServiceTimeouts: getEnvDurations("REAPER_SERVICE_TIMEOUTS"),
+			MaxAttempts:     getEnvInt("REAPER_MAX_ATTEMPTS", 1),
+		},
+		Retention: RetentionConfig{
+			Interval:        getEnvDuration("RETENTION_INTERVAL", time.Hour),
+			FileTTL:         getEnvDuration("RETENTION_FILE_TTL", 7*24*time.Hour),
+			FileTTLs:        getEnvDurations("RETENTION_FILE_TTLS"),
+			TaskTTL:         getEnvDuration("RETENTION_TASK_TTL", 90*24*time.Hour),
+			TaskTTLs:        getEnvDurations("RETENTION_TASK_TTLS"),
+			Archive:         getEnvBool("RETENTION_ARCHIVE", true),
+			SharedImagePath: getEnv("SHARED_IMAGE_PATH", "/shared/images"),
+		},
+		Backends: BackendConfig{
+			TextToVoiceURL:       getEnv("TEXT_TO_VOICE_URL", "http://text_to_voice_service:5001"),
+			VoiceToTextURL:       getEnv("VOICE_TO_TEXT_URL", "http://voice_to_text_service:5002"),
+			BackgroundRemovalURL: getEnv("BACKGROUND_REMOVAL_URL", "http://background_removal_service:5003"),
+			SpeechRecognitionURL: getEnv("SPEECH_RECOGNITION_URL", "http://speech_recognition_service:5004"),
+			FaceRecognitionURL:   getEnv("FACE_RECOGNITION_URL", "http://face_recognition_service:5005"),
+			OCRURL:               getEnv("OCR_URL", "http://ocr_service:5006"),
+			TranslationURL:       getEnv("TRANSLATION_URL", "http://translation_service:5007"),
+		},
+		OCR: OCRConfig{
+			PageConcurrency: getEnvInt("OCR_PAGE_CONCURRENCY", 4),
+			MaxPages:        getEnvInt("OCR_MAX_PAGES", 200),
+			PDFDPI:          getEnvInt("OCR_PDF_DPI", 300),
+		},
+		Preprocess: PreprocessConfig{
+			MaxDimension: getEnvInt("PREPROCESS_MAX_DIMENSION", 2048),
+		},
+		Face: FaceConfig{
+			VerifyThreshold: getEnvFloat("FACE_VERIFY_THRESHOLD", 0.82),
+		},
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validate kiểm tra các giá trị cấu hình mà giá trị không hợp lệ sẽ làm server panic khi chạy
+func (c *Config) validate() error {
+	if c.Reaper.Interval <= 0 {
+		return fmt.Errorf("REAPER_INTERVAL must be positive, got %s", c.Reaper.Interval)
+	}
+	if c.Retention.Interval <= 0 {
+		return fmt.Errorf("RETENTION_INTERVAL must be positive, got %s", c.Retention.Interval)
+	}
+	return nil
 }
 
 func getEnv(key, defaultVal string) string {
@@ -52,3 +250,54 @@ func getEnv(key, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+func getEnvInt(key string, defaultVal int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultVal
+}
+
+func getEnvFloat(key string, defaultVal float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultVal
+}
+
+func getEnvBool(key string, defaultVal bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultVal
+}
+
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultVal
+}
+
+// getEnvDurations đọc danh sách "key=duration" cách nhau bởi dấu phẩy, bỏ qua các phần tử không hợp lệ
+func getEnvDurations(key string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err == nil {
+			result[strings.TrimSpace(name)] = d
+		}
+	}
+	return result
+}
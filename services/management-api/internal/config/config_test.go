@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionFileExpired(t *testing.T) {
+	now := time.Now()
+	cfg := RetentionConfig{
+		FileTTL:  24 * time.Hour,
+		FileTTLs: map[string]time.Duration{"tts": 0, "ocr": time.Hour},
+	}
+	cases := []struct {
+		tool string
+		age  time.Duration
+		want bool
+	}{
+		{"remove-bg", 23 * time.Hour, false},
+		{"remove-bg", 24 * time.Hour, true},
+		{"ocr", 2 * time.Hour, true},
+		// thời gian giữ file bằng 0 là không xoá file
+		{"tts", 0, false},
+		{"tts", 365 * 24 * time.Hour, false},
+	}
+	for _, c := range cases {
+		if got := cfg.FileExpired(c.tool, now.Add(-c.age), now); got != c.want {
+			t.Errorf("FileExpired(%s, age %s) = %t, want %t", c.tool, c.age, got, c.want)
+		}
+	}
+
+	cfg = RetentionConfig{FileTTL: 0}
+	if cfg.FileExpired("ocr", now.Add(-365*24*time.Hour), now) {
+		t.Error("FileExpired with RETENTION_FILE_TTL=0 must be false")
+	}
+}
@@ -0,0 +1,280 @@
+// Package document tách tài liệu nhiều trang (PDF, TIFF) thành ảnh từng trang và ghép các trang PDF.
+// PDF được xử lý bằng poppler-utils (pdfinfo, pdftoppm, pdfunite), TIFF được tách trong Go.
+package document
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"management-api/internal/imaging"
+
+	"golang.org/x/image/tiff"
+)
+
+// Loại tài liệu nhiều trang
+const (
+	KindPDF  = "pdf"
+	KindTIFF = "tiff"
+)
+
+// ErrTooManyPages là lỗi khi tài liệu có nhiều trang hơn giới hạn
+var ErrTooManyPages = errors.New("document has too many pages")
+
+// Detect trả về KindPDF hoặc KindTIFF theo phần đầu của file; chuỗi rỗng nếu file là ảnh thường
+func Detect(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, 5)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", nil
+	}
+	header = header[:n]
+	switch {
+	case bytes.HasPrefix(header, []byte("%PDF-")):
+		return KindPDF, nil
+	case bytes.HasPrefix(header, []byte("II*\x00")), bytes.HasPrefix(header, []byte("MM\x00*")):
+		return KindTIFF, nil
+	}
+	return "", nil
+}
+
+// SplitPages ghi ảnh PNG của từng trang vào dir (page-0001.png, page-0002.png, ...) và trả về
+// đường dẫn theo thứ tự trang. PDF được render với độ phân giải dpi. Tài liệu có nhiều hơn
+// maxPages trang trả về lỗi bọc ErrTooManyPages.
+func SplitPages(ctx context.Context, path, dir string, dpi, maxPages int) ([]string, error) {
+	kind, err := Detect(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case KindPDF:
+		return splitPDF(ctx, path, dir, dpi, maxPages)
+	case KindTIFF:
+		return splitTIFF(path, dir, maxPages)
+	default:
+		return nil, fmt.Errorf("%s is not a PDF or TIFF document", filepath.Base(path))
+	}
+}
+
+// MergePDF ghép các file PDF (theo thứ tự trang) thành file out
+func MergePDF(ctx context.Context, pages []string, out string) error {
+	if len(pages) == 0 {
+		return fmt.Errorf("no pages to merge")
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return err
+	}
+	if len(pages) == 1 {
+		data, err := os.ReadFile(pages[0])
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(out, data, 0644)
+	}
+
+	args := append(append([]string{}, pages...), out)
+	_, err := run(ctx, "pdfunite", args...)
+	return err
+}
+
+// pagePath là tên file ảnh của trang (bắt đầu từ 1) trong dir
+func pagePath(dir string, page int) string {
+	return filepath.Join(dir, fmt.Sprintf("page-%04d.png", page))
+}
+
+func splitPDF(ctx context.Context, path, dir string, dpi, maxPages int) ([]string, error) {
+	count, err := pdfPageCount(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("PDF has no pages")
+	}
+	if count > maxPages {
+		return nil, fmt.Errorf("%w: %d pages, limit is %d", ErrTooManyPages, count, maxPages)
+	}
+
+	// pdftoppm đặt tên page-1.png, page-01.png, ... tuỳ số trang; đổi lại thành pagePath
+	prefix := filepath.Join(dir, "pdftoppm")
+	if _, err := run(ctx, "pdftoppm", "-r", strconv.Itoa(dpi), "-png", path, prefix); err != nil {
+		return nil, err
+	}
+	rendered, err := filepath.Glob(prefix + "-*.png")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(rendered)
+	if len(rendered) != count {
+		return nil, fmt.Errorf("pdftoppm rendered %d of %d pages", len(rendered), count)
+	}
+
+	pages := make([]string, len(rendered))
+	for i, file := range rendered {
+		pages[i] = pagePath(dir, i+1)
+		if err := os.Rename(file, pages[i]); err != nil {
+			return nil, err
+		}
+	}
+	return pages, nil
+}
+
+// pdfPageCount đọc số trang của PDF từ dòng "Pages:" của pdfinfo
+func pdfPageCount(ctx context.Context, path string) (int, error) {
+	out, err := run(ctx, "pdfinfo", path)
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if value, ok := strings.CutPrefix(line, "Pages:"); ok {
+			return strconv.Atoi(strings.TrimSpace(value))
+		}
+	}
+	return 0, fmt.Errorf("pdfinfo did not report a page count")
+}
+
+// splitTIFF tách từng IFD (frame) của TIFF thành một ảnh PNG. Mỗi frame được giải mã bằng cách trỏ
+// offset IFD đầu tiên trong header tới frame đó và cắt chuỗi IFD sau frame.
+func splitTIFF(path, dir string, maxPages int) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	order, offsets, err := tiffIFDs(data, maxPages)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]string, len(offsets))
+	for i, offset := range offsets {
+		pages[i] = pagePath(dir, i+1)
+		if err := writeTIFFPage(data, order, offset, pages[i]); err != nil {
+			return nil, fmt.Errorf("TIFF page %d: %v", i+1, err)
+		}
+	}
+	return pages, nil
+}
+
+// tiffIFDs trả về thứ tự byte và offset các IFD của một file TIFF (không hỗ trợ BigTIFF)
+func tiffIFDs(data []byte, maxPages int) (binary.ByteOrder, []uint32, error) {
+	if len(data) < 8 {
+		return nil, nil, fmt.Errorf("invalid TIFF header")
+	}
+	var order binary.ByteOrder
+	switch string(data[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return nil, nil, fmt.Errorf("invalid TIFF header")
+	}
+
+	var offsets []uint32
+	seen := make(map[uint32]bool)
+	for offset := order.Uint32(data[4:8]); offset != 0; {
+		if seen[offset] {
+			return nil, nil, fmt.Errorf("TIFF IFD chain has a loop")
+		}
+		seen[offset] = true
+		next, err := tiffNextIFD(data, order, offset)
+		if err != nil {
+			return nil, nil, err
+		}
+		offsets = append(offsets, offset)
+		if len(offsets) > maxPages {
+			return nil, nil, fmt.Errorf("%w: more than %d pages", ErrTooManyPages, maxPages)
+		}
+		offset = order.Uint32(data[next:])
+	}
+	if len(offsets) == 0 {
+		return nil, nil, fmt.Errorf("TIFF has no pages")
+	}
+	return order, offsets, nil
+}
+
+// tiffNextIFD trả về vị trí của con trỏ tới IFD tiếp theo, nằm ngay sau các entry của IFD tại offset
+func tiffNextIFD(data []byte, order binary.ByteOrder, offset uint32) (int, error) {
+	if int64(offset)+2 > int64(len(data)) {
+		return 0, fmt.Errorf("TIFF IFD offset %d out of range", offset)
+	}
+	count := int(order.Uint16(data[offset:]))
+	next := int(offset) + 2 + count*12
+	if next+4 > len(data) {
+		return 0, fmt.Errorf("TIFF IFD at %d is truncated", offset)
+	}
+	return next, nil
+}
+
+// decodeTIFF giải mã frame đầu của data nếu kích thước không vượt quá imaging.MaxPixels
+func decodeTIFF(data []byte) (image.Image, error) {
+	cfg, err := tiff.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if err := imaging.CheckSize(cfg.Width, cfg.Height); err != nil {
+		return nil, err
+	}
+	return tiff.Decode(bytes.NewReader(data))
+}
+
+// writeTIFFPage giải mã frame có IFD tại offset và ghi ra file PNG. data được sửa tạm thời và khôi phục sau khi giải mã.
+func writeTIFFPage(data []byte, order binary.ByteOrder, offset uint32, out string) error {
+	next, err := tiffNextIFD(data, order, offset)
+	if err != nil {
+		return err
+	}
+	first := order.Uint32(data[4:8])
+	following := order.Uint32(data[next:])
+	order.PutUint32(data[4:8], offset)
+	order.PutUint32(data[next:], 0)
+	img, err := decodeTIFF(data)
+	order.PutUint32(data[4:8], first)
+	order.PutUint32(data[next:], following)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
+
+// run chạy một lệnh của poppler-utils và trả về stdout; lỗi kèm stderr của lệnh
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("%s failed: %s", name, message)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Artifact là file kết quả của một task (ví dụ ảnh đã xoá nền), được tham chiếu bằng ID ổn định.
+// Path là đường dẫn tương đối trong thư mục ảnh dùng chung, chỉ dùng nội bộ; client tải file qua URL.
+// Width và Height chỉ có với ảnh.
+type Artifact struct {
+	ID          string    `json:"id"`
+	TaskID      int       `json:"task_id"`
+	Path        string    `json:"-"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Width       int       `json:"width,omitempty"`
+	Height      int       `json:"height,omitempty"`
+	URL         string    `json:"url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ArtifactURL là đường dẫn tải file của artifact id qua management-api
+func ArtifactURL(id string) string {
+	return "/artifacts/" + id + "/download"
+}
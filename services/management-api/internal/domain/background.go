@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// Định dạng ảnh kết quả của remove-bg
+const (
+	ImageFormatPNG  = "png"
+	ImageFormatWebP = "webp"
+)
+
+// MaxCropPadding là phần đệm tối đa (pixel) quanh chủ thể khi remove-bg cắt ảnh
+const MaxCropPadding = 1000
+
+// ValidateBackgroundRemoval kiểm tra các tuỳ chọn của remove-bg: chỉ một loại nền thay thế
+// (background_color hoặc ảnh nền), mask_only không đi cùng nền thay thế, crop_padding chỉ dùng với crop
+func ValidateBackgroundRemoval(input ToolInput) error {
+	if input.BackgroundColor != "" {
+		if _, err := ParseHexColor(input.BackgroundColor); err != nil {
+			return err
+		}
+	}
+	hasImage := input.BackgroundFilePath != "" || input.BackgroundURL != ""
+	if input.BackgroundColor != "" && hasImage {
+		return fmt.Errorf("use either 'background_color' or a background image, not both")
+	}
+	if input.MaskOnly && (input.BackgroundColor != "" || hasImage) {
+		return fmt.Errorf("'mask_only' cannot be combined with a background")
+	}
+	if input.CropPadding < 0 || input.CropPadding > MaxCropPadding {
+		return fmt.Errorf("invalid 'crop_padding' %d, must be between 0 and %d", input.CropPadding, MaxCropPadding)
+	}
+	if input.CropPadding > 0 && !input.Crop {
+		return fmt.Errorf("'crop_padding' requires 'crop'")
+	}
+	switch input.Format {
+	case "", ImageFormatPNG, ImageFormatWebP:
+	default:
+		return fmt.Errorf("invalid 'format' '%s', must be %s or %s", input.Format, ImageFormatPNG, ImageFormatWebP)
+	}
+	return nil
+}
+
+// ParseHexColor đọc màu dạng "#rrggbb" hoặc "#rrggbbaa" (dấu # không bắt buộc)
+func ParseHexColor(value string) (color.NRGBA, error) {
+	digits := strings.TrimPrefix(value, "#")
+	b, err := hex.DecodeString(digits)
+	if err != nil || (len(b) != 3 && len(b) != 4) {
+		return color.NRGBA{}, fmt.Errorf("invalid color '%s', expected #rrggbb or #rrggbbaa", value)
+	}
+	c := color.NRGBA{R: b[0], G: b[1], B: b[2], A: 255}
+	if len(b) == 4 {
+		c.A = b[3]
+	}
+	return c, nil
+}
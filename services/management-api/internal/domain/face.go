@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// FaceEncoding là một khuôn mặt trong ảnh cùng embedding do service face-recognition tính
+type FaceEncoding struct {
+	Face      FaceBox
+	Embedding []float64
+}
+
+// FaceGallery là một tập người đã đăng ký khuôn mặt, dùng để tìm người trong ảnh
+type FaceGallery struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// PersonCount và EnrollmentCount là số người và số khuôn mặt đã đăng ký
+	PersonCount     int       `json:"person_count"`
+	EnrollmentCount int       `json:"enrollment_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// FaceEnrollment là một khuôn mặt đã đăng ký của một người trong gallery.
+// Một người có thể có nhiều enrollment (nhiều ảnh); ảnh gốc không được lưu.
+type FaceEnrollment struct {
+	ID        int                    `json:"id"`
+	GalleryID int                    `json:"gallery_id"`
+	PersonID  string                 `json:"person_id"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	// Face là vị trí khuôn mặt trong ảnh đã dùng để đăng ký
+	Face      FaceBox   `json:"face"`
+	Embedding []float64 `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FaceMatch là một người trong gallery giống khuôn mặt cần tìm; EnrollmentID là
+// enrollment giống nhất của người đó
+type FaceMatch struct {
+	PersonID     string                 `json:"person_id"`
+	EnrollmentID int                    `json:"enrollment_id"`
+	Similarity   float64                `json:"similarity"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// FaceSearchFace là một khuôn mặt trong ảnh tìm kiếm cùng các người giống nhất, similarity giảm dần
+type FaceSearchFace struct {
+	Face    FaceBox     `json:"face"`
+	Matches []FaceMatch `json:"matches"`
+}
+
+// FaceSearchResult là kết quả tìm mọi khuôn mặt của ảnh trong gallery
+type FaceSearchResult struct {
+	Gallery   string           `json:"gallery"`
+	FaceCount int              `json:"face_count"`
+	Faces     []FaceSearchFace `json:"faces"`
+}
+
+// Các vấn đề chất lượng của khuôn mặt trong FaceQuality.Issues
+const (
+	FaceIssueTooSmall    = "too_small"
+	FaceIssueTooDark     = "too_dark"
+	FaceIssueTooBright   = "too_bright"
+	FaceIssueLowContrast = "low_contrast"
+	FaceIssueBlurry      = "blurry"
+)
+
+// Các dấu hiệu trong FaceLiveness.Hints
+const (
+	// LivenessLowSaturation: màu nhạt bất thường, thường gặp ở ảnh in hoặc ảnh chụp lại màn hình
+	LivenessLowSaturation = "low_saturation"
+	// LivenessGlare: nhiều điểm chói, thường gặp khi chụp lại màn hình hoặc ảnh ép plastic
+	LivenessGlare = "glare"
+	// LivenessLowTexture: vùng mặt gần như không có chi tiết da
+	LivenessLowTexture = "low_texture"
+	// LivenessMultipleFaces: ảnh có nhiều hơn một khuôn mặt
+	LivenessMultipleFaces = "multiple_faces"
+)
+
+// FaceQuality là chất lượng ảnh của vùng khuôn mặt. Score từ 0 đến 1, giảm theo số vấn đề trong Issues.
+type FaceQuality struct {
+	Score  float64 `json:"score"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	// Brightness là độ sáng trung bình (0-255), Contrast là độ lệch chuẩn của độ sáng
+	Brightness float64 `json:"brightness"`
+	Contrast   float64 `json:"contrast"`
+	// Sharpness là phương sai của Laplacian, càng nhỏ ảnh càng mờ
+	Sharpness float64  `json:"sharpness"`
+	Issues    []string `json:"issues"`
+}
+
+// FaceLiveness là các gợi ý heuristic cho thấy ảnh có thể được chụp lại từ ảnh in hoặc màn hình.
+// Đây không phải kiểm tra liveness: Suspicious chỉ nên dùng để chuyển hồ sơ sang kiểm tra thủ công.
+type FaceLiveness struct {
+	Suspicious bool     `json:"suspicious"`
+	Hints      []string `json:"hints"`
+	// Saturation là độ bão hoà màu trung bình (0-1), Glare là tỉ lệ pixel gần trắng của vùng khuôn mặt
+	Saturation float64 `json:"saturation"`
+	Glare      float64 `json:"glare"`
+}
+
+// FaceVerifyFace là khuôn mặt được đem so khớp: khuôn mặt lớn nhất của ảnh, hoặc enrollment giống
+// nhất của người trong gallery. Quality và Liveness chỉ có khi khuôn mặt lấy từ ảnh.
+type FaceVerifyFace struct {
+	Face FaceBox `json:"face"`
+	// FaceCount là số khuôn mặt tìm thấy trong ảnh
+	FaceCount    int           `json:"face_count,omitempty"`
+	Gallery      string        `json:"gallery,omitempty"`
+	PersonID     string        `json:"person_id,omitempty"`
+	EnrollmentID int           `json:"enrollment_id,omitempty"`
+	Quality      *FaceQuality  `json:"quality,omitempty"`
+	Liveness     *FaceLiveness `json:"liveness,omitempty"`
+}
+
+// FaceVerifyResult là kết quả so khớp 1:1 của face-verify: Match khi Similarity từ Threshold trở lên
+type FaceVerifyResult struct {
+	Match      bool           `json:"match"`
+	Similarity float64        `json:"similarity"`
+	Threshold  float64        `json:"threshold"`
+	Probe      FaceVerifyFace `json:"probe"`
+	Reference  FaceVerifyFace `json:"reference"`
+}
+
+// Các cách che khuôn mặt của face-anonymize
+const (
+	AnonymizeBlur     = "blur"
+	AnonymizePixelate = "pixelate"
+	// AnonymizeBox tô kín khuôn mặt bằng màu đen, không dùng strength
+	AnonymizeBox = "box"
+)
+
+// DefaultAnonymizeStrength là mức che khi input không chỉ định strength
+const DefaultAnonymizeStrength = 50
+
+// ValidateAnonymize kiểm tra mode (rỗng là blur) và strength (0 là mặc định, 1-100) của face-anonymize
+func ValidateAnonymize(mode string, strength int) error {
+	switch mode {
+	case "", AnonymizeBlur, AnonymizePixelate, AnonymizeBox:
+	default:
+		return fmt.Errorf("invalid 'mode' '%s', must be %s, %s or %s", mode, AnonymizeBlur, AnonymizePixelate, AnonymizeBox)
+	}
+	if strength < 0 || strength > 100 {
+		return fmt.Errorf("invalid 'strength' %d, must be between 1 and 100", strength)
+	}
+	return nil
+}
+
+// FaceAnonymizeResult là kết quả của face-anonymize. ImagePath là ảnh đã che khuôn mặt trong thư mục
+// ảnh dùng chung, được ghi thành artifact ArtifactID và tải qua ImageURL; ảnh không giữ metadata (EXIF)
+// của ảnh gốc.
+type FaceAnonymizeResult struct {
+	FaceCount  int       `json:"face_count"`
+	Faces      []FaceBox `json:"faces"`
+	Mode       string    `json:"mode"`
+	Strength   int       `json:"strength"`
+	ImagePath  string    `json:"image_path"`
+	ArtifactID string    `json:"artifact_id,omitempty"`
+	ImageURL   string    `json:"image_url,omitempty"`
+}
+
+// CosineSimilarity trả về độ tương đồng cosine (-1 đến 1) của hai embedding;
+// 0 nếu hai embedding khác số chiều hoặc có độ dài bằng 0
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
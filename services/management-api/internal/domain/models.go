@@ -1,13 +1,381 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+// Tên các tool, trùng với đường dẫn endpoint tương ứng
+const (
+	ToolTextToVoice       = "tts"
+	ToolVoiceToText       = "vts"
+	ToolBackgroundRemoval = "remove-bg"
+	ToolSpeechRecognition = "speech-recognition"
+	ToolFaceRecognition   = "face-recognition"
+	ToolFaceVerify        = "face-verify"
+	ToolFaceAnonymize     = "face-anonymize"
+	ToolOCR               = "ocr"
+	ToolTranslation       = "translate"
+)
+
+// Tools là danh sách tất cả các tool được hỗ trợ
+var Tools = []string{
+	ToolTextToVoice,
+	ToolVoiceToText,
+	ToolBackgroundRemoval,
+	ToolSpeechRecognition,
+	ToolFaceRecognition,
+	ToolFaceVerify,
+	ToolFaceAnonymize,
+	ToolOCR,
+	ToolTranslation,
+}
+
+// IsValidTool kiểm tra tên tool có được hỗ trợ hay không
+func IsValidTool(tool string) bool {
+	for _, t := range Tools {
+		if t == tool {
+			return true
+		}
+	}
+	return false
+}
 
 type Task struct {
-	ID          int       `json:"id"`
-	ServiceName string    `json:"service_name"`
-	Status      string    `json:"status"`
-	InputData   []byte    `json:"input_data"`
-	OutputData  []byte    `json:"output_data"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                int             `json:"id"`
+	ServiceName       string          `json:"service_name"`
+	Status            TaskStatus      `json:"status"`
+	InputData         json.RawMessage `json:"input_data"`
+	OutputData        json.RawMessage `json:"output_data"`
+	BatchID           *int            `json:"batch_id,omitempty"`
+	PipelineID        *int            `json:"pipeline_id,omitempty"`
+	PipelineStep      *string         `json:"pipeline_step,omitempty"`
+	TemplateVersionID *int            `json:"template_version_id,omitempty"`
+	CallbackURL       *string         `json:"callback_url,omitempty"`
+	Attempt           int             `json:"attempt"`
+	ParentTaskID      *int            `json:"parent_task_id,omitempty"`
+	// DocumentTaskID và PageNumber có ở task OCR một trang của tài liệu nhiều trang
+	DocumentTaskID *int       `json:"document_task_id,omitempty"`
+	PageNumber     *int       `json:"page_number,omitempty"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// ToolInput là đầu vào chung của một lần chạy tool (dùng cho batch).
+// Chỉ những trường mà tool cần mới được sử dụng.
+type ToolInput struct {
+	Text     string `json:"text,omitempty"`
+	Language string `json:"language,omitempty"`
+	DestLang string `json:"dest_lang,omitempty"`
+	AudioURL string `json:"audio_url,omitempty"`
+	// URL là file hoặc text được tải về trước khi chạy tool
+	URL string `json:"url,omitempty"`
+	// FilePath là file đã được lưu trên server (ảnh hoặc audio)
+	FilePath string `json:"file_path,omitempty"`
+	// Languages là ngôn ngữ nhận dạng của OCR theo mã tesseract, ví dụ "vie+eng"
+	Languages string `json:"languages,omitempty"`
+	// Output là định dạng kết quả OCR: text, hocr, json hoặc tsv
+	Output string `json:"output,omitempty"`
+	// SearchablePDF yêu cầu OCR tạo thêm PDF có lớp text (searchable PDF)
+	SearchablePDF bool `json:"searchable_pdf,omitempty"`
+	// Preprocess là các bước tiền xử lý ảnh trước khi chạy ocr hoặc face-recognition, ví dụ ["auto_orient", "deskew"]
+	Preprocess []string `json:"preprocess,omitempty"`
+	// MaxDimension là cạnh dài tối đa của ảnh với bước downscale; 0 dùng giá trị mặc định của server
+	MaxDimension int `json:"max_dimension,omitempty"`
+	// Preprocessing là các bước tiền xử lý đã áp dụng, do management-api ghi lại khi chạy task
+	Preprocessing *Preprocessing `json:"preprocessing,omitempty"`
+	// ReferenceFilePath và ReferenceURL là ảnh tham chiếu của face-verify (ví dụ ảnh CCCD)
+	ReferenceFilePath string `json:"reference_file_path,omitempty"`
+	ReferenceURL      string `json:"reference_url,omitempty"`
+	// Gallery và PersonID là người đã đăng ký khuôn mặt, dùng làm tham chiếu của face-verify thay cho ảnh
+	Gallery  string `json:"gallery,omitempty"`
+	PersonID string `json:"person_id,omitempty"`
+	// Threshold là ngưỡng similarity của face-verify (-1 đến 1); 0 dùng giá trị mặc định của server
+	Threshold float64 `json:"threshold,omitempty"`
+	// Mode và Strength là cách che khuôn mặt của face-anonymize (blur, pixelate, box) và mức che (1-100)
+	Mode     string `json:"mode,omitempty"`
+	Strength int    `json:"strength,omitempty"`
+	// BackgroundColor ("#rrggbb" hoặc "#rrggbbaa") hoặc ảnh nền BackgroundFilePath/BackgroundURL
+	// thay cho nền đã xoá của remove-bg; không có thì nền trong suốt
+	BackgroundColor    string `json:"background_color,omitempty"`
+	BackgroundFilePath string `json:"background_file_path,omitempty"`
+	BackgroundURL      string `json:"background_url,omitempty"`
+	// AlphaMatting yêu cầu service remove-bg tinh chỉnh viền chủ thể (tóc, lông) bằng alpha matting
+	AlphaMatting bool `json:"alpha_matting,omitempty"`
+	// Crop cắt ảnh remove-bg theo khung chủ thể, chừa CropPadding pixel mỗi phía
+	Crop        bool `json:"crop,omitempty"`
+	CropPadding int  `json:"crop_padding,omitempty"`
+	// MaskOnly trả về mask của chủ thể (trắng là chủ thể, đen là nền) thay cho ảnh đã xoá nền
+	MaskOnly bool `json:"mask_only,omitempty"`
+	// Format là định dạng ảnh kết quả của remove-bg: png (mặc định) hoặc webp
+	Format string `json:"format,omitempty"`
+}
+
+// ToolInputRequest là input của tool do client gửi lên. Khác ToolInput, request không có các đường dẫn
+// file trên server (file_path, reference_file_path, background_file_path, preprocessing): các trường này
+// chỉ được gán từ file mà server đã lưu.
+type ToolInputRequest struct {
+	Text            string   `json:"text,omitempty"`
+	Language        string   `json:"language,omitempty"`
+	DestLang        string   `json:"dest_lang,omitempty"`
+	AudioURL        string   `json:"audio_url,omitempty"`
+	URL             string   `json:"url,omitempty"`
+	Languages       string   `json:"languages,omitempty"`
+	Output          string   `json:"output,omitempty"`
+	SearchablePDF   bool     `json:"searchable_pdf,omitempty"`
+	Preprocess      []string `json:"preprocess,omitempty"`
+	MaxDimension    int      `json:"max_dimension,omitempty"`
+	ReferenceURL    string   `json:"reference_url,omitempty"`
+	Gallery         string   `json:"gallery,omitempty"`
+	PersonID        string   `json:"person_id,omitempty"`
+	Threshold       float64  `json:"threshold,omitempty"`
+	Mode            string   `json:"mode,omitempty"`
+	Strength        int      `json:"strength,omitempty"`
+	BackgroundColor string   `json:"background_color,omitempty"`
+	BackgroundURL   string   `json:"background_url,omitempty"`
+	AlphaMatting    bool     `json:"alpha_matting,omitempty"`
+	Crop            bool     `json:"crop,omitempty"`
+	CropPadding     int      `json:"crop_padding,omitempty"`
+	MaskOnly        bool     `json:"mask_only,omitempty"`
+	Format          string   `json:"format,omitempty"`
+}
+
+// ToolInput chuyển request thành ToolInput, chưa có file nào trên server
+func (r ToolInputRequest) ToolInput() ToolInput {
+	return ToolInput{
+		Text:            r.Text,
+		Language:        r.Language,
+		DestLang:        r.DestLang,
+		AudioURL:        r.AudioURL,
+		URL:             r.URL,
+		Languages:       r.Languages,
+		Output:          r.Output,
+		SearchablePDF:   r.SearchablePDF,
+		Preprocess:      r.Preprocess,
+		MaxDimension:    r.MaxDimension,
+		ReferenceURL:    r.ReferenceURL,
+		Gallery:         r.Gallery,
+		PersonID:        r.PersonID,
+		Threshold:       r.Threshold,
+		Mode:            r.Mode,
+		Strength:        r.Strength,
+		BackgroundColor: r.BackgroundColor,
+		BackgroundURL:   r.BackgroundURL,
+		AlphaMatting:    r.AlphaMatting,
+		Crop:            r.Crop,
+		CropPadding:     r.CropPadding,
+		MaskOnly:        r.MaskOnly,
+		Format:          r.Format,
+	}
+}
+
+// HasServerFiles kiểm tra input có trường đường dẫn file trên server hay không
+func (i ToolInput) HasServerFiles() bool {
+	return i.FilePath != "" || i.ReferenceFilePath != "" || i.BackgroundFilePath != "" || i.Preprocessing != nil
+}
+
+// Batch là một nhóm task con cùng chạy một tool
+type Batch struct {
+	ID        int           `json:"id"`
+	Tool      string        `json:"tool"`
+	Status    string        `json:"status"`
+	Progress  BatchProgress `json:"progress"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// BatchProgress là tiến độ tổng hợp của các task con trong batch
+type BatchProgress struct {
+	Total      int     `json:"total"`
+	Pending    int     `json:"pending"`
+	Queued     int     `json:"queued"`
+	Processing int     `json:"processing"`
+	Completed  int     `json:"completed"`
+	Failed     int     `json:"failed"`
+	Cancelled  int     `json:"cancelled"`
+	Expired    int     `json:"expired"`
+	Percent    float64 `json:"percent"`
+}
+
+// SpeechMark là mốc thời gian của một từ hoặc một câu trong audio TTS.
+// CharStart/CharEnd là vị trí rune trong text gốc, theo dạng [start, end).
+type SpeechMark struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	StartMs   int    `json:"start_ms"`
+	EndMs     int    `json:"end_ms"`
+	CharStart int    `json:"char_start"`
+	CharEnd   int    `json:"char_end"`
+}
+
+// TextToVoiceResult là kết quả của service Text-to-Voice
+type TextToVoiceResult struct {
+	AudioURL string       `json:"audio_url"`
+	Marks    []SpeechMark `json:"marks"`
+	// MarksSource là "estimated": mốc được ước lượng bằng cách căn chỉnh text với audio, không chính xác tuyệt đối
+	MarksSource string `json:"marks_source,omitempty"`
+}
+
+// TextResult là kết quả của Voice-to-Text và Speech Recognition
+type TextResult struct {
+	Text string `json:"text"`
+}
+
+// TranslationResult là kết quả của service Translation
+type TranslationResult struct {
+	TranslatedText string `json:"translated_text"`
+}
+
+// FaceRecognitionResult là kết quả của service Face Recognition
+type FaceRecognitionResult struct {
+	FaceCount int       `json:"face_count"`
+	Faces     []FaceBox `json:"faces,omitempty"`
+}
+
+// FaceBox là vị trí một khuôn mặt trong ảnh, tính theo pixel
+type FaceBox struct {
+	Top    int `json:"top"`
+	Right  int `json:"right"`
+	Bottom int `json:"bottom"`
+	Left   int `json:"left"`
+}
+
+// BackgroundRemovalResult là kết quả của service Background Removal.
+// ProcessedImagePath là đường dẫn tương đối trong /shared/images. ArtifactID và URL (đường dẫn tải ảnh)
+// có khi kết quả được ghi vào task. Format, Width và Height chỉ có khi management-api xử lý thêm ảnh
+// (nền thay thế, crop, mask_only hoặc webp); SubjectBox là khung chủ thể trong ảnh gốc khi crop.
+type BackgroundRemovalResult struct {
+	ProcessedImagePath string       `json:"processed_image_path"`
+	ArtifactID         string       `json:"artifact_id,omitempty"`
+	URL                string       `json:"url,omitempty"`
+	Format             string       `json:"format,omitempty"`
+	Width              int          `json:"width,omitempty"`
+	Height             int          `json:"height,omitempty"`
+	SubjectBox         *BoundingBox `json:"subject_box,omitempty"`
+}
+
+// PipelineStep là một bước trong pipeline.
+// Inputs ánh xạ trường của ToolInput tới một tham chiếu: "input.<field>" lấy từ
+// input của pipeline, "params.<name>" lấy từ tham số khi chạy template,
+// "steps.<id>.<path>" lấy từ output của bước trước (ví dụ "steps.ocr.text").
+// Các bước được tham chiếu và DependsOn phải chạy xong trước.
+type PipelineStep struct {
+	ID        string            `json:"id"`
+	Tool      string            `json:"tool"`
+	Params    ToolInput         `json:"params"`
+	Inputs    map[string]string `json:"inputs,omitempty"`
+	DependsOn []string          `json:"depends_on,omitempty"`
+}
+
+// Pipeline là một chuỗi (hoặc DAG) các bước, mỗi bước chạy một tool
+type Pipeline struct {
+	ID                int                    `json:"id"`
+	Status            string                 `json:"status"`
+	Input             ToolInput              `json:"input"`
+	Params            map[string]interface{} `json:"params,omitempty"`
+	Steps             []PipelineStep         `json:"steps"`
+	TemplateVersionID *int                   `json:"template_version_id,omitempty"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
+
+// Kiểu dữ liệu của tham số template
+const (
+	ParamTypeString  = "string"
+	ParamTypeNumber  = "number"
+	ParamTypeBoolean = "boolean"
+)
+
+// TemplateParameter mô tả một tham số của pipeline template, được kiểm tra khi chạy
+type TemplateParameter struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	Required    bool          `json:"required,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Description string        `json:"description,omitempty"`
+}
+
+// PipelineTemplate là pipeline được lưu theo tên để chạy lại nhiều lần
+type PipelineTemplate struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	LatestVersion int       `json:"latest_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// PipelineTemplateVersion là một phiên bản bất biến của template
+type PipelineTemplateVersion struct {
+	ID         int                 `json:"id"`
+	TemplateID int                 `json:"template_id"`
+	Version    int                 `json:"version"`
+	Steps      []PipelineStep      `json:"steps"`
+	Parameters []TemplateParameter `json:"parameters"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// Sự kiện webhook gửi tới callback_url khi task kết thúc
+const (
+	WebhookEventTaskCompleted = "task.completed"
+	WebhookEventTaskFailed    = "task.failed"
+	WebhookEventTaskCancelled = "task.cancelled"
+)
+
+// WebhookEvent trả về sự kiện webhook của task ở status; false nếu status không có sự kiện (ví dụ task chưa kết thúc)
+func WebhookEvent(status TaskStatus) (string, bool) {
+	switch status {
+	case TaskStatusCompleted:
+		return WebhookEventTaskCompleted, true
+	case TaskStatusFailed:
+		return WebhookEventTaskFailed, true
+	case TaskStatusCancelled:
+		return WebhookEventTaskCancelled, true
+	}
+	return "", false
+}
+
+// WebhookPayload là body JSON gửi tới callback_url
+type WebhookPayload struct {
+	Event string `json:"event"`
+	Task  Task   `json:"task"`
+}
+
+// WebhookDelivery là một lần gửi webhook (mỗi lần thử lại là một bản ghi)
+type WebhookDelivery struct {
+	ID         int       `json:"id"`
+	TaskID     int       `json:"task_id"`
+	URL        string    `json:"url"`
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+	StatusCode *int      `json:"status_code,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RetentionFile là một file bị (hoặc sẽ bị) janitor xoá
+type RetentionFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// TaskID là task tham chiếu tới file; nil nếu file không thuộc task nào và đã quá hạn
+	TaskID *int `json:"task_id,omitempty"`
+}
+
+// RetentionReport là kết quả một lần dọn dẹp. Với DryRun, chưa có gì bị xoá.
+type RetentionReport struct {
+	DryRun    bool            `json:"dry_run"`
+	Files     []RetentionFile `json:"files"`
+	FileCount int             `json:"file_count"`
+	FileBytes int64           `json:"file_bytes"`
+	// FilesTruncated cho biết danh sách Files bị cắt bớt (FileCount vẫn là tổng số)
+	FilesTruncated bool `json:"files_truncated"`
+	// Tasks là số task hết hạn theo tool; TaskAction là "archive" hoặc "purge"
+	Tasks      map[string]int `json:"tasks"`
+	TaskCount  int            `json:"task_count"`
+	TaskAction string         `json:"task_action"`
+	CreatedAt  time.Time      `json:"created_at"`
 }
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Định dạng kết quả OCR
+const (
+	OCROutputText = "text"
+	OCROutputHOCR = "hocr"
+	OCROutputJSON = "json"
+	OCROutputTSV  = "tsv"
+)
+
+// ocrLanguagesPattern là danh sách mã ngôn ngữ tesseract nối bằng "+", ví dụ "vie+eng" hoặc "chi_sim"
+var ocrLanguagesPattern = regexp.MustCompile(`^[a-z_]+(\+[a-z_]+)*$`)
+
+// ValidateOCROptions kiểm tra languages và output của OCR; giá trị rỗng dùng mặc định của service ("eng", "text")
+func ValidateOCROptions(languages, output string) error {
+	if languages != "" && !ocrLanguagesPattern.MatchString(languages) {
+		return fmt.Errorf("invalid 'languages' '%s'", languages)
+	}
+	switch output {
+	case "", OCROutputText, OCROutputHOCR, OCROutputJSON, OCROutputTSV:
+		return nil
+	default:
+		return fmt.Errorf("invalid 'output' '%s'", output)
+	}
+}
+
+// OCRResult là kết quả của service OCR. Blocks và Orientation chỉ có với output json,
+// HOCR với output hocr, TSV với output tsv.
+// Với tài liệu nhiều trang (PDF, TIFF), Text là text của các trang nối bằng "\f", PageCount và Pages
+// là kết quả từng trang. SearchablePDF là đường dẫn PDF có lớp text trong thư mục ảnh dùng chung,
+// được ghi thành artifact SearchablePDFArtifactID và tải qua SearchablePDFURL; chỉ có khi input yêu cầu searchable_pdf.
+type OCRResult struct {
+	Text                    string          `json:"text"`
+	Languages               string          `json:"languages,omitempty"`
+	Orientation             *OCROrientation `json:"orientation,omitempty"`
+	Blocks                  []OCRBlock      `json:"blocks,omitempty"`
+	HOCR                    string          `json:"hocr,omitempty"`
+	TSV                     string          `json:"tsv,omitempty"`
+	PageCount               int             `json:"page_count,omitempty"`
+	Pages                   []OCRPage       `json:"pages,omitempty"`
+	SearchablePDF           string          `json:"searchable_pdf,omitempty"`
+	SearchablePDFArtifactID string          `json:"searchable_pdf_artifact_id,omitempty"`
+	SearchablePDFURL        string          `json:"searchable_pdf_url,omitempty"`
+	// PDF là nội dung searchable PDF của một trang do service trả về, không được lưu vào task
+	PDF []byte `json:"-"`
+}
+
+// OCRPage là kết quả OCR một trang của tài liệu; TaskID là task con của trang (0 nếu trang không có task).
+// Result chỉ có khi trang completed, Error khi trang failed.
+type OCRPage struct {
+	Page   int        `json:"page"`
+	TaskID int        `json:"task_id,omitempty"`
+	Status TaskStatus `json:"status"`
+	Error  string     `json:"error,omitempty"`
+	Result *OCRResult `json:"result,omitempty"`
+}
+
+// OCROrientation là hướng trang: Rotate là số độ cần xoay để trang đứng thẳng
+type OCROrientation struct {
+	Rotate     int     `json:"rotate"`
+	Confidence float64 `json:"confidence"`
+}
+
+// BoundingBox là một vùng trong ảnh (vùng chữ của OCR, chủ thể của remove-bg), tính theo pixel
+type BoundingBox struct {
+	Left   int `json:"left"`
+	Top    int `json:"top"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// OCRBlock là một khối chữ; Confidence từ 0 đến 100, -1 nếu không xác định
+type OCRBlock struct {
+	Text       string      `json:"text"`
+	BBox       BoundingBox `json:"bbox"`
+	Confidence float64     `json:"confidence"`
+	Lines      []OCRLine   `json:"lines"`
+}
+
+type OCRLine struct {
+	Text       string      `json:"text"`
+	BBox       BoundingBox `json:"bbox"`
+	Confidence float64     `json:"confidence"`
+	Words      []OCRWord   `json:"words"`
+}
+
+type OCRWord struct {
+	Text       string      `json:"text"`
+	BBox       BoundingBox `json:"bbox"`
+	Confidence float64     `json:"confidence"`
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "fmt"
+
+// Các bước tiền xử lý ảnh trước khi gửi tới service OCR hoặc face-recognition
+const (
+	// PreprocessAutoOrient xoay ảnh theo tag Orientation trong EXIF (ảnh chụp bằng điện thoại)
+	PreprocessAutoOrient = "auto_orient"
+	// PreprocessDownscale thu nhỏ ảnh để cạnh dài không vượt quá MaxDimension
+	PreprocessDownscale = "downscale"
+	// PreprocessGrayscale chuyển ảnh sang ảnh xám
+	PreprocessGrayscale = "grayscale"
+	// PreprocessBinarize chuyển ảnh sang đen trắng với ngưỡng Otsu
+	PreprocessBinarize = "binarize"
+	// PreprocessDeskew xoay ảnh văn bản bị nghiêng về thẳng hàng
+	PreprocessDeskew = "deskew"
+)
+
+// preprocessSteps là các bước tiền xử lý mà mỗi tool hỗ trợ
+var preprocessSteps = map[string][]string{
+	ToolOCR:             {PreprocessAutoOrient, PreprocessDownscale, PreprocessGrayscale, PreprocessBinarize, PreprocessDeskew},
+	ToolFaceRecognition: {PreprocessAutoOrient, PreprocessDownscale},
+	ToolFaceAnonymize:   {PreprocessAutoOrient, PreprocessDownscale},
+}
+
+// ValidatePreprocess kiểm tra các bước tiền xử lý của tool: ocr hỗ trợ mọi bước,
+// face-recognition và face-anonymize chỉ hỗ trợ auto_orient và downscale, các tool khác không hỗ trợ bước nào
+func ValidatePreprocess(tool string, steps []string, maxDimension int) error {
+	if maxDimension < 0 {
+		return fmt.Errorf("invalid 'max_dimension' %d", maxDimension)
+	}
+	for _, step := range steps {
+		supported := false
+		for _, s := range preprocessSteps[tool] {
+			if s == step {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("preprocess step '%s' is not supported by '%s'", step, tool)
+		}
+	}
+	return nil
+}
+
+// Preprocessing ghi lại kết quả tiền xử lý ảnh đầu vào của task
+type Preprocessing struct {
+	// FilePath là ảnh sau tiền xử lý, được gửi tới service thay cho file_path
+	FilePath string `json:"file_path"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	// Operations là các bước đã thực sự làm thay đổi ảnh, theo thứ tự áp dụng
+	Operations []PreprocessOperation `json:"operations"`
+}
+
+// PreprocessOperation là một bước tiền xử lý đã áp dụng cùng tham số của bước đó
+type PreprocessOperation struct {
+	Name string `json:"name"`
+	// Orientation là giá trị EXIF Orientation (2-8) của ảnh gốc, với auto_orient
+	Orientation int `json:"orientation,omitempty"`
+	// From và To là kích thước ảnh trước và sau ("WxH"), với downscale
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Threshold là ngưỡng Otsu (0-255), với binarize
+	Threshold int `json:"threshold,omitempty"`
+	// Angle là số độ ảnh đã được xoay (chiều kim đồng hồ là dương), với deskew
+	Angle float64 `json:"angle,omitempty"`
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TaskResource là task trong API /v1: output được decode theo kiểu kết quả của tool,
+// lỗi được tách khỏi output
+type TaskResource struct {
+	ID     int        `json:"id"`
+	Tool   string     `json:"tool"`
+	Status TaskStatus `json:"status"`
+	// Input là input_data đã lưu của task
+	Input json.RawMessage `json:"input"`
+	// Output là kết quả của tool (ví dụ *TextResult), chỉ có khi task completed
+	Output       interface{} `json:"output"`
+	Error        *TaskError  `json:"error,omitempty"`
+	Attempt      int         `json:"attempt"`
+	ParentTaskID *int        `json:"parent_task_id,omitempty"`
+	// DocumentTaskID và PageNumber có ở task OCR một trang của tài liệu nhiều trang
+	DocumentTaskID *int       `json:"document_task_id,omitempty"`
+	PageNumber     *int       `json:"page_number,omitempty"`
+	BatchID        *int       `json:"batch_id,omitempty"`
+	PipelineID     *int       `json:"pipeline_id,omitempty"`
+	CallbackURL    *string    `json:"callback_url,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TaskError là lỗi của task failed; Reason là mã lỗi, ví dụ FailureReasonTimeout.
+// Service và BackendStatus có khi lỗi đến từ service AI phía sau.
+type TaskError struct {
+	Message       string `json:"message"`
+	Reason        string `json:"reason,omitempty"`
+	Service       string `json:"service,omitempty"`
+	BackendStatus int    `json:"backend_status,omitempty"`
+}
+
+func NewTaskResource(task *Task) TaskResource {
+	resource := TaskResource{
+		ID:             task.ID,
+		Tool:           task.ServiceName,
+		Status:         task.Status,
+		Input:          task.InputData,
+		Attempt:        task.Attempt,
+		ParentTaskID:   task.ParentTaskID,
+		DocumentTaskID: task.DocumentTaskID,
+		PageNumber:     task.PageNumber,
+		BatchID:        task.BatchID,
+		PipelineID:     task.PipelineID,
+		CallbackURL:    task.CallbackURL,
+		CreatedAt:      task.CreatedAt,
+		StartedAt:      task.StartedAt,
+		FinishedAt:     task.FinishedAt,
+		UpdatedAt:      task.UpdatedAt,
+	}
+
+	switch task.Status {
+	case TaskStatusCompleted:
+		resource.Output = DecodeToolOutput(task.ServiceName, task.OutputData)
+	case TaskStatusFailed:
+		var failure struct {
+			Error         string `json:"error"`
+			Reason        string `json:"reason"`
+			Service       string `json:"service"`
+			BackendStatus int    `json:"backend_status"`
+		}
+		json.Unmarshal(task.OutputData, &failure)
+		resource.Error = &TaskError{
+			Message:       failure.Error,
+			Reason:        failure.Reason,
+			Service:       failure.Service,
+			BackendStatus: failure.BackendStatus,
+		}
+	}
+	return resource
+}
+
+// DecodeToolOutput decode output_data theo kiểu kết quả của tool.
+// Output của service không phải tool (hoặc không decode được) được trả về nguyên dạng JSON.
+func DecodeToolOutput(tool string, raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var output interface{}
+	switch tool {
+	case ToolTextToVoice:
+		output = &TextToVoiceResult{}
+	case ToolVoiceToText, ToolSpeechRecognition:
+		output = &TextResult{}
+	case ToolOCR:
+		output = &OCRResult{}
+	case ToolTranslation:
+		output = &TranslationResult{}
+	case ToolFaceRecognition:
+		output = &FaceRecognitionResult{}
+	case ToolFaceVerify:
+		output = &FaceVerifyResult{}
+	case ToolFaceAnonymize:
+		output = &FaceAnonymizeResult{}
+	case ToolBackgroundRemoval:
+		output = &BackgroundRemovalResult{}
+	default:
+		return raw
+	}
+	if err := json.Unmarshal(raw, output); err != nil {
+		return raw
+	}
+	return output
+}
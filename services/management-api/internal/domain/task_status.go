@@ -0,0 +1,84 @@
+package domain
+
+import "errors"
+
+// TaskStatus là trạng thái của task, khớp với CHECK constraint của cột tasks.status
+type TaskStatus string
+
+const (
+	// TaskStatusPending là task đã tạo nhưng chưa sẵn sàng chạy (ví dụ bước pipeline đang chờ bước trước)
+	TaskStatusPending TaskStatus = "pending"
+	// TaskStatusQueued là task đang chờ tới lượt chạy
+	TaskStatusQueued     TaskStatus = "queued"
+	TaskStatusProcessing TaskStatus = "processing"
+	TaskStatusCompleted  TaskStatus = "completed"
+	TaskStatusFailed     TaskStatus = "failed"
+	TaskStatusCancelled  TaskStatus = "cancelled"
+	// TaskStatusExpired là task chưa từng được chạy và đã quá hạn
+	TaskStatusExpired TaskStatus = "expired"
+)
+
+// FailureReasonTimeout là lý do ghi vào output_data khi task bị reaper đánh dấu failed
+const FailureReasonTimeout = "TIMEOUT"
+
+// FailureReasonBackendError là lý do ghi vào output_data khi service AI phía sau trả lỗi hoặc không gọi được
+const FailureReasonBackendError = "BACKEND_ERROR"
+
+// FailureReasonNoFace là lý do ghi vào output_data khi face-verify không có khuôn mặt để so khớp
+const FailureReasonNoFace = "NO_FACE"
+
+// TaskStatuses là danh sách tất cả các trạng thái của task
+var TaskStatuses = []TaskStatus{
+	TaskStatusPending,
+	TaskStatusQueued,
+	TaskStatusProcessing,
+	TaskStatusCompleted,
+	TaskStatusFailed,
+	TaskStatusCancelled,
+	TaskStatusExpired,
+}
+
+// ErrInvalidTransition là lỗi khi chuyển task sang trạng thái không hợp lệ
+var ErrInvalidTransition = errors.New("invalid task status transition")
+
+// taskTransitions liệt kê các trạng thái kế tiếp hợp lệ. Trạng thái kết thúc không có trạng thái kế tiếp.
+// processing -> queued là khi task bị treo được đưa lại vào hàng đợi để chạy lần nữa.
+var taskTransitions = map[TaskStatus][]TaskStatus{
+	TaskStatusPending:    {TaskStatusQueued, TaskStatusProcessing, TaskStatusFailed, TaskStatusCancelled, TaskStatusExpired},
+	TaskStatusQueued:     {TaskStatusProcessing, TaskStatusFailed, TaskStatusCancelled, TaskStatusExpired},
+	TaskStatusProcessing: {TaskStatusQueued, TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled},
+}
+
+func (s TaskStatus) IsValid() bool {
+	for _, status := range TaskStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal cho biết task đã kết thúc và không thể đổi trạng thái nữa
+func (s TaskStatus) IsTerminal() bool {
+	return s.IsValid() && len(taskTransitions[s]) == 0
+}
+
+func (s TaskStatus) CanTransitionTo(next TaskStatus) bool {
+	for _, status := range taskTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionSources là các trạng thái có thể chuyển sang next
+func TransitionSources(next TaskStatus) []TaskStatus {
+	var sources []TaskStatus
+	for _, status := range TaskStatuses {
+		if status.CanTransitionTo(next) {
+			sources = append(sources, status)
+		}
+	}
+	return sources
+}
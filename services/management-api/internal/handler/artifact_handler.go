@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"management-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ArtifactHandler struct {
+	service service.ArtifactService
+}
+
+func NewArtifactHandler(service service.ArtifactService) *ArtifactHandler {
+	return &ArtifactHandler{service: service}
+}
+
+// artifactError chuyển lỗi của ArtifactService thành HTTP status: 404 nếu không có artifact,
+// 410 nếu file của artifact đã bị xoá theo chính sách lưu trữ
+func artifactError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrArtifactNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrArtifactGone):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	default:
+		log.Printf("artifactError: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GetArtifact xử lý endpoint GET /artifacts/:id: content type, kích thước, số pixel và đường dẫn tải file
+func (h *ArtifactHandler) GetArtifact(c *gin.Context) {
+	artifact, err := h.service.GetArtifact(c.Param("id"))
+	if err != nil {
+		artifactError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, artifact)
+}
+
+// DownloadArtifact xử lý endpoint GET /artifacts/:id/download, trả về file với content type đã ghi lại
+func (h *ArtifactHandler) DownloadArtifact(c *gin.Context) {
+	artifact, path, err := h.service.ArtifactFile(c.Param("id"))
+	if err != nil {
+		artifactError(c, err)
+		return
+	}
+	c.Header("Content-Type", artifact.ContentType)
+	c.FileAttachment(path, artifact.ID+filepath.Ext(path))
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/service"
+	"management-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BatchHandler struct {
+	service service.BatchService
+	uploads config.UploadConfig
+}
+
+func NewBatchHandler(service service.BatchService, cfg *config.Config) *BatchHandler {
+	return &BatchHandler{service: service, uploads: cfg.Uploads}
+}
+
+// createBatchRequest là body JSON của POST /batches.
+// Params là giá trị mặc định áp dụng cho mọi input, ví dụ {"dest_lang": "vi"}.
+// files là các file tải lên đã được lưu trên server, mỗi file là một input sau Inputs.
+type createBatchRequest struct {
+	Tool   string                    `json:"tool" binding:"required"`
+	Params domain.ToolInputRequest   `json:"params"`
+	Inputs []domain.ToolInputRequest `json:"inputs"`
+	files  []string
+}
+
+// CreateBatch xử lý endpoint POST /batches.
+// Nhận JSON, hoặc multipart với các trường tool, params, inputs (JSON) và nhiều file "files".
+func (h *BatchHandler) CreateBatch(c *gin.Context) {
+	var req createBatchRequest
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		req, err = h.bindMultipartBatch(c)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		log.Printf("CreateBatch: Invalid input. Error: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if !domain.IsValidTool(req.Tool) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown tool '%s'", req.Tool)})
+		return
+	}
+	if len(req.Inputs) == 0 && len(req.files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing 'inputs'"})
+		return
+	}
+
+	if field := itemOnlyField(req.Params); field != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("'%s' must be set per input, not in 'params'", field)})
+		return
+	}
+	inputs := make([]domain.ToolInput, 0, len(req.Inputs)+len(req.files))
+	for _, item := range req.Inputs {
+		input, err := withDefaults(item, req.Params)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+		inputs = append(inputs, input)
+	}
+	for _, filePath := range req.files {
+		input := req.Params.ToolInput()
+		input.FilePath = filePath
+		inputs = append(inputs, input)
+	}
+
+	batch, err := h.service.CreateBatch(req.Tool, inputs)
+	if errors.Is(err, service.ErrInvalidToolInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("CreateBatch: Failed to create batch. Error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, batch)
+}
+
+// bindMultipartBatch đọc request multipart và lưu các file tải lên thành input của batch
+func (h *BatchHandler) bindMultipartBatch(c *gin.Context) (createBatchRequest, error) {
+	req := createBatchRequest{Tool: c.PostForm("tool")}
+	if params := c.PostForm("params"); params != "" {
+		if err := json.Unmarshal([]byte(params), &req.Params); err != nil {
+			return req, err
+		}
+	}
+	if inputs := c.PostForm("inputs"); inputs != "" {
+		if err := json.Unmarshal([]byte(inputs), &req.Inputs); err != nil {
+			return req, err
+		}
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return req, err
+	}
+
+	uploadPath := h.uploads.ImagePath
+	if req.Tool == domain.ToolVoiceToText || req.Tool == domain.ToolSpeechRecognition {
+		uploadPath = h.uploads.AudioPath
+	}
+	// Mỗi batch lưu file vào thư mục riêng để tránh trùng tên file giữa các batch
+	uploadPath = filepath.Join(uploadPath, "batches", strconv.FormatInt(time.Now().UnixNano(), 10))
+
+	for _, header := range form.File["files"] {
+		file, err := header.Open()
+		if err != nil {
+			return req, err
+		}
+		filePath, err := utils.SaveUploadedFile(file, header, uploadPath)
+		file.Close()
+		if err != nil {
+			return req, err
+		}
+		req.files = append(req.files, filePath)
+	}
+
+	return req, nil
+}
+
+// itemOnlyField trả về tên trường đầu vào chính (text, url, audio_url) nếu params có đặt;
+// các trường này là dữ liệu riêng của từng input nên không dùng làm giá trị mặc định
+func itemOnlyField(params domain.ToolInputRequest) string {
+	switch {
+	case params.Text != "":
+		return "text"
+	case params.URL != "":
+		return "url"
+	case params.AudioURL != "":
+		return "audio_url"
+	}
+	return ""
+}
+
+// withDefaults điền các trường còn trống của input bằng giá trị trong params.
+// Hai request được gộp theo JSON nên mọi tuỳ chọn của tool đều được áp dụng, kể cả tuỳ chọn thêm sau này.
+func withDefaults(input, params domain.ToolInputRequest) (domain.ToolInput, error) {
+	fields := map[string]json.RawMessage{}
+	for _, request := range []domain.ToolInputRequest{params, input} {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return domain.ToolInput{}, err
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return domain.ToolInput{}, err
+		}
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return domain.ToolInput{}, err
+	}
+	var merged domain.ToolInputRequest
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return domain.ToolInput{}, err
+	}
+	return merged.ToolInput(), nil
+}
+
+// GetBatch xử lý endpoint GET /batches/:id, trả về batch, tiến độ và các task con
+func (h *BatchHandler) GetBatch(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID"})
+		return
+	}
+
+	batch, err := h.service.GetBatch(id)
+	if err != nil {
+		log.Printf("GetBatch: Batch with ID %d not found. Error: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Batch not found"})
+		return
+	}
+
+	tasks, err := h.service.GetBatchTasks(id)
+	if err != nil {
+		log.Printf("GetBatch: Failed to retrieve tasks of batch %d. Error: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database query error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"batch": batch, "tasks": tasks})
+}
+
+// GetBatchResults xử lý endpoint GET /batches/:id/results?format=jsonl|zip
+func (h *BatchHandler) GetBatchResults(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", service.BatchResultsJSONL)
+	var contentType string
+	switch format {
+	case service.BatchResultsJSONL:
+		contentType = "application/x-ndjson"
+	case service.BatchResultsZip:
+		contentType = "application/zip"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected 'jsonl' or 'zip'"})
+		return
+	}
+
+	if _, err := h.service.GetBatch(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Batch not found"})
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=batch_%d_results.%s", id, format))
+	if err := h.service.ExportResults(id, format, c.Writer); err != nil {
+		log.Printf("GetBatchResults: Failed to export results of batch %d. Error: %v", id, err)
+	}
+}
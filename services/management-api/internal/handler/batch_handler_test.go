@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	"management-api/internal/domain"
+)
+
+func TestWithDefaultsMergesEveryOption(t *testing.T) {
+	params := domain.ToolInputRequest{
+		BackgroundColor: "#ffffff",
+		AlphaMatting:    true,
+		Crop:            true,
+		CropPadding:     8,
+		Format:          domain.ImageFormatWebP,
+		Threshold:       0.5,
+		Mode:            "pixelate",
+		Strength:        10,
+		Preprocess:      []string{"deskew"},
+	}
+	input, err := withDefaults(domain.ToolInputRequest{URL: "https://example.com/a.png", Strength: 3}, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := params.ToolInput()
+	want.URL = "https://example.com/a.png"
+	want.Strength = 3
+	if !reflect.DeepEqual(input, want) {
+		t.Fatalf("withDefaults = %+v, want %+v", input, want)
+	}
+}
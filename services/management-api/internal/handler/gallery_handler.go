@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"management-api/internal/config"
+	"management-api/internal/service"
+	"management-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GalleryHandler struct {
+	service service.GalleryService
+	uploads config.UploadConfig
+}
+
+func NewGalleryHandler(service service.GalleryService, cfg *config.Config) *GalleryHandler {
+	return &GalleryHandler{service: service, uploads: cfg.Uploads}
+}
+
+// galleryRequest là body của POST /face-galleries
+type galleryRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// galleryError chuyển lỗi của GalleryService thành HTTP status tương ứng.
+// Lỗi từ service face-recognition trả về 502 giống các route tool.
+func galleryError(c *gin.Context, err error) {
+	var backendErr *service.BackendError
+	switch {
+	case errors.Is(err, service.ErrGalleryNotFound), errors.Is(err, service.ErrEnrollmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidGallery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.As(err, &backendErr):
+		body := gin.H{"error": err.Error(), "service": backendErr.Service}
+		if backendErr.StatusCode != 0 {
+			body["backend_status"] = backendErr.StatusCode
+		}
+		c.JSON(http.StatusBadGateway, body)
+	default:
+		log.Printf("galleryError: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// CreateGallery xử lý endpoint POST /face-galleries
+func (h *GalleryHandler) CreateGallery(c *gin.Context) {
+	var req galleryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	gallery, err := h.service.CreateGallery(req.Name, req.Description)
+	if err != nil {
+		galleryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gallery)
+}
+
+// GetAllGalleries xử lý endpoint GET /face-galleries
+func (h *GalleryHandler) GetAllGalleries(c *gin.Context) {
+	galleries, err := h.service.GetAllGalleries()
+	if err != nil {
+		galleryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, galleries)
+}
+
+// GetGallery xử lý endpoint GET /face-galleries/:name
+func (h *GalleryHandler) GetGallery(c *gin.Context) {
+	gallery, err := h.service.GetGallery(c.Param("name"))
+	if err != nil {
+		galleryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gallery)
+}
+
+// DeleteGallery xử lý endpoint DELETE /face-galleries/:name, xoá cả các enrollment của gallery
+func (h *GalleryHandler) DeleteGallery(c *gin.Context) {
+	if err := h.service.DeleteGallery(c.Param("name")); err != nil {
+		galleryError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Enroll xử lý endpoint POST /face-galleries/:name/enrollments.
+// Nhận multipart với file "image" (đúng một khuôn mặt), "person_id" và "metadata" (JSON object, tuỳ chọn).
+func (h *GalleryHandler) Enroll(c *gin.Context) {
+	var metadata map[string]interface{}
+	if value := c.PostForm("metadata"); value != "" {
+		if err := json.Unmarshal([]byte(value), &metadata); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'metadata', expected a JSON object"})
+			return
+		}
+	}
+
+	imagePath, cleanup, ok := h.saveImage(c)
+	if !ok {
+		return
+	}
+	defer cleanup()
+
+	enrollment, err := h.service.Enroll(c.Request.Context(), c.Param("name"), c.PostForm("person_id"), metadata, imagePath)
+	if err != nil {
+		galleryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, enrollment)
+}
+
+// GetEnrollments xử lý endpoint GET /face-galleries/:name/enrollments, lọc theo ?person_id=
+func (h *GalleryHandler) GetEnrollments(c *gin.Context) {
+	enrollments, err := h.service.GetEnrollments(c.Param("name"), c.Query("person_id"))
+	if err != nil {
+		galleryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollments)
+}
+
+// DeleteEnrollment xử lý endpoint DELETE /face-galleries/:name/enrollments/:id
+func (h *GalleryHandler) DeleteEnrollment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrollment ID"})
+		return
+	}
+	if err := h.service.DeleteEnrollment(c.Param("name"), id); err != nil {
+		galleryError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeletePerson xử lý endpoint DELETE /face-galleries/:name/persons/:person_id, xoá mọi enrollment của người đó
+func (h *GalleryHandler) DeletePerson(c *gin.Context) {
+	deleted, err := h.service.DeletePerson(c.Param("name"), c.Param("person_id"))
+	if err != nil {
+		galleryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
+
+// Search xử lý endpoint POST /face-galleries/:name/search.
+// Nhận multipart với file "image", "top_k" và "min_similarity" (tuỳ chọn).
+func (h *GalleryHandler) Search(c *gin.Context) {
+	topK := 0
+	if value := c.PostForm("top_k"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'top_k'"})
+			return
+		}
+		topK = n
+	}
+	minSimilarity := -1.0
+	if value := c.PostForm("min_similarity"); value != "" {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'min_similarity'"})
+			return
+		}
+		minSimilarity = f
+	}
+
+	imagePath, cleanup, ok := h.saveImage(c)
+	if !ok {
+		return
+	}
+	defer cleanup()
+
+	result, err := h.service.Search(c.Request.Context(), c.Param("name"), imagePath, topK, minSimilarity)
+	if err != nil {
+		galleryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// saveImage lưu file "image" của request vào thư mục tạm; cleanup xoá ảnh sau khi xử lý vì
+// gallery chỉ lưu embedding. Trả về false nếu đã trả lỗi cho client.
+func (h *GalleryHandler) saveImage(c *gin.Context) (path string, cleanup func(), ok bool) {
+	file, header, err := c.Request.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
+		return "", nil, false
+	}
+	defer file.Close()
+
+	uploadPath := filepath.Join(h.uploads.ImagePath, "faces", strconv.FormatInt(time.Now().UnixNano(), 10))
+	cleanup = func() { os.RemoveAll(uploadPath) }
+	path, err = utils.SaveUploadedFile(file, header, uploadPath)
+	if err != nil {
+		cleanup()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save the file"})
+		return "", nil, false
+	}
+	return path, cleanup, true
+}
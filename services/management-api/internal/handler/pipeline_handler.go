@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/service"
+	"management-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PipelineHandler struct {
+	service service.PipelineService
+	uploads config.UploadConfig
+}
+
+func NewPipelineHandler(service service.PipelineService, cfg *config.Config) *PipelineHandler {
+	return &PipelineHandler{service: service, uploads: cfg.Uploads}
+}
+
+// createPipelineRequest là body của POST /pipelines; filePath là file tải lên đã được lưu trên server
+type createPipelineRequest struct {
+	Input    domain.ToolInputRequest `json:"input"`
+	Steps    []domain.PipelineStep   `json:"steps"`
+	filePath string
+}
+
+// CreatePipeline xử lý endpoint POST /pipelines.
+// Nhận JSON, hoặc multipart với trường "steps", "input" (JSON) và file "file"
+// (đường dẫn file được gán vào input.file_path).
+func (h *PipelineHandler) CreatePipeline(c *gin.Context) {
+	var req createPipelineRequest
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		req, err = h.bindMultipartPipeline(c)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		log.Printf("CreatePipeline: Invalid input. Error: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := h.service.ValidatePipeline(req.Steps, nil); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := req.Input.ToolInput()
+	input.FilePath = req.filePath
+	pipeline, err := h.service.CreatePipeline(&domain.Pipeline{Input: input, Steps: req.Steps})
+	if err != nil {
+		log.Printf("CreatePipeline: Failed to create pipeline. Error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, pipeline)
+}
+
+func (h *PipelineHandler) bindMultipartPipeline(c *gin.Context) (createPipelineRequest, error) {
+	var req createPipelineRequest
+	if err := json.Unmarshal([]byte(c.PostForm("steps")), &req.Steps); err != nil {
+		return req, err
+	}
+	if input := c.PostForm("input"); input != "" {
+		if err := json.Unmarshal([]byte(input), &req.Input); err != nil {
+			return req, err
+		}
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err == http.ErrMissingFile {
+		return req, nil
+	}
+	if err != nil {
+		return req, err
+	}
+	defer file.Close()
+
+	uploadPath := h.uploads.ImagePath
+	if len(req.Steps) > 0 && (req.Steps[0].Tool == domain.ToolVoiceToText || req.Steps[0].Tool == domain.ToolSpeechRecognition) {
+		uploadPath = h.uploads.AudioPath
+	}
+	uploadPath = filepath.Join(uploadPath, "pipelines", strconv.FormatInt(time.Now().UnixNano(), 10))
+
+	req.filePath, err = utils.SaveUploadedFile(file, header, uploadPath)
+	return req, err
+}
+
+// GetPipeline xử lý endpoint GET /pipelines/:id, trả về pipeline và task của từng bước
+func (h *PipelineHandler) GetPipeline(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pipeline ID"})
+		return
+	}
+
+	pipeline, err := h.service.GetPipeline(id)
+	if err != nil {
+		log.Printf("GetPipeline: Pipeline with ID %d not found. Error: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pipeline not found"})
+		return
+	}
+
+	tasks, err := h.service.GetPipelineTasks(id)
+	if err != nil {
+		log.Printf("GetPipeline: Failed to retrieve tasks of pipeline %d. Error: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database query error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pipeline": pipeline, "tasks": tasks})
+}
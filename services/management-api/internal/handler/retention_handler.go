@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"management-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionHandler struct {
+	service service.RetentionService
+}
+
+func NewRetentionHandler(service service.RetentionService) *RetentionHandler {
+	return &RetentionHandler{service: service}
+}
+
+// GetReport xử lý endpoint GET /admin/retention/report: liệt kê file và task sẽ bị dọn dẹp, không xoá gì
+func (h *RetentionHandler) GetReport(c *gin.Context) {
+	report, err := h.service.Sweep(true)
+	if err != nil {
+		log.Printf("GetReport: Failed to build retention report. Error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -1,10 +1,17 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
+	"management-api/internal/config"
+	"management-api/internal/domain"
 	"management-api/internal/service"
 	"management-api/pkg/utils"
 
@@ -13,10 +20,17 @@ import (
 
 type TaskHandler struct {
 	service service.TaskService
+	uploads config.UploadConfig
 }
 
-func NewTaskHandler(service service.TaskService) *TaskHandler {
-	return &TaskHandler{service: service}
+func NewTaskHandler(service service.TaskService, cfg *config.Config) *TaskHandler {
+	return &TaskHandler{service: service, uploads: cfg.Uploads}
+}
+
+// requestDir trả về thư mục riêng của một request trong root, để file tải lên của các request
+// (thường cùng tên như image.jpg) không ghi đè lên nhau
+func requestDir(root, tool string) string {
+	return filepath.Join(root, tool, strconv.FormatInt(time.Now().UnixNano(), 10))
 }
 
 // GetTaskStatus lấy trạng thái của một task
@@ -57,31 +71,151 @@ func (h *TaskHandler) GetAllTasks(c *gin.Context) {
 	c.JSON(http.StatusOK, tasks)
 }
 
-// HandleTextToVoice xử lý endpoint /tts
-func (h *TaskHandler) HandleTextToVoice(c *gin.Context) {
+// GetTaskDeliveries lấy lịch sử gửi webhook của một task
+func (h *TaskHandler) GetTaskDeliveries(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	deliveries, err := h.service.GetTaskDeliveries(id)
+	if err != nil {
+		log.Printf("GetTaskDeliveries: Failed to retrieve deliveries of task %d. Error: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// CancelTask xử lý endpoint POST /tasks/:id/cancel
+func (h *TaskHandler) CancelTask(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	task, err := h.service.CancelTask(id)
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case errors.Is(err, domain.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		log.Printf("CancelTask: Failed to cancel task %d. Error: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+// RetryTask xử lý endpoint POST /tasks/:id/retry.
+// Body (không bắt buộc): {"overrides": {"language": "fr"}, "callback_url": "..."}
+func (h *TaskHandler) RetryTask(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
 	var req struct {
-		Text     string `json:"text" binding:"required"`
-		Language string `json:"language"`
+		Overrides   map[string]interface{} `json:"overrides"`
+		CallbackURL string                 `json:"callback_url"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+	}
+	if !validCallbackURL(req.CallbackURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'callback_url'"})
+		return
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	task, err := h.service.RetryTask(id, req.Overrides, req.CallbackURL)
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case errors.Is(err, service.ErrTaskNotRetryable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidOverrides):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		log.Printf("RetryTask: Failed to retry task %d. Error: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Header("X-Task-ID", strconv.Itoa(task.ID))
+		c.JSON(http.StatusAccepted, task)
+	}
+}
+
+// validCallbackURL kiểm tra callback_url (không bắt buộc) là URL http/https không trỏ tới địa chỉ nội bộ.
+// Tên miền phân giải ra địa chỉ nội bộ bị chặn khi gửi webhook.
+func validCallbackURL(callbackURL string) bool {
+	return callbackURL == "" || utils.CheckPublicURL(callbackURL) == nil
+}
+
+// runTool chạy tool qua service và trả kết quả về client theo dạng của route cũ
+// (output của tool, hoặc {"error": ...}); route /v1 trả về cả task.
+// ID của task được trả về trong header X-Task-ID để đối chiếu với webhook.
+func (h *TaskHandler) runTool(c *gin.Context, tool string, input domain.ToolInput, callbackURL string) {
+	if !validCallbackURL(callbackURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'callback_url'"})
 		return
 	}
 
-	resp, err := h.service.HandleTextToVoice(req.Text, req.Language)
+	task, err := h.service.SubmitTool(tool, input, callbackURL, true)
+	if errors.Is(err, service.ErrInvalidToolInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	c.Header("X-Task-ID", strconv.Itoa(task.ID))
+	resource := domain.NewTaskResource(task)
+	switch task.Status {
+	case domain.TaskStatusCompleted:
+		c.JSON(http.StatusOK, resource.Output)
+	case domain.TaskStatusCancelled:
+		c.JSON(http.StatusConflict, gin.H{"error": service.ErrTaskCancelled.Error()})
+	default:
+		c.JSON(failedTaskResponse(resource))
+	}
 }
 
-// HandleVoiceToText xử lý endpoint /vts
-func (h *TaskHandler) HandleVoiceToText(c *gin.Context) {
+// failedTaskResponse trả về status và body của route cũ cho task failed.
+// Lỗi từ service AI phía sau trả về 502 kèm tên service và status HTTP của service;
+// face-verify không có khuôn mặt để so khớp trả về 422.
+func failedTaskResponse(resource domain.TaskResource) (int, gin.H) {
+	if resource.Error == nil {
+		return http.StatusInternalServerError, gin.H{"error": "task did not complete"}
+	}
+	if resource.Error.Reason == domain.FailureReasonNoFace {
+		return http.StatusUnprocessableEntity, gin.H{"error": resource.Error.Message}
+	}
+	if resource.Error.Reason != domain.FailureReasonBackendError {
+		return http.StatusInternalServerError, gin.H{"error": resource.Error.Message}
+	}
+
+	body := gin.H{"error": resource.Error.Message, "service": resource.Error.Service}
+	if resource.Error.BackendStatus != 0 {
+		body["backend_status"] = resource.Error.BackendStatus
+	}
+	return http.StatusBadGateway, body
+}
+
+// HandleTextToVoice xử lý endpoint /tts
+func (h *TaskHandler) HandleTextToVoice(c *gin.Context) {
 	var req struct {
-		AudioURL string `json:"audio_url" binding:"required"`
+		Text        string `json:"text" binding:"required"`
+		Language    string `json:"language"`
+		CallbackURL string `json:"callback_url"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -89,16 +223,26 @@ func (h *TaskHandler) HandleVoiceToText(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.service.HandleVoiceToText(req.AudioURL)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	h.runTool(c, domain.ToolTextToVoice, domain.ToolInput{Text: req.Text, Language: req.Language}, req.CallbackURL)
+}
+
+// HandleVoiceToText xử lý endpoint /vts
+func (h *TaskHandler) HandleVoiceToText(c *gin.Context) {
+	var req struct {
+		AudioURL    string `json:"audio_url" binding:"required"`
+		CallbackURL string `json:"callback_url"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	h.runTool(c, domain.ToolVoiceToText, domain.ToolInput{AudioURL: req.AudioURL}, req.CallbackURL)
 }
 
-// HandleBackgroundRemoval xử lý endpoint /remove-bg
+// HandleBackgroundRemoval xử lý endpoint /remove-bg. Các trường form không bắt buộc, xem backgroundForm;
+// file "background_image" là ảnh nền thay thế.
 func (h *TaskHandler) HandleBackgroundRemoval(c *gin.Context) {
 	log.Println("HandleBackgroundRemoval: Received request to remove background")
 
@@ -109,10 +253,16 @@ func (h *TaskHandler) HandleBackgroundRemoval(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
 		return
 	}
+	defer file.Close()
 	log.Printf("HandleBackgroundRemoval: Received file '%s' with size %d bytes", header.Filename, header.Size)
+	input, err := backgroundForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Lưu file tạm thời
-	uploadPath := "./uploads/images/"
+	uploadPath := requestDir(h.uploads.ImagePath, domain.ToolBackgroundRemoval)
 	filePath, err := utils.SaveUploadedFile(file, header, uploadPath)
 	if err != nil {
 		log.Printf("HandleBackgroundRemoval: Failed to save file '%s'. Error: %v", header.Filename, err)
@@ -120,25 +270,50 @@ func (h *TaskHandler) HandleBackgroundRemoval(c *gin.Context) {
 		return
 	}
 	log.Printf("HandleBackgroundRemoval: File saved to temporary path '%s'", filePath)
-
-	// Gọi service xử lý background removal
-	processedImagePath, err := h.service.HandleBackgroundRemoval(filePath)
-	if err != nil {
-		log.Printf("HandleBackgroundRemoval: Failed to process background removal for file '%s'. Error: %v", filePath, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	input.FilePath = filePath
+
+	// Ảnh nền được lưu vào thư mục con để không trùng tên với ảnh chính
+	if background, backgroundHeader, err := c.Request.FormFile("background_image"); err == nil {
+		defer background.Close()
+		input.BackgroundFilePath, err = utils.SaveUploadedFile(background, backgroundHeader, filepath.Join(uploadPath, "background"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save the file"})
+			return
+		}
 	}
 
-	log.Printf("HandleBackgroundRemoval: Successfully processed background removal for file '%s'", filePath)
+	// Gọi service xử lý background removal, trả về đường dẫn file đã xử lý
+	h.runTool(c, domain.ToolBackgroundRemoval, input, c.PostForm("callback_url"))
+}
 
-	// Trả về đường dẫn file đã xử lý
-	c.JSON(http.StatusOK, gin.H{"processed_image_path": processedImagePath})
+// backgroundForm đọc các tuỳ chọn của /remove-bg: background_color, alpha_matting, crop, crop_padding,
+// mask_only và format. Tổ hợp tuỳ chọn không hợp lệ được tool kiểm tra khi tạo task.
+func backgroundForm(c *gin.Context) (domain.ToolInput, error) {
+	input := domain.ToolInput{BackgroundColor: c.PostForm("background_color"), Format: c.PostForm("format")}
+	for name, field := range map[string]*bool{"alpha_matting": &input.AlphaMatting, "crop": &input.Crop, "mask_only": &input.MaskOnly} {
+		if value := c.PostForm(name); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return input, fmt.Errorf("Invalid '%s'", name)
+			}
+			*field = b
+		}
+	}
+	if value := c.PostForm("crop_padding"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return input, errors.New("Invalid 'crop_padding'")
+		}
+		input.CropPadding = n
+	}
+	return input, nil
 }
 
 // HandleSpeechRecognition xử lý endpoint /speech-recognition
 func (h *TaskHandler) HandleSpeechRecognition(c *gin.Context) {
 	var req struct {
-		AudioURL string `json:"audio_url" binding:"required"`
+		AudioURL    string `json:"audio_url" binding:"required"`
+		CallbackURL string `json:"callback_url"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -146,70 +321,186 @@ func (h *TaskHandler) HandleSpeechRecognition(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.service.HandleSpeechRecognition(req.AudioURL)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, resp)
+	h.runTool(c, domain.ToolSpeechRecognition, domain.ToolInput{AudioURL: req.AudioURL}, req.CallbackURL)
 }
 
-// HandleFaceRecognition xử lý endpoint /face-recognition
+// HandleFaceRecognition xử lý endpoint /face-recognition. Trường form preprocess (auto_orient, downscale)
+// và max_dimension không bắt buộc, xem preprocessForm.
 func (h *TaskHandler) HandleFaceRecognition(c *gin.Context) {
 	file, header, err := c.Request.FormFile("image")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
 		return
 	}
+	preprocess, maxDimension, err := preprocessForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Lưu file tạm thời
-	uploadPath := "./uploads/images/"
+	uploadPath := requestDir(h.uploads.ImagePath, domain.ToolFaceRecognition)
 	filePath, err := utils.SaveUploadedFile(file, header, uploadPath)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save the file"})
 		return
 	}
 
-	resp, err := h.service.HandleFaceRecognition(filePath)
+	input := domain.ToolInput{FilePath: filePath, Preprocess: preprocess, MaxDimension: maxDimension}
+	h.runTool(c, domain.ToolFaceRecognition, input, c.PostForm("callback_url"))
+}
+
+// HandleFaceVerify xử lý endpoint /face-verify: so khớp 1:1 khuôn mặt trong "image" (ví dụ ảnh selfie)
+// với khuôn mặt trong "reference_image" (ví dụ ảnh CCCD), hoặc với người person_id đã đăng ký trong gallery.
+// Trường form threshold (-1 đến 1) không bắt buộc.
+func (h *TaskHandler) HandleFaceVerify(c *gin.Context) {
+	file, header, err := c.Request.FormFile("image")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
+		return
+	}
+	defer file.Close()
+	threshold := 0.0
+	if value := c.PostForm("threshold"); value != "" {
+		if threshold, err = strconv.ParseFloat(value, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'threshold'"})
+			return
+		}
+	}
+
+	// Hai ảnh thường cùng tên (image.jpg) nên được lưu vào hai thư mục riêng của request
+	uploadPath := requestDir(h.uploads.ImagePath, domain.ToolFaceVerify)
+	filePath, err := utils.SaveUploadedFile(file, header, filepath.Join(uploadPath, "image"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save the file"})
 		return
 	}
+	input := domain.ToolInput{
+		FilePath:  filePath,
+		Gallery:   c.PostForm("gallery"),
+		PersonID:  c.PostForm("person_id"),
+		Threshold: threshold,
+	}
 
-	c.JSON(http.StatusOK, resp)
+	if reference, referenceHeader, err := c.Request.FormFile("reference_image"); err == nil {
+		defer reference.Close()
+		input.ReferenceFilePath, err = utils.SaveUploadedFile(reference, referenceHeader, filepath.Join(uploadPath, "reference"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save the file"})
+			return
+		}
+	}
+	h.runTool(c, domain.ToolFaceVerify, input, c.PostForm("callback_url"))
 }
 
-// HandleOCR xử lý endpoint /ocr
-func (h *TaskHandler) HandleOCR(c *gin.Context) {
+// HandleFaceAnonymize xử lý endpoint /face-anonymize: che các khuôn mặt trong "image" để công bố ảnh.
+// Trường form mode (blur, pixelate, box; mặc định blur), strength (1-100, mặc định 50), preprocess
+// (auto_orient, downscale) và max_dimension không bắt buộc, xem preprocessForm.
+func (h *TaskHandler) HandleFaceAnonymize(c *gin.Context) {
 	file, header, err := c.Request.FormFile("image")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
 		return
 	}
+	strength := 0
+	if value := c.PostForm("strength"); value != "" {
+		if strength, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'strength'"})
+			return
+		}
+	}
+	preprocess, maxDimension, err := preprocessForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Lưu file tạm thời
-	uploadPath := "./uploads/images/"
+	uploadPath := requestDir(h.uploads.ImagePath, domain.ToolFaceAnonymize)
 	filePath, err := utils.SaveUploadedFile(file, header, uploadPath)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save the file"})
 		return
 	}
 
-	resp, err := h.service.HandleOCR(filePath)
+	input := domain.ToolInput{
+		FilePath:     filePath,
+		Mode:         c.PostForm("mode"),
+		Strength:     strength,
+		Preprocess:   preprocess,
+		MaxDimension: maxDimension,
+	}
+	h.runTool(c, domain.ToolFaceAnonymize, input, c.PostForm("callback_url"))
+}
+
+// preprocessForm đọc trường form preprocess (các bước tiền xử lý ảnh, cách nhau bởi dấu phẩy,
+// ví dụ "auto_orient,deskew") và max_dimension. Bước không hợp lệ được tool kiểm tra khi tạo task.
+func preprocessForm(c *gin.Context) ([]string, int, error) {
+	var steps []string
+	for _, step := range strings.Split(c.PostForm("preprocess"), ",") {
+		if step = strings.TrimSpace(step); step != "" {
+			steps = append(steps, step)
+		}
+	}
+
+	maxDimension := 0
+	if value := c.PostForm("max_dimension"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, 0, errors.New("Invalid 'max_dimension'")
+		}
+		maxDimension = n
+	}
+	return steps, maxDimension, nil
+}
+
+// HandleOCR xử lý endpoint /ocr. image là ảnh, hoặc tài liệu PDF/TIFF nhiều trang được OCR từng trang.
+// Trường form languages (ví dụ "vie+eng"), output (text, hocr, json, tsv), searchable_pdf,
+// preprocess và max_dimension (xem preprocessForm) không bắt buộc.
+func (h *TaskHandler) HandleOCR(c *gin.Context) {
+	file, header, err := c.Request.FormFile("image")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
+		return
+	}
+	searchablePDF := false
+	if value := c.PostForm("searchable_pdf"); value != "" {
+		if searchablePDF, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'searchable_pdf'"})
+			return
+		}
+	}
+	preprocess, maxDimension, err := preprocessForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Lưu file tạm thời
+	uploadPath := requestDir(h.uploads.ImagePath, domain.ToolOCR)
+	filePath, err := utils.SaveUploadedFile(file, header, uploadPath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save the file"})
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	input := domain.ToolInput{
+		FilePath:      filePath,
+		Languages:     c.PostForm("languages"),
+		Output:        c.PostForm("output"),
+		SearchablePDF: searchablePDF,
+		Preprocess:    preprocess,
+		MaxDimension:  maxDimension,
+	}
+	h.runTool(c, domain.ToolOCR, input, c.PostForm("callback_url"))
 }
 
 // HandleTranslation xử lý endpoint /translate
 func (h *TaskHandler) HandleTranslation(c *gin.Context) {
 	var req struct {
-		Text     string `json:"text" binding:"required"`
-		DestLang string `json:"dest_lang" binding:"required"`
+		Text        string `json:"text" binding:"required"`
+		DestLang    string `json:"dest_lang" binding:"required"`
+		CallbackURL string `json:"callback_url"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -217,13 +508,7 @@ func (h *TaskHandler) HandleTranslation(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.service.HandleTranslation(req.Text, req.DestLang)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, resp)
+	h.runTool(c, domain.ToolTranslation, domain.ToolInput{Text: req.Text, DestLang: req.DestLang}, req.CallbackURL)
 }
 
 // UploadAudio xử lý endpoint /upload-audio
@@ -234,8 +519,8 @@ func (h *TaskHandler) UploadAudio(c *gin.Context) {
 		return
 	}
 
-	// Lưu file vào thư mục /uploads/audio (tạo thư mục này nếu chưa có)
-	uploadPath := "./uploads/audio/"
+	// Lưu file vào thư mục audio upload (tạo thư mục này nếu chưa có)
+	uploadPath := requestDir(h.uploads.AudioPath, "upload-audio")
 	filePath, err := utils.SaveUploadedFile(file, header, uploadPath)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save the file"})
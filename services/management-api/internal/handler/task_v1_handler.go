@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/service"
+	"management-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TaskV1Handler xử lý API /v1: mọi tool được gọi qua POST /v1/tools/:tool/tasks
+// và mọi endpoint trả về task dạng domain.TaskResource
+type TaskV1Handler struct {
+	service service.TaskService
+	uploads config.UploadConfig
+}
+
+func NewTaskV1Handler(service service.TaskService, cfg *config.Config) *TaskV1Handler {
+	return &TaskV1Handler{service: service, uploads: cfg.Uploads}
+}
+
+// createTaskRequest là body JSON của POST /v1/tools/:tool/tasks.
+// Các đường dẫn file chỉ được gán từ file tải lên mà server đã lưu.
+type createTaskRequest struct {
+	Input              domain.ToolInputRequest `json:"input"`
+	CallbackURL        string                  `json:"callback_url"`
+	filePath           string
+	referenceFilePath  string
+	backgroundFilePath string
+}
+
+// CreateTask xử lý endpoint POST /v1/tools/:tool/tasks.
+// Nhận JSON {"input": {...}, "callback_url": "..."}, hoặc multipart với trường input (JSON),
+// callback_url và file "file". Mặc định chạy tool ngay và trả về 201 với task đã kết thúc;
+// ?wait=false trả về 202 với task queued, tool chạy ở background.
+func (h *TaskV1Handler) CreateTask(c *gin.Context) {
+	tool := c.Param("tool")
+	if !domain.IsValidTool(tool) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Unknown tool '%s'", tool)})
+		return
+	}
+
+	wait := true
+	if v := c.Query("wait"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'wait'"})
+			return
+		}
+		wait = parsed
+	}
+
+	var req createTaskRequest
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		req, err = h.bindMultipartTask(c, tool)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		log.Printf("CreateTask: Invalid input. Error: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if !validCallbackURL(req.CallbackURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'callback_url'"})
+		return
+	}
+
+	input := req.Input.ToolInput()
+	input.FilePath = req.filePath
+	input.ReferenceFilePath = req.referenceFilePath
+	input.BackgroundFilePath = req.backgroundFilePath
+	task, err := h.service.SubmitTool(tool, input, req.CallbackURL, wait)
+	if errors.Is(err, service.ErrInvalidToolInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("CreateTask: Failed to create '%s' task. Error: %v", tool, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/v1/tasks/%d", task.ID))
+	status := http.StatusCreated
+	if !wait {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, domain.NewTaskResource(task))
+}
+
+// bindMultipartTask đọc request multipart và lưu file tải lên thành input của task.
+// File "reference_file" là ảnh tham chiếu của face-verify, "background_file" là ảnh nền thay thế của remove-bg.
+func (h *TaskV1Handler) bindMultipartTask(c *gin.Context, tool string) (createTaskRequest, error) {
+	req := createTaskRequest{CallbackURL: c.PostForm("callback_url")}
+	if input := c.PostForm("input"); input != "" {
+		if err := json.Unmarshal([]byte(input), &req.Input); err != nil {
+			return req, err
+		}
+	}
+
+	uploadPath := h.uploads.ImagePath
+	if tool == domain.ToolVoiceToText || tool == domain.ToolSpeechRecognition {
+		uploadPath = h.uploads.AudioPath
+	}
+	uploadPath = filepath.Join(uploadPath, "tasks", strconv.FormatInt(time.Now().UnixNano(), 10))
+
+	var err error
+	if req.filePath, err = saveFormFile(c, "file", uploadPath); err != nil {
+		return req, err
+	}
+	if req.referenceFilePath, err = saveFormFile(c, "reference_file", filepath.Join(uploadPath, "reference")); err != nil {
+		return req, err
+	}
+	req.backgroundFilePath, err = saveFormFile(c, "background_file", filepath.Join(uploadPath, "background"))
+	return req, err
+}
+
+// saveFormFile lưu file field của request multipart vào uploadPath; trả về "" nếu request không có file đó
+func saveFormFile(c *gin.Context, field, uploadPath string) (string, error) {
+	file, header, err := c.Request.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return utils.SaveUploadedFile(file, header, uploadPath)
+}
+
+// taskID đọc ID task từ path, trả về false (và đã gửi 400) nếu ID không hợp lệ
+func taskID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// ListTasks xử lý endpoint GET /v1/tasks
+func (h *TaskV1Handler) ListTasks(c *gin.Context) {
+	tasks, err := h.service.GetAllTasks()
+	if err != nil {
+		log.Printf("ListTasks: Failed to retrieve tasks. Error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database query error"})
+		return
+	}
+
+	resources := make([]domain.TaskResource, 0, len(tasks))
+	for i := range tasks {
+		resources = append(resources, domain.NewTaskResource(&tasks[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"tasks": resources})
+}
+
+// GetTask xử lý endpoint GET /v1/tasks/:id
+func (h *TaskV1Handler) GetTask(c *gin.Context) {
+	id, ok := taskID(c)
+	if !ok {
+		return
+	}
+
+	task, err := h.service.GetTaskStatus(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	c.JSON(http.StatusOK, domain.NewTaskResource(task))
+}
+
+// CancelTask xử lý endpoint POST /v1/tasks/:id/cancel
+func (h *TaskV1Handler) CancelTask(c *gin.Context) {
+	id, ok := taskID(c)
+	if !ok {
+		return
+	}
+
+	task, err := h.service.CancelTask(id)
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case errors.Is(err, domain.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		log.Printf("CancelTask: Failed to cancel task %d. Error: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, domain.NewTaskResource(task))
+	}
+}
+
+// RetryTask xử lý endpoint POST /v1/tasks/:id/retry.
+// Body (không bắt buộc): {"overrides": {"language": "fr"}, "callback_url": "..."}
+func (h *TaskV1Handler) RetryTask(c *gin.Context) {
+	id, ok := taskID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Overrides   map[string]interface{} `json:"overrides"`
+		CallbackURL string                 `json:"callback_url"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+	}
+	if !validCallbackURL(req.CallbackURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'callback_url'"})
+		return
+	}
+
+	task, err := h.service.RetryTask(id, req.Overrides, req.CallbackURL)
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case errors.Is(err, service.ErrTaskNotRetryable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidOverrides):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		log.Printf("RetryTask: Failed to retry task %d. Error: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Header("Location", fmt.Sprintf("/v1/tasks/%d", task.ID))
+		c.JSON(http.StatusAccepted, domain.NewTaskResource(task))
+	}
+}
+
+// GetTaskDeliveries xử lý endpoint GET /v1/tasks/:id/deliveries
+func (h *TaskV1Handler) GetTaskDeliveries(c *gin.Context) {
+	id, ok := taskID(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.service.GetTaskDeliveries(id)
+	if err != nil {
+		log.Printf("GetTaskDeliveries: Failed to retrieve deliveries of task %d. Error: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/service"
+	"management-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TemplateHandler struct {
+	service service.TemplateService
+	uploads config.UploadConfig
+}
+
+func NewTemplateHandler(service service.TemplateService, cfg *config.Config) *TemplateHandler {
+	return &TemplateHandler{service: service, uploads: cfg.Uploads}
+}
+
+// templateRequest là body của POST /pipeline-templates và PUT /pipeline-templates/:name
+type templateRequest struct {
+	Name        string                     `json:"name"`
+	Description *string                    `json:"description"`
+	Steps       []domain.PipelineStep      `json:"steps"`
+	Parameters  []domain.TemplateParameter `json:"parameters"`
+}
+
+// runTemplateRequest là body của POST /pipeline-templates/:name/run.
+// Version bằng 0 (hoặc bỏ trống) là chạy phiên bản mới nhất. filePath là file tải lên đã được lưu trên server.
+type runTemplateRequest struct {
+	Version  int                     `json:"version"`
+	Input    domain.ToolInputRequest `json:"input"`
+	Params   map[string]interface{}  `json:"params"`
+	filePath string
+}
+
+// templateError chuyển lỗi của TemplateService thành HTTP status tương ứng
+func templateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTemplateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTemplate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("templateError: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// CreateTemplate xử lý endpoint POST /pipeline-templates
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req templateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	description := ""
+	if req.Description != nil {
+		description = *req.Description
+	}
+
+	template, err := h.service.CreateTemplate(req.Name, description, req.Steps, req.Parameters)
+	if err != nil {
+		templateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateTemplate xử lý endpoint PUT /pipeline-templates/:name, tạo phiên bản mới của template
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	var req templateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	version, err := h.service.CreateTemplateVersion(c.Param("name"), req.Description, req.Steps, req.Parameters)
+	if err != nil {
+		templateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, version)
+}
+
+// GetAllTemplates xử lý endpoint GET /pipeline-templates
+func (h *TemplateHandler) GetAllTemplates(c *gin.Context) {
+	templates, err := h.service.GetAllTemplates()
+	if err != nil {
+		templateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate xử lý endpoint GET /pipeline-templates/:name, trả về template và phiên bản mới nhất
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	template, err := h.service.GetTemplate(c.Param("name"))
+	if err != nil {
+		templateError(c, err)
+		return
+	}
+
+	latest, err := h.service.GetTemplateVersion(template.Name, 0)
+	if err != nil {
+		templateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template, "latest": latest})
+}
+
+// GetTemplateVersions xử lý endpoint GET /pipeline-templates/:name/versions
+func (h *TemplateHandler) GetTemplateVersions(c *gin.Context) {
+	versions, err := h.service.GetTemplateVersions(c.Param("name"))
+	if err != nil {
+		templateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetTemplateVersion xử lý endpoint GET /pipeline-templates/:name/versions/:version
+func (h *TemplateHandler) GetTemplateVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+
+	v, err := h.service.GetTemplateVersion(c.Param("name"), version)
+	if err != nil {
+		templateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+// DeleteTemplate xử lý endpoint DELETE /pipeline-templates/:name
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	if err := h.service.DeleteTemplate(c.Param("name")); err != nil {
+		templateError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RunTemplate xử lý endpoint POST /pipeline-templates/:name/run.
+// Nhận JSON, hoặc multipart với trường "version", "input", "params" (JSON) và file "file".
+func (h *TemplateHandler) RunTemplate(c *gin.Context) {
+	var req runTemplateRequest
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		req, err = h.bindMultipartRun(c)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		log.Printf("RunTemplate: Invalid input. Error: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	input := req.Input.ToolInput()
+	input.FilePath = req.filePath
+	pipeline, err := h.service.RunTemplate(c.Param("name"), req.Version, input, req.Params)
+	if err != nil {
+		templateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, pipeline)
+}
+
+func (h *TemplateHandler) bindMultipartRun(c *gin.Context) (runTemplateRequest, error) {
+	var req runTemplateRequest
+	if version := c.PostForm("version"); version != "" {
+		v, err := strconv.Atoi(version)
+		if err != nil {
+			return req, err
+		}
+		req.Version = v
+	}
+	if input := c.PostForm("input"); input != "" {
+		if err := json.Unmarshal([]byte(input), &req.Input); err != nil {
+			return req, err
+		}
+	}
+	if params := c.PostForm("params"); params != "" {
+		if err := json.Unmarshal([]byte(params), &req.Params); err != nil {
+			return req, err
+		}
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err == http.ErrMissingFile {
+		return req, nil
+	}
+	if err != nil {
+		return req, err
+	}
+	defer file.Close()
+
+	uploadPath := filepath.Join(h.uploads.ImagePath, "pipelines", strconv.FormatInt(time.Now().UnixNano(), 10))
+	req.filePath, err = utils.SaveUploadedFile(file, header, uploadPath)
+	return req, err
+}
@@ -0,0 +1,137 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"management-api/internal/domain"
+)
+
+// facePadding là phần mở rộng mỗi phía của khung khuôn mặt (theo tỉ lệ kích thước khung) khi che,
+// vì khung của face_recognition chỉ ôm sát mắt, mũi, miệng
+const facePadding = 0.2
+
+// Anonymize che các khuôn mặt faces trong ảnh path theo mode (blur, pixelate, box) với mức strength
+// (1-100, càng lớn càng khó nhận ra) rồi ghi ảnh ra out: JPEG nếu out có đuôi .jpg hoặc .jpeg,
+// ngược lại là PNG. Độ mờ và cỡ ô pixel tỉ lệ với kích thước khuôn mặt.
+func Anonymize(path, out string, faces []domain.FaceBox, mode string, strength int) error {
+	src, err := decodeFile(path)
+	if err != nil {
+		return err
+	}
+
+	img := flatten(src)
+	for _, face := range faces {
+		padX := int(float64(face.Right-face.Left) * facePadding)
+		padY := int(float64(face.Bottom-face.Top) * facePadding)
+		rect := image.Rect(face.Left-padX, face.Top-padY, face.Right+padX, face.Bottom+padY).Intersect(img.Bounds())
+		if rect.Empty() {
+			continue
+		}
+		faceSize := min(rect.Dx(), rect.Dy())
+		switch mode {
+		case domain.AnonymizePixelate:
+			pixelate(img, rect, max(2, faceSize*strength/400))
+		case domain.AnonymizeBox:
+			draw.Draw(img, rect, image.NewUniform(color.Black), image.Point{}, draw.Src)
+		default:
+			boxBlur(img, rect, max(1, faceSize*strength/200))
+		}
+	}
+	return writeImage(out, img)
+}
+
+// writeImage ghi img ra out theo đuôi file: JPEG (chất lượng 90) với .jpg và .jpeg, PNG với đuôi khác
+func writeImage(out string, img image.Image) error {
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(out)) {
+	case ".jpg", ".jpeg":
+		return jpeg.Encode(f, img, &jpeg.Options{Quality: 90})
+	default:
+		return png.Encode(f, img)
+	}
+}
+
+// pixelate chia vùng rect thành các ô block x block và tô mỗi ô bằng màu trung bình của ô
+func pixelate(img *image.RGBA, rect image.Rectangle, block int) {
+	for y0 := rect.Min.Y; y0 < rect.Max.Y; y0 += block {
+		for x0 := rect.Min.X; x0 < rect.Max.X; x0 += block {
+			cell := image.Rect(x0, y0, x0+block, y0+block).Intersect(rect)
+			var sum [3]int
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					i := img.PixOffset(x, y)
+					sum[0] += int(img.Pix[i])
+					sum[1] += int(img.Pix[i+1])
+					sum[2] += int(img.Pix[i+2])
+				}
+			}
+			n := cell.Dx() * cell.Dy()
+			fill := color.RGBA{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n), 255}
+			draw.Draw(img, cell, image.NewUniform(fill), image.Point{}, draw.Src)
+		}
+	}
+}
+
+// boxBlur làm mờ vùng rect bằng ba lượt box blur ngang và dọc bán kính radius (xấp xỉ Gaussian blur);
+// chỉ dùng pixel bên trong rect nên phần ảnh bên ngoài không bị ảnh hưởng
+func boxBlur(img *image.RGBA, rect image.Rectangle, radius int) {
+	w, h := rect.Dx(), rect.Dy()
+	buf := make([]int32, w*h*3)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := img.PixOffset(rect.Min.X+x, rect.Min.Y+y)
+			for c := 0; c < 3; c++ {
+				buf[(y*w+x)*3+c] = int32(img.Pix[i+c])
+			}
+		}
+	}
+
+	tmp := make([]int32, len(buf))
+	for pass := 0; pass < 3; pass++ {
+		blurLines(buf, tmp, w, h, 3, 3*w, radius)
+		blurLines(tmp, buf, h, w, 3*w, 3, radius)
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := img.PixOffset(rect.Min.X+x, rect.Min.Y+y)
+			for c := 0; c < 3; c++ {
+				img.Pix[i+c] = uint8(buf[(y*w+x)*3+c])
+			}
+		}
+	}
+}
+
+// blurLines tính trung bình trượt bán kính radius trên từng đường (hàng hoặc cột) của src và ghi vào dst.
+// n là số pixel mỗi đường, step là khoảng cách giữa hai pixel liên tiếp của đường, lineStep là khoảng cách
+// giữa hai đường; pixel ngoài biên được lấy bằng pixel ở biên.
+func blurLines(src, dst []int32, n, lines, step, lineStep, radius int) {
+	window := int32(2*radius + 1)
+	for l := 0; l < lines; l++ {
+		base := l * lineStep
+		for c := 0; c < 3; c++ {
+			at := func(i int) int32 {
+				return src[base+min(max(i, 0), n-1)*step+c]
+			}
+			var sum int32
+			for i := -radius; i <= radius; i++ {
+				sum += at(i)
+			}
+			for i := 0; i < n; i++ {
+				dst[base+i*step+c] = sum / window
+				sum += at(i+radius+1) - at(i-radius)
+			}
+		}
+	}
+}
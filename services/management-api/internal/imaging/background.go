@@ -0,0 +1,142 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"os/exec"
+	"strings"
+
+	"management-api/internal/domain"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// subjectAlpha là alpha tối thiểu để một pixel được tính là thuộc chủ thể khi tìm khung chủ thể,
+// bỏ qua viền mờ gần như trong suốt do alpha matting để lại
+const subjectAlpha = 16
+
+// BackgroundOptions là các bước xử lý ảnh đã xoá nền của remove-bg
+type BackgroundOptions struct {
+	// Color là màu nền thay thế; nil và ImagePath rỗng thì giữ nền trong suốt
+	Color *color.NRGBA
+	// ImagePath là ảnh nền thay thế, được co giãn và cắt giữa để phủ kín ảnh kết quả
+	ImagePath string
+	// Crop cắt ảnh theo khung chủ thể, chừa Padding pixel mỗi phía (phần vượt ra ngoài ảnh là nền trong suốt)
+	Crop     bool
+	Padding  int
+	MaskOnly bool
+}
+
+// Background xử lý ảnh đã xoá nền path (có kênh alpha) theo opts và ghi ảnh PNG ra out.
+// Ảnh được crop trước, sau đó chuyển thành mask (MaskOnly) hoặc ghép lên nền thay thế.
+// Trả về kích thước ảnh kết quả và khung chủ thể trong ảnh path (nil nếu không crop hoặc
+// ảnh không có chủ thể, khi đó ảnh giữ nguyên kích thước).
+func Background(path, out string, opts BackgroundOptions) (int, int, *domain.BoundingBox, error) {
+	src, err := decodeFile(path)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	b := src.Bounds()
+	img := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(img, img.Bounds(), src, b.Min, draw.Src)
+
+	var box *domain.BoundingBox
+	if opts.Crop {
+		if subject := subjectBounds(img); !subject.Empty() {
+			box = &domain.BoundingBox{Left: subject.Min.X, Top: subject.Min.Y, Width: subject.Dx(), Height: subject.Dy()}
+			rect := subject.Inset(-opts.Padding)
+			cropped := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+			draw.Draw(cropped, cropped.Bounds(), img, rect.Min, draw.Src)
+			img = cropped
+		}
+	}
+
+	w, h := size(img)
+	var result image.Image = img
+	switch {
+	case opts.MaskOnly:
+		mask := image.NewGray(img.Bounds())
+		for i := range mask.Pix {
+			mask.Pix[i] = img.Pix[i*4+3]
+		}
+		result = mask
+	case opts.Color != nil:
+		canvas := image.NewNRGBA(img.Bounds())
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(*opts.Color), image.Point{}, draw.Src)
+		draw.Draw(canvas, canvas.Bounds(), img, image.Point{}, draw.Over)
+		result = canvas
+	case opts.ImagePath != "":
+		background, err := decodeFile(opts.ImagePath)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("background image: %v", err)
+		}
+		canvas := cover(flatten(background), w, h)
+		draw.Draw(canvas, canvas.Bounds(), img, image.Point{}, draw.Over)
+		result = canvas
+	}
+	if err := writeImage(out, result); err != nil {
+		return 0, 0, nil, err
+	}
+	return w, h, box, nil
+}
+
+// EncodeWebP chuyển ảnh PNG path sang WebP (chất lượng 90, giữ nguyên kênh alpha) bằng cwebp của libwebp
+func EncodeWebP(ctx context.Context, path, out string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "cwebp", "-quiet", "-q", "90", "-alpha_q", "100", path, "-o", out)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return fmt.Errorf("cwebp failed: %s", message)
+	}
+	return nil
+}
+
+func decodeFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decode(f)
+}
+
+// subjectBounds trả về khung nhỏ nhất chứa mọi pixel có alpha từ subjectAlpha; rỗng nếu không có pixel nào
+func subjectBounds(img *image.NRGBA) image.Rectangle {
+	w, h := size(img)
+	minX, minY, maxX, maxY := w, h, -1, -1
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride:]
+		for x := 0; x < w; x++ {
+			if row[x*4+3] >= subjectAlpha {
+				minX, maxX = min(minX, x), max(maxX, x)
+				minY, maxY = min(minY, y), max(maxY, y)
+			}
+		}
+	}
+	if maxX < 0 {
+		return image.Rectangle{}
+	}
+	return image.Rect(minX, minY, maxX+1, maxY+1)
+}
+
+// cover co giãn src để phủ kín khung w x h mà không méo ảnh; phần thừa được cắt đều hai phía
+func cover(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := size(src)
+	cw, ch := sw, max(1, sw*h/w)
+	if ch > sh {
+		cw, ch = max(1, sh*w/h), sh
+	}
+	x0, y0 := (sw-cw)/2, (sh-ch)/2
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, image.Rect(x0, y0, x0+cw, y0+ch), xdraw.Src, nil)
+	return dst
+}
@@ -0,0 +1,119 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+const (
+	// maxSkew là góc nghiêng lớn nhất (độ) được tìm khi deskew
+	maxSkew = 15.0
+	// skewSampleSize là cạnh dài của ảnh thu nhỏ dùng để ước lượng góc nghiêng
+	skewSampleSize = 1024
+	// maxSkewPoints là số pixel chữ tối đa dùng để ước lượng góc nghiêng
+	maxSkewPoints = 200000
+	// minSkew là góc nhỏ nhất (độ) đáng để xoay ảnh
+	minSkew = 0.1
+)
+
+// skewAngle ước lượng góc (độ) cần xoay ảnh văn bản để các dòng chữ nằm ngang, theo phương pháp
+// projection profile: với góc đúng, hình chiếu của các pixel chữ lên trục dọc tập trung thành các
+// đỉnh (dòng chữ) nên tổng bình phương của histogram lớn nhất. Trả về 0 nếu ảnh không nghiêng
+// hoặc không giống ảnh văn bản nền sáng.
+func skewAngle(img image.Image) float64 {
+	gray, ok := img.(*image.Gray)
+	if !ok {
+		gray = grayscale(img.(*image.RGBA))
+	}
+	small := downscale(gray, skewSampleSize).(*image.Gray)
+	threshold := otsu(small)
+	w, h := size(small)
+
+	var points [][2]float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if small.Pix[y*small.Stride+x] <= threshold {
+				points = append(points, [2]float64{float64(x), float64(y)})
+			}
+		}
+	}
+	if len(points) == 0 || len(points) > w*h/2 {
+		return 0
+	}
+	if step := len(points)/maxSkewPoints + 1; step > 1 {
+		sampled := points[:0]
+		for i := 0; i < len(points); i += step {
+			sampled = append(sampled, points[i])
+		}
+		points = sampled
+	}
+
+	bins := make([]float64, 2*w+h+2)
+	score := func(angle float64) float64 {
+		sin, cos := math.Sincos(angle * math.Pi / 180)
+		for i := range bins {
+			bins[i] = 0
+		}
+		for _, p := range points {
+			bins[int(p[0]*sin+p[1]*cos)+w]++
+		}
+		var sum float64
+		for _, n := range bins {
+			sum += n * n
+		}
+		return sum
+	}
+
+	best, bestScore := 0.0, score(0)
+	search := func(from, to, step float64) {
+		for angle := from; angle <= to+step/2; angle += step {
+			if s := score(angle); s > bestScore {
+				best, bestScore = angle, s
+			}
+		}
+	}
+	search(-maxSkew, maxSkew, 0.5)
+	search(best-0.5, best+0.5, 0.05)
+
+	best = math.Round(best*100) / 100
+	if math.Abs(best) < minSkew {
+		return 0
+	}
+	return best
+}
+
+// rotate xoay ảnh quanh tâm một góc angle (độ, chiều kim đồng hồ là dương), giữ nguyên kích thước;
+// phần ảnh nằm ngoài khung bị cắt, phần trống được tô trắng
+func rotate(img image.Image, angle float64) image.Image {
+	w, h := size(img)
+	dst := newLike(img, w, h)
+	sp, ss, bpp := pixels(img)
+	dp, ds, _ := pixels(dst)
+
+	sample := func(x, y, c int) float64 {
+		if x < 0 || y < 0 || x >= w || y >= h {
+			return 255
+		}
+		return float64(sp[y*ss+x*bpp+c])
+	}
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	cx, cy := float64(w-1)/2, float64(h-1)/2
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// Điểm của ảnh gốc mà phép xoay đưa tới (x, y)
+			dx, dy := float64(x)-cx, float64(y)-cy
+			sx, sy := dx*cos+dy*sin+cx, -dx*sin+dy*cos+cy
+			x0, y0 := int(math.Floor(sx)), int(math.Floor(sy))
+			fx, fy := sx-float64(x0), sy-float64(y0)
+
+			o := y*ds + x*bpp
+			for c := 0; c < bpp; c++ {
+				v := (1-fx)*(1-fy)*sample(x0, y0, c) + fx*(1-fy)*sample(x0+1, y0, c) +
+					(1-fx)*fy*sample(x0, y0+1, c) + fx*fy*sample(x0+1, y0+1, c)
+				dp[o+c] = uint8(v + 0.5)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,133 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"management-api/internal/domain"
+)
+
+// Ngưỡng đánh giá vùng khuôn mặt của AnalyzeFace
+const (
+	// minFaceSize là cạnh ngắn tối thiểu (pixel) của khuôn mặt để embedding đủ tin cậy
+	minFaceSize    = 80
+	minBrightness  = 60
+	maxBrightness  = 200
+	minContrast    = 25
+	minSharpness   = 50
+	minSaturation  = 0.08
+	maxGlare       = 0.05
+	minTexture     = 15
+	glareLuminance = 250
+)
+
+// AnalyzeFace đo chất lượng và các dấu hiệu liveness của vùng khuôn mặt face trong ảnh path.
+// Vùng khuôn mặt được cắt theo khung ảnh; ảnh không được xoay theo EXIF vì vị trí khuôn mặt
+// do service face-recognition trả về theo ảnh gốc.
+func AnalyzeFace(path string, face domain.FaceBox) (*domain.FaceQuality, *domain.FaceLiveness, error) {
+	src, err := decodeFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	img := flatten(src)
+	rect := image.Rect(face.Left, face.Top, face.Right, face.Bottom).Intersect(img.Bounds())
+	if rect.Empty() {
+		return nil, nil, fmt.Errorf("face box is outside the image")
+	}
+	crop := flatten(img.SubImage(rect))
+	gray := grayscale(crop)
+
+	quality := &domain.FaceQuality{Width: rect.Dx(), Height: rect.Dy(), Issues: []string{}}
+	quality.Brightness, quality.Contrast = meanStdDev(gray)
+	quality.Sharpness = laplacianVariance(gray)
+	if min(rect.Dx(), rect.Dy()) < minFaceSize {
+		quality.Issues = append(quality.Issues, domain.FaceIssueTooSmall)
+	}
+	if quality.Brightness < minBrightness {
+		quality.Issues = append(quality.Issues, domain.FaceIssueTooDark)
+	}
+	if quality.Brightness > maxBrightness {
+		quality.Issues = append(quality.Issues, domain.FaceIssueTooBright)
+	}
+	if quality.Contrast < minContrast {
+		quality.Issues = append(quality.Issues, domain.FaceIssueLowContrast)
+	}
+	if quality.Sharpness < minSharpness {
+		quality.Issues = append(quality.Issues, domain.FaceIssueBlurry)
+	}
+	quality.Score = math.Max(0, 1-0.2*float64(len(quality.Issues)))
+
+	liveness := &domain.FaceLiveness{Hints: []string{}}
+	liveness.Saturation, liveness.Glare = saturationGlare(crop, gray)
+	if liveness.Saturation < minSaturation {
+		liveness.Hints = append(liveness.Hints, domain.LivenessLowSaturation)
+	}
+	if liveness.Glare > maxGlare {
+		liveness.Hints = append(liveness.Hints, domain.LivenessGlare)
+	}
+	if quality.Sharpness < minTexture {
+		liveness.Hints = append(liveness.Hints, domain.LivenessLowTexture)
+	}
+	liveness.Suspicious = len(liveness.Hints) > 0
+	return quality, liveness, nil
+}
+
+// meanStdDev trả về độ sáng trung bình và độ lệch chuẩn của ảnh xám
+func meanStdDev(img *image.Gray) (float64, float64) {
+	w, h := size(img)
+	var sum, sumSq float64
+	for y := 0; y < h; y++ {
+		for _, v := range img.Pix[y*img.Stride : y*img.Stride+w] {
+			sum += float64(v)
+			sumSq += float64(v) * float64(v)
+		}
+	}
+	n := float64(w * h)
+	mean := sum / n
+	return mean, math.Sqrt(math.Max(0, sumSq/n-mean*mean))
+}
+
+// laplacianVariance trả về phương sai của Laplacian (kernel 4 lân cận) của ảnh xám, thước đo độ nét
+func laplacianVariance(img *image.Gray) float64 {
+	w, h := size(img)
+	if w < 3 || h < 3 {
+		return 0
+	}
+	var sum, sumSq float64
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*img.Stride + x
+			v := float64(img.Pix[i-1]) + float64(img.Pix[i+1]) + float64(img.Pix[i-img.Stride]) +
+				float64(img.Pix[i+img.Stride]) - 4*float64(img.Pix[i])
+			sum += v
+			sumSq += v * v
+		}
+	}
+	n := float64((w - 2) * (h - 2))
+	mean := sum / n
+	return sumSq/n - mean*mean
+}
+
+// saturationGlare trả về độ bão hoà màu trung bình (HSV, 0-1) và tỉ lệ pixel gần trắng của ảnh
+func saturationGlare(img *image.RGBA, gray *image.Gray) (float64, float64) {
+	w, h := size(gray)
+	var saturation float64
+	glare := 0
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride:]
+		for x := 0; x < w; x++ {
+			r, g, b := row[x*4], row[x*4+1], row[x*4+2]
+			hi, lo := max(r, g, b), min(r, g, b)
+			if hi > 0 {
+				saturation += float64(hi-lo) / float64(hi)
+			}
+			if gray.Pix[y*gray.Stride+x] >= glareLuminance {
+				glare++
+			}
+		}
+	}
+	n := float64(w * h)
+	return saturation / n, float64(glare) / n
+}
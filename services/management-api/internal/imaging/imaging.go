@@ -0,0 +1,294 @@
+// Package imaging tiền xử lý ảnh trước khi gửi tới service OCR và face-recognition:
+// xoay theo EXIF, thu nhỏ, chuyển xám, nhị phân hoá và chỉnh nghiêng; đồng thời đo chất lượng
+// vùng khuôn mặt cho face-verify, che khuôn mặt cho face-anonymize và crop, ghép nền cho ảnh remove-bg.
+// Các bước làm việc trên *image.RGBA (ảnh màu, đã bỏ kênh alpha) hoặc *image.Gray; riêng ảnh remove-bg
+// được giữ kênh alpha (*image.NRGBA).
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+
+	"management-api/internal/domain"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels là số pixel tối đa (rộng x cao) của ảnh được giải mã, khoảng 200 MB khi giải mã thành RGBA
+const MaxPixels = 50_000_000
+
+// ErrImageTooLarge là lỗi khi ảnh có nhiều pixel hơn MaxPixels
+var ErrImageTooLarge = errors.New("image is too large")
+
+// decode đọc kích thước ảnh từ header trước và chỉ giải mã ảnh không vượt quá MaxPixels
+func decode(r io.ReadSeeker) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %v", err)
+	}
+	if err := CheckSize(cfg.Width, cfg.Height); err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %v", err)
+	}
+	return img, nil
+}
+
+// CheckSize trả về ErrImageTooLarge nếu ảnh width x height vượt quá MaxPixels
+func CheckSize(width, height int) error {
+	if int64(width)*int64(height) > MaxPixels {
+		return fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrImageTooLarge, width, height, MaxPixels)
+	}
+	return nil
+}
+
+// Options là các bước tiền xử lý cần áp dụng
+type Options struct {
+	AutoOrient bool
+	// MaxDimension lớn hơn 0 thì ảnh được thu nhỏ để cạnh dài không vượt quá giá trị này
+	MaxDimension int
+	Grayscale    bool
+	Binarize     bool
+	Deskew       bool
+}
+
+// Process đọc ảnh path, áp dụng các bước trong opts và ghi ảnh PNG ra out. Thứ tự áp dụng:
+// downscale, auto_orient, grayscale, deskew, binarize. Kết quả chỉ ghi các bước đã làm thay đổi ảnh.
+func Process(path, out string, opts Options) (*domain.Preprocessing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	src, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var img image.Image = flatten(src)
+	var ops []domain.PreprocessOperation
+
+	if opts.MaxDimension > 0 {
+		w, h := size(img)
+		if scaled := downscale(img, opts.MaxDimension); scaled != img {
+			img = scaled
+			sw, sh := size(img)
+			ops = append(ops, domain.PreprocessOperation{
+				Name: domain.PreprocessDownscale,
+				From: fmt.Sprintf("%dx%d", w, h),
+				To:   fmt.Sprintf("%dx%d", sw, sh),
+			})
+		}
+	}
+	if opts.AutoOrient {
+		if orientation := exifOrientation(data); orientation > 1 {
+			img = orient(img, orientation)
+			ops = append(ops, domain.PreprocessOperation{Name: domain.PreprocessAutoOrient, Orientation: orientation})
+		}
+	}
+	if opts.Grayscale || opts.Binarize {
+		if rgba, ok := img.(*image.RGBA); ok {
+			img = grayscale(rgba)
+			if opts.Grayscale {
+				ops = append(ops, domain.PreprocessOperation{Name: domain.PreprocessGrayscale})
+			}
+		}
+	}
+	if opts.Deskew {
+		if angle := skewAngle(img); angle != 0 {
+			img = rotate(img, angle)
+			ops = append(ops, domain.PreprocessOperation{Name: domain.PreprocessDeskew, Angle: angle})
+		}
+	}
+	if opts.Binarize {
+		gray := img.(*image.Gray)
+		threshold := otsu(gray)
+		binarize(gray, threshold)
+		ops = append(ops, domain.PreprocessOperation{Name: domain.PreprocessBinarize, Threshold: int(threshold)})
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		return nil, err
+	}
+
+	w, h := size(img)
+	return &domain.Preprocessing{FilePath: out, Width: w, Height: h, Operations: ops}, nil
+}
+
+func size(img image.Image) (int, int) {
+	b := img.Bounds()
+	return b.Dx(), b.Dy()
+}
+
+// flatten chuyển ảnh sang *image.RGBA gốc (0, 0); vùng trong suốt được phủ nền trắng
+func flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	if opaque, ok := src.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// newLike tạo ảnh trống cùng loại với img (*image.RGBA hoặc *image.Gray)
+func newLike(img image.Image, w, h int) image.Image {
+	if _, ok := img.(*image.Gray); ok {
+		return image.NewGray(image.Rect(0, 0, w, h))
+	}
+	return image.NewRGBA(image.Rect(0, 0, w, h))
+}
+
+// pixels trả về mảng pixel, stride và số byte mỗi pixel của img
+func pixels(img image.Image) ([]uint8, int, int) {
+	switch m := img.(type) {
+	case *image.Gray:
+		return m.Pix, m.Stride, 1
+	case *image.RGBA:
+		return m.Pix, m.Stride, 4
+	}
+	panic(fmt.Sprintf("imaging: unsupported image type %T", img))
+}
+
+// downscale thu nhỏ img để cạnh dài không vượt quá maxDimension; trả về chính img nếu ảnh đã đủ nhỏ
+func downscale(img image.Image, maxDimension int) image.Image {
+	w, h := size(img)
+	if w <= maxDimension && h <= maxDimension {
+		return img
+	}
+	sw, sh := maxDimension, h*maxDimension/w
+	if h > w {
+		sw, sh = w*maxDimension/h, maxDimension
+	}
+	if sw < 1 {
+		sw = 1
+	}
+	if sh < 1 {
+		sh = 1
+	}
+	dst := newLike(img, sw, sh)
+	xdraw.BiLinear.Scale(dst.(draw.Image), dst.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+// orient biến đổi ảnh theo EXIF Orientation (2-8) để ảnh đứng thẳng
+func orient(img image.Image, orientation int) image.Image {
+	w, h := size(img)
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := newLike(img, dw, dh)
+	sp, ss, bpp := pixels(img)
+	dp, ds, _ := pixels(dst)
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := x, y
+			switch orientation {
+			case 2: // lật ngang
+				sx, sy = w-1-x, y
+			case 3: // xoay 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // lật dọc
+				sx, sy = x, h-1-y
+			case 5: // chuyển vị
+				sx, sy = y, x
+			case 6: // xoay 90° theo chiều kim đồng hồ
+				sx, sy = y, h-1-x
+			case 7: // chuyển vị ngược
+				sx, sy = w-1-y, h-1-x
+			case 8: // xoay 90° ngược chiều kim đồng hồ
+				sx, sy = w-1-y, x
+			}
+			copy(dp[y*ds+x*bpp:y*ds+x*bpp+bpp], sp[sy*ss+sx*bpp:sy*ss+sx*bpp+bpp])
+		}
+	}
+	return dst
+}
+
+// grayscale chuyển ảnh màu sang ảnh xám theo độ sáng (ITU-R BT.601)
+func grayscale(img *image.RGBA) *image.Gray {
+	w, h := size(img)
+	gray := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride:]
+		for x := 0; x < w; x++ {
+			r, g, b := int(row[x*4]), int(row[x*4+1]), int(row[x*4+2])
+			gray.Pix[y*gray.Stride+x] = uint8((299*r + 587*g + 114*b + 500) / 1000)
+		}
+	}
+	return gray
+}
+
+// otsu trả về ngưỡng chia ảnh xám thành nền và chữ sao cho phương sai giữa hai lớp lớn nhất
+func otsu(img *image.Gray) uint8 {
+	var histogram [256]int
+	w, h := size(img)
+	for y := 0; y < h; y++ {
+		for _, v := range img.Pix[y*img.Stride : y*img.Stride+w] {
+			histogram[v]++
+		}
+	}
+
+	total := w * h
+	var sum float64
+	for v, n := range histogram {
+		sum += float64(v * n)
+	}
+	var sumBelow float64
+	var below int
+	var best float64
+	threshold := 127
+	for v, n := range histogram {
+		below += n
+		if below == 0 {
+			continue
+		}
+		above := total - below
+		if above == 0 {
+			break
+		}
+		sumBelow += float64(v * n)
+		meanBelow := sumBelow / float64(below)
+		meanAbove := (sum - sumBelow) / float64(above)
+		between := float64(below) * float64(above) * (meanBelow - meanAbove) * (meanBelow - meanAbove)
+		if between > best {
+			best = between
+			threshold = v
+		}
+	}
+	return uint8(threshold)
+}
+
+// binarize đặt pixel tối hơn hoặc bằng threshold thành đen, còn lại thành trắng
+func binarize(img *image.Gray, threshold uint8) {
+	for i, v := range img.Pix {
+		if v <= threshold {
+			img.Pix[i] = 0
+		} else {
+			img.Pix[i] = 255
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package migrate
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"management-api/internal/config"
+
+	"github.com/jackc/pgx/v4"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// advisoryLockID khoá các lần chạy migration đồng thời (nhiều container cùng khởi động)
+const advisoryLockID = 727001
+
+// migrationPattern khớp tên file dạng "0001_create_tasks.up.sql"
+var migrationPattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration là một phiên bản schema gồm câu lệnh nâng cấp và hạ cấp
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus là trạng thái của một migration trong database
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrator chạy các migration nhúng trong binary trên một kết nối riêng
+type Migrator struct {
+	conn       *pgx.Conn
+	migrations []Migration
+}
+
+func New(cfg config.DatabaseConfig) (*Migrator, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	dbURL := fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
+		cfg.User,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.Name,
+	)
+	conn, err := pgx.Connect(context.Background(), dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Migrator{conn: conn, migrations: migrations}
+	if err := m.ensureTable(); err != nil {
+		conn.Close(context.Background())
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Migrator) Close() {
+	m.conn.Close(context.Background())
+}
+
+// LatestVersion là phiên bản mới nhất có trong binary
+func LatestVersion() int {
+	migrations, err := loadMigrations()
+	if err != nil || len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// CurrentVersion là phiên bản cao nhất đã được áp dụng vào database
+func (m *Migrator) CurrentVersion() (int, error) {
+	var version int
+	err := m.conn.QueryRow(context.Background(),
+		"SELECT COALESCE(MAX(version), 0) FROM schema_migrations",
+	).Scan(&version)
+	return version, err
+}
+
+// CheckSchema trả lỗi nếu database không ở đúng phiên bản mà binary yêu cầu
+func (m *Migrator) CheckSchema() error {
+	current, err := m.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	if latest := LatestVersion(); current != latest {
+		return fmt.Errorf("schema version is %d but this build requires %d, run 'migrate up'", current, latest)
+	}
+	return nil
+}
+
+// Up áp dụng tất cả migration chưa chạy, theo thứ tự phiên bản
+func (m *Migrator) Up() error {
+	return m.withLock(func(ctx context.Context) error {
+		current, err := m.CurrentVersion()
+		if err != nil {
+			return err
+		}
+
+		applied := 0
+		for _, mig := range m.migrations {
+			if mig.Version <= current {
+				continue
+			}
+			log.Printf("Up: Applying migration %04d_%s", mig.Version, mig.Name)
+			if err := m.apply(ctx, mig.Up, "INSERT INTO schema_migrations (version) VALUES ($1)", mig.Version); err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			applied++
+		}
+
+		if applied == 0 {
+			log.Printf("Up: Schema is up to date at version %d", current)
+		}
+		return nil
+	})
+}
+
+// Down hạ cấp n migration gần nhất
+func (m *Migrator) Down(n int) error {
+	return m.withLock(func(ctx context.Context) error {
+		for i := 0; i < n; i++ {
+			current, err := m.CurrentVersion()
+			if err != nil {
+				return err
+			}
+			if current == 0 {
+				log.Println("Down: No migrations to roll back")
+				return nil
+			}
+
+			mig, ok := m.find(current)
+			if !ok {
+				return fmt.Errorf("migration %d is applied but not found in this build", current)
+			}
+			log.Printf("Down: Rolling back migration %04d_%s", mig.Version, mig.Name)
+			if err := m.apply(ctx, mig.Down, "DELETE FROM schema_migrations WHERE version=$1", mig.Version); err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Status liệt kê mọi migration trong binary cùng thời điểm được áp dụng (nil nếu chưa chạy)
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	rows, err := m.conn.Query(context.Background(), "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		status := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if at, ok := applied[mig.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) ensureTable() error {
+	_, err := m.conn.Exec(context.Background(), `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`)
+	return err
+}
+
+// withLock giữ advisory lock trong suốt quá trình migrate
+func (m *Migrator) withLock(fn func(ctx context.Context) error) error {
+	ctx := context.Background()
+	if _, err := m.conn.Exec(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := m.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", advisoryLockID); err != nil {
+			log.Printf("withLock: Failed to release advisory lock. Error: %v", err)
+		}
+	}()
+	return fn(ctx)
+}
+
+// apply chạy câu lệnh migration và cập nhật schema_migrations trong cùng một transaction
+func (m *Migrator) apply(ctx context.Context, statements, record string, version int) error {
+	tx, err := m.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, statements); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, record, version); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
+
+// loadMigrations đọc các file .sql nhúng và ghép cặp up/down theo phiên bản
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationPattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name '%s'", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names '%s' and '%s'", version, mig.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			mig.Up = string(content)
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down files", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
@@ -1,3 +1,4 @@
+-- Dùng IF NOT EXISTS để các database tạo từ database/init.sql cũ có thể nâng cấp an toàn
 CREATE TABLE IF NOT EXISTS tasks (
     id SERIAL PRIMARY KEY,
     service_name VARCHAR(255) NOT NULL,
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS callback_url,
+    DROP COLUMN IF EXISTS template_version_id,
+    DROP COLUMN IF EXISTS pipeline_step,
+    DROP COLUMN IF EXISTS pipeline_id,
+    DROP COLUMN IF EXISTS batch_id;
+
+DROP TABLE IF EXISTS pipelines;
+DROP TABLE IF EXISTS pipeline_template_versions;
+DROP TABLE IF EXISTS pipeline_templates;
+DROP TABLE IF EXISTS batches;
@@ -0,0 +1,60 @@
+-- Dùng IF NOT EXISTS để các database tạo từ database/init.sql cũ có thể nâng cấp an toàn
+CREATE TABLE IF NOT EXISTS batches (
+    id SERIAL PRIMARY KEY,
+    tool VARCHAR(255) NOT NULL,
+    status VARCHAR(50) DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS pipeline_templates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+-- Tên template là duy nhất trong số các template chưa bị xoá
+CREATE UNIQUE INDEX IF NOT EXISTS pipeline_templates_name_idx ON pipeline_templates (name) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS pipeline_template_versions (
+    id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL REFERENCES pipeline_templates(id),
+    version INTEGER NOT NULL,
+    steps JSONB NOT NULL,
+    parameters JSONB,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (template_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS pipelines (
+    id SERIAL PRIMARY KEY,
+    status VARCHAR(50) DEFAULT 'pending',
+    input_data JSONB,
+    params JSONB,
+    steps JSONB NOT NULL,
+    template_version_id INTEGER REFERENCES pipeline_template_versions(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS batch_id INTEGER REFERENCES batches(id),
+    ADD COLUMN IF NOT EXISTS pipeline_id INTEGER REFERENCES pipelines(id),
+    ADD COLUMN IF NOT EXISTS pipeline_step VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS template_version_id INTEGER REFERENCES pipeline_template_versions(id),
+    ADD COLUMN IF NOT EXISTS callback_url TEXT;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id),
+    url TEXT NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    success BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
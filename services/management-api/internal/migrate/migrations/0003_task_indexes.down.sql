@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS tasks_pipeline_id_idx;
+DROP INDEX IF EXISTS tasks_batch_id_idx;
+DROP INDEX IF EXISTS tasks_created_at_idx;
+DROP INDEX IF EXISTS tasks_status_idx;
+DROP INDEX IF EXISTS tasks_service_name_idx;
@@ -0,0 +1,7 @@
+CREATE INDEX IF NOT EXISTS tasks_service_name_idx ON tasks (service_name);
+CREATE INDEX IF NOT EXISTS tasks_status_idx ON tasks (status);
+CREATE INDEX IF NOT EXISTS tasks_created_at_idx ON tasks (created_at);
+
+-- Tra cứu task con của batch / pipeline
+CREATE INDEX IF NOT EXISTS tasks_batch_id_idx ON tasks (batch_id) WHERE batch_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS tasks_pipeline_id_idx ON tasks (pipeline_id) WHERE pipeline_id IS NOT NULL;
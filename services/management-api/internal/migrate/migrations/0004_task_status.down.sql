@@ -0,0 +1,5 @@
+ALTER TABLE tasks
+    DROP CONSTRAINT IF EXISTS tasks_status_check,
+    ALTER COLUMN status DROP NOT NULL,
+    DROP COLUMN IF EXISTS finished_at,
+    DROP COLUMN IF EXISTS started_at;
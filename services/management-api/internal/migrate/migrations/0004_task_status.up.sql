@@ -0,0 +1,14 @@
+ALTER TABLE tasks
+    ADD COLUMN started_at TIMESTAMP,
+    ADD COLUMN finished_at TIMESTAMP;
+
+-- Chuẩn hoá dữ liệu cũ trước khi thêm ràng buộc
+UPDATE tasks SET status = 'pending' WHERE status IS NULL;
+UPDATE tasks SET status = 'failed' WHERE status NOT IN ('pending', 'queued', 'processing', 'completed', 'failed', 'cancelled', 'expired');
+UPDATE tasks SET started_at = created_at WHERE status IN ('processing', 'completed', 'failed');
+UPDATE tasks SET finished_at = updated_at WHERE status IN ('completed', 'failed', 'cancelled', 'expired');
+
+ALTER TABLE tasks
+    ALTER COLUMN status SET NOT NULL,
+    ADD CONSTRAINT tasks_status_check
+        CHECK (status IN ('pending', 'queued', 'processing', 'completed', 'failed', 'cancelled', 'expired'));
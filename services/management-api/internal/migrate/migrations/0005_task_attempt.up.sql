@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN attempt INTEGER NOT NULL DEFAULT 1;
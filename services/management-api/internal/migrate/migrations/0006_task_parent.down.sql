@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_parent_task_id_idx;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_task_id;
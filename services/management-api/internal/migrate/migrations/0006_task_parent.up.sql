@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN parent_task_id INTEGER REFERENCES tasks(id);
+
+CREATE INDEX tasks_parent_task_id_idx ON tasks (parent_task_id) WHERE parent_task_id IS NOT NULL;
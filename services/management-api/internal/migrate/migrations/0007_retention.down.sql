@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tasks_archive;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS files_purged_at;
@@ -0,0 +1,23 @@
+-- Thời điểm janitor đã xoá các file mà task tham chiếu
+ALTER TABLE tasks ADD COLUMN files_purged_at TIMESTAMP;
+
+-- Task hết hạn được chuyển sang đây khi RETENTION_ARCHIVE=true
+CREATE TABLE tasks_archive (
+    id INTEGER PRIMARY KEY,
+    service_name VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    input_data JSONB,
+    output_data JSONB,
+    batch_id INTEGER,
+    pipeline_id INTEGER,
+    pipeline_step VARCHAR(255),
+    template_version_id INTEGER,
+    callback_url TEXT,
+    attempt INTEGER NOT NULL,
+    parent_task_id INTEGER,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    archived_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
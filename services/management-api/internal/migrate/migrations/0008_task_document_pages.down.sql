@@ -0,0 +1,7 @@
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS page_number;
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS document_task_id;
+
+DROP INDEX IF EXISTS tasks_document_task_id_idx;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS page_number;
+ALTER TABLE tasks DROP COLUMN IF EXISTS document_task_id;
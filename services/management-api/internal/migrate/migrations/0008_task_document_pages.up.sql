@@ -0,0 +1,8 @@
+-- Trang của tài liệu nhiều trang (PDF, TIFF) được OCR thành task con của task tài liệu
+ALTER TABLE tasks ADD COLUMN document_task_id INTEGER REFERENCES tasks(id);
+ALTER TABLE tasks ADD COLUMN page_number INTEGER;
+
+CREATE INDEX tasks_document_task_id_idx ON tasks (document_task_id) WHERE document_task_id IS NOT NULL;
+
+ALTER TABLE tasks_archive ADD COLUMN document_task_id INTEGER;
+ALTER TABLE tasks_archive ADD COLUMN page_number INTEGER;
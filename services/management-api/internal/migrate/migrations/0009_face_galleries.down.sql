@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS face_enrollments;
+DROP TABLE IF EXISTS face_galleries;
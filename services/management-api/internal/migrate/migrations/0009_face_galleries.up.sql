@@ -0,0 +1,24 @@
+-- Gallery khuôn mặt: mỗi enrollment là embedding một khuôn mặt của một người (person_id) trong gallery.
+-- Ảnh gốc không được lưu; embedding được so khớp trong management-api.
+CREATE TABLE face_galleries (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE face_enrollments (
+    id SERIAL PRIMARY KEY,
+    gallery_id INTEGER NOT NULL REFERENCES face_galleries(id) ON DELETE CASCADE,
+    person_id VARCHAR(255) NOT NULL,
+    metadata JSONB,
+    face_top INTEGER NOT NULL,
+    face_right INTEGER NOT NULL,
+    face_bottom INTEGER NOT NULL,
+    face_left INTEGER NOT NULL,
+    embedding DOUBLE PRECISION[] NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX face_enrollments_gallery_person_idx ON face_enrollments (gallery_id, person_id);
@@ -0,0 +1,14 @@
+-- File kết quả của tool (ví dụ ảnh đã xoá nền) được tham chiếu bằng ID ổn định thay cho đường dẫn trong
+-- thư mục ảnh dùng chung. path là đường dẫn tương đối trong thư mục đó; width và height bằng 0 nếu file không phải ảnh.
+CREATE TABLE artifacts (
+    id VARCHAR(64) PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id),
+    path TEXT NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX artifacts_task_id_idx ON artifacts (task_id);
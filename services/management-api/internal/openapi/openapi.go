@@ -0,0 +1,201 @@
+// Package openapi nhúng tài liệu OpenAPI của management-api, phục vụ tài liệu và Swagger UI,
+// và kiểm tra request theo tài liệu để tài liệu và hành vi không bị lệch nhau.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+// maxMultipartMemory giống giá trị mặc định của gin; phần vượt quá được ghi ra file tạm
+const maxMultipartMemory = 32 << 20
+
+// Spec là tài liệu OpenAPI đã được kiểm tra, cùng router để tìm operation của request
+type Spec struct {
+	doc    *openapi3.T
+	router routers.Router
+	json   []byte
+}
+
+func Load() (*Spec, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(specYAML)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Spec{doc: doc, router: router, json: raw}, nil
+}
+
+// MustLoad giống Load nhưng panic khi lỗi; tài liệu được nhúng nên lỗi chỉ xảy ra khi sửa sai file
+func MustLoad() *Spec {
+	spec, err := Load()
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+
+// ServeJSON xử lý endpoint GET /openapi.json
+func (s *Spec) ServeJSON(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", s.json)
+}
+
+// ServeDocs xử lý endpoint GET /docs, trả về Swagger UI đọc /openapi.json
+func (s *Spec) ServeDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUI))
+}
+
+// Validator là middleware kiểm tra path, query và body của request theo tài liệu.
+// Request không khớp route nào trong tài liệu (ví dụ file tĩnh) được cho qua.
+func (s *Spec) Validator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := s.router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		multipartBody := multipartSchema(route.Operation, c.ContentType())
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				// Body multipart được kiểm tra riêng để không phải đọc cả file vào bộ nhớ
+				ExcludeRequestBody: multipartBody != nil,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+			return
+		}
+		if multipartBody != nil {
+			if err := validateMultipart(c.Request, multipartBody); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": errorMessage(err)})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// multipartSchema trả về schema của body multipart/form-data nếu operation nhận loại body này
+func multipartSchema(op *openapi3.Operation, contentType string) *openapi3.Schema {
+	if contentType != "multipart/form-data" || op.RequestBody == nil || op.RequestBody.Value == nil {
+		return nil
+	}
+	media := op.RequestBody.Value.Content.Get(contentType)
+	if media == nil || media.Schema == nil {
+		return nil
+	}
+	return media.Schema.Value
+}
+
+// validateMultipart đọc form bằng ParseMultipartForm (handler dùng lại form đã đọc) rồi kiểm tra
+// theo schema. Trường "format: binary" phải là file, các trường khác phải là text.
+func validateMultipart(req *http.Request, schema *openapi3.Schema) error {
+	if err := req.ParseMultipartForm(maxMultipartMemory); err != nil {
+		return fmt.Errorf("Invalid multipart body: %w", err)
+	}
+	form := req.MultipartForm
+
+	obj := make(map[string]interface{})
+	for name, prop := range schema.Properties {
+		itemSchema := prop.Value
+		if itemSchema.Type.Is(openapi3.TypeArray) && itemSchema.Items != nil {
+			itemSchema = itemSchema.Items.Value
+		}
+
+		var values []interface{}
+		if itemSchema.Format == "binary" {
+			if len(form.Value[name]) > 0 {
+				return fmt.Errorf("Invalid request body: '%s' must be a file", name)
+			}
+			for range form.File[name] {
+				values = append(values, "")
+			}
+		} else {
+			if len(form.File[name]) > 0 {
+				return fmt.Errorf("Invalid request body: '%s' must be a text field", name)
+			}
+			for _, v := range form.Value[name] {
+				values = append(values, v)
+			}
+		}
+
+		switch {
+		case len(values) == 0:
+		case prop.Value.Type.Is(openapi3.TypeArray):
+			obj[name] = values
+		default:
+			obj[name] = values[0]
+		}
+	}
+	return schema.VisitJSON(obj)
+}
+
+// errorMessage chuyển lỗi kiểm tra thành thông báo ngắn gọn cho client
+func errorMessage(err error) string {
+	var reqErr *openapi3filter.RequestError
+	var schemaErr *openapi3.SchemaError
+
+	prefix := "Invalid request body"
+	if errors.As(err, &reqErr) && reqErr.Parameter != nil {
+		prefix = fmt.Sprintf("Invalid %s parameter '%s'", reqErr.Parameter.In, reqErr.Parameter.Name)
+	}
+
+	switch {
+	case errors.As(err, &schemaErr):
+		if field := strings.Join(schemaErr.JSONPointer(), "."); field != "" {
+			return fmt.Sprintf("%s at '%s': %s", prefix, field, schemaErr.Reason)
+		}
+		return fmt.Sprintf("%s: %s", prefix, schemaErr.Reason)
+	case reqErr != nil:
+		return fmt.Sprintf("%s: %v", prefix, reqErr.Err)
+	}
+	return err.Error()
+}
+
+const swaggerUI = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>itool management API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"management-api/internal/domain"
+)
+
+// CreateArtifact lưu artifact với ID đã được tạo sẵn và ghi thời điểm tạo vào a
+func (r *taskRepository) CreateArtifact(a *domain.Artifact) error {
+	return r.db.QueryRow(context.Background(),
+		`INSERT INTO artifacts (id, task_id, path, content_type, size, width, height)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at`,
+		a.ID, a.TaskID, a.Path, a.ContentType, a.Size, a.Width, a.Height,
+	).Scan(&a.CreatedAt)
+}
+
+func (r *taskRepository) GetArtifact(id string) (*domain.Artifact, error) {
+	var a domain.Artifact
+	err := r.db.QueryRow(context.Background(),
+		"SELECT id, task_id, path, content_type, size, width, height, created_at FROM artifacts WHERE id=$1",
+		id,
+	).Scan(&a.ID, &a.TaskID, &a.Path, &a.ContentType, &a.Size, &a.Width, &a.Height, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	a.URL = domain.ArtifactURL(a.ID)
+	return &a, nil
+}
+
+// GetTaskArtifacts trả về các artifact của task theo thứ tự tạo
+func (r *taskRepository) GetTaskArtifacts(taskID int) ([]domain.Artifact, error) {
+	rows, err := r.db.Query(context.Background(),
+		"SELECT id, task_id, path, content_type, size, width, height, created_at FROM artifacts WHERE task_id=$1 ORDER BY created_at, id",
+		taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	artifacts := []domain.Artifact{}
+	for rows.Next() {
+		var a domain.Artifact
+		if err := rows.Scan(&a.ID, &a.TaskID, &a.Path, &a.ContentType, &a.Size, &a.Width, &a.Height, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.URL = domain.ArtifactURL(a.ID)
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, rows.Err()
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"management-api/internal/domain"
+)
+
+// CreateBatch tạo batch mới ở trạng thái pending
+func (r *taskRepository) CreateBatch(tool string) (int, error) {
+	var id int
+	err := r.db.QueryRow(context.Background(),
+		"INSERT INTO batches (tool, status) VALUES ($1, $2) RETURNING id",
+		tool, "pending",
+	).Scan(&id)
+	return id, err
+}
+
+// GetBatch lấy batch kèm tiến độ tổng hợp từ các task con
+func (r *taskRepository) GetBatch(id int) (*domain.Batch, error) {
+	var batch domain.Batch
+	err := r.db.QueryRow(context.Background(),
+		"SELECT id, tool, status, created_at, updated_at FROM batches WHERE id=$1",
+		id,
+	).Scan(&batch.ID, &batch.Tool, &batch.Status, &batch.CreatedAt, &batch.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(context.Background(),
+		"SELECT status, COUNT(*) FROM tasks WHERE batch_id=$1 GROUP BY status",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	p := &batch.Progress
+	for rows.Next() {
+		var status domain.TaskStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		p.Total += count
+		switch status {
+		case domain.TaskStatusPending:
+			p.Pending = count
+		case domain.TaskStatusQueued:
+			p.Queued = count
+		case domain.TaskStatusProcessing:
+			p.Processing = count
+		case domain.TaskStatusCompleted:
+			p.Completed = count
+		case domain.TaskStatusFailed:
+			p.Failed = count
+		case domain.TaskStatusCancelled:
+			p.Cancelled = count
+		case domain.TaskStatusExpired:
+			p.Expired = count
+		}
+	}
+	if p.Total > 0 {
+		finished := p.Completed + p.Failed + p.Cancelled + p.Expired
+		p.Percent = float64(finished) * 100 / float64(p.Total)
+	}
+
+	return &batch, rows.Err()
+}
+
+// GetBatchTasks lấy các task con của batch theo thứ tự tạo
+func (r *taskRepository) GetBatchTasks(batchID int) ([]domain.Task, error) {
+	return r.queryTasks("SELECT "+taskColumns+" FROM tasks WHERE batch_id=$1 ORDER BY id", batchID)
+}
+
+// UpdateBatchStatus cập nhật trạng thái của batch
+func (r *taskRepository) UpdateBatchStatus(id int, status string) error {
+	_, err := r.db.Exec(context.Background(),
+		"UPDATE batches SET status=$1, updated_at=NOW() WHERE id=$2",
+		status, id,
+	)
+	return err
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+
+	"management-api/internal/domain"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// galleryColumns lấy thông tin gallery kèm số người và số khuôn mặt đã đăng ký
+const galleryColumns = `g.id, g.name, g.description,
+	(SELECT COUNT(DISTINCT e.person_id) FROM face_enrollments e WHERE e.gallery_id = g.id),
+	(SELECT COUNT(*) FROM face_enrollments e WHERE e.gallery_id = g.id),
+	g.created_at, g.updated_at`
+
+const enrollmentColumns = "id, gallery_id, person_id, metadata, face_top, face_right, face_bottom, face_left, embedding, created_at"
+
+func scanGallery(row pgx.Row) (*domain.FaceGallery, error) {
+	var g domain.FaceGallery
+	err := row.Scan(&g.ID, &g.Name, &g.Description, &g.PersonCount, &g.EnrollmentCount, &g.CreatedAt, &g.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func scanEnrollment(row pgx.Row) (*domain.FaceEnrollment, error) {
+	var e domain.FaceEnrollment
+	err := row.Scan(&e.ID, &e.GalleryID, &e.PersonID, &e.Metadata,
+		&e.Face.Top, &e.Face.Right, &e.Face.Bottom, &e.Face.Left, &e.Embedding, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *taskRepository) CreateGallery(name, description string) (int, error) {
+	var id int
+	err := r.db.QueryRow(context.Background(),
+		"INSERT INTO face_galleries (name, description) VALUES ($1, $2) RETURNING id",
+		name, description,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *taskRepository) GetGalleryByName(name string) (*domain.FaceGallery, error) {
+	return scanGallery(r.db.QueryRow(context.Background(),
+		"SELECT "+galleryColumns+" FROM face_galleries g WHERE g.name=$1",
+		name,
+	))
+}
+
+func (r *taskRepository) GetAllGalleries() ([]domain.FaceGallery, error) {
+	rows, err := r.db.Query(context.Background(),
+		"SELECT "+galleryColumns+" FROM face_galleries g ORDER BY g.name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var galleries []domain.FaceGallery
+	for rows.Next() {
+		g, err := scanGallery(rows)
+		if err != nil {
+			return nil, err
+		}
+		galleries = append(galleries, *g)
+	}
+	return galleries, rows.Err()
+}
+
+// DeleteGallery xoá gallery cùng mọi enrollment của gallery
+func (r *taskRepository) DeleteGallery(id int) error {
+	_, err := r.db.Exec(context.Background(), "DELETE FROM face_galleries WHERE id=$1", id)
+	return err
+}
+
+// CreateEnrollment lưu enrollment và ghi ID, thời điểm tạo vào e
+func (r *taskRepository) CreateEnrollment(e *domain.FaceEnrollment) error {
+	return r.db.QueryRow(context.Background(),
+		`INSERT INTO face_enrollments (gallery_id, person_id, metadata, face_top, face_right, face_bottom, face_left, embedding)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at`,
+		e.GalleryID, e.PersonID, e.Metadata, e.Face.Top, e.Face.Right, e.Face.Bottom, e.Face.Left, e.Embedding,
+	).Scan(&e.ID, &e.CreatedAt)
+}
+
+// GetEnrollments lấy các enrollment của gallery (kèm embedding); personID khác rỗng thì chỉ lấy của người đó
+func (r *taskRepository) GetEnrollments(galleryID int, personID string) ([]domain.FaceEnrollment, error) {
+	rows, err := r.db.Query(context.Background(),
+		"SELECT "+enrollmentColumns+" FROM face_enrollments WHERE gallery_id=$1 AND ($2 = '' OR person_id=$2) ORDER BY id",
+		galleryID, personID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var enrollments []domain.FaceEnrollment
+	for rows.Next() {
+		e, err := scanEnrollment(rows)
+		if err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, *e)
+	}
+	return enrollments, rows.Err()
+}
+
+// DeleteEnrollments xoá enrollment id (khác 0) hoặc mọi enrollment của personID trong gallery;
+// trả về số enrollment đã xoá
+func (r *taskRepository) DeleteEnrollments(galleryID, id int, personID string) (int, error) {
+	tag, err := r.db.Exec(context.Background(),
+		"DELETE FROM face_enrollments WHERE gallery_id=$1 AND ($2 = 0 OR id=$2) AND ($3 = '' OR person_id=$3)",
+		galleryID, id, personID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"management-api/internal/domain"
+)
+
+// CreatePipeline lưu định nghĩa pipeline ở trạng thái pending
+func (r *taskRepository) CreatePipeline(pipeline *domain.Pipeline) (int, error) {
+	var id int
+	err := r.db.QueryRow(context.Background(),
+		"INSERT INTO pipelines (status, input_data, params, steps, template_version_id) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		"pending", pipeline.Input, pipeline.Params, pipeline.Steps, pipeline.TemplateVersionID,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *taskRepository) GetPipeline(id int) (*domain.Pipeline, error) {
+	var pipeline domain.Pipeline
+	err := r.db.QueryRow(context.Background(),
+		"SELECT id, status, input_data, params, steps, template_version_id, created_at, updated_at FROM pipelines WHERE id=$1",
+		id,
+	).Scan(&pipeline.ID, &pipeline.Status, &pipeline.Input, &pipeline.Params, &pipeline.Steps, &pipeline.TemplateVersionID, &pipeline.CreatedAt, &pipeline.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// GetPipelineTasks lấy các task của pipeline theo thứ tự các bước
+func (r *taskRepository) GetPipelineTasks(pipelineID int) ([]domain.Task, error) {
+	return r.queryTasks("SELECT "+taskColumns+" FROM tasks WHERE pipeline_id=$1 ORDER BY id", pipelineID)
+}
+
+// CreatePipelineTask tạo task pending cho một bước của pipeline.
+// input_data ban đầu là định nghĩa của bước, được thay bằng input thật khi bước bắt đầu chạy.
+func (r *taskRepository) CreatePipelineTask(pipeline *domain.Pipeline, step domain.PipelineStep) (int, error) {
+	var id int
+	err := r.db.QueryRow(context.Background(),
+		"INSERT INTO tasks (service_name, status, input_data, pipeline_id, pipeline_step, template_version_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		step.Tool, string(domain.TaskStatusPending), step, pipeline.ID, step.ID, pipeline.TemplateVersionID,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *taskRepository) UpdatePipelineStatus(id int, status string) error {
+	_, err := r.db.Exec(context.Background(),
+		"UPDATE pipelines SET status=$1, updated_at=NOW() WHERE id=$2",
+		status, id,
+	)
+	return err
+}
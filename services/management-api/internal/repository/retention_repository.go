@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"management-api/internal/domain"
+)
+
+// terminalStatuses là các trạng thái kết thúc, chỉ task ở các trạng thái này mới bị dọn dẹp
+func terminalStatuses() []string {
+	var statuses []string
+	for _, s := range domain.TaskStatuses {
+		if s.IsTerminal() {
+			statuses = append(statuses, string(s))
+		}
+	}
+	return statuses
+}
+
+// expiredTaskCondition lọc task đã kết thúc trước mốc của tool ($2, $3) hoặc mốc mặc định ($4)
+const expiredTaskCondition = `status = ANY($1) AND COALESCE(finished_at, updated_at) < COALESCE(
+	(SELECT c.cutoff FROM unnest($2::text[], $3::timestamp[]) AS c(service_name, cutoff) WHERE c.service_name = tasks.service_name),
+	$4)`
+
+func cutoffArgs(cutoffs map[string]time.Time, defaultCutoff time.Time) []interface{} {
+	services := make([]string, 0, len(cutoffs))
+	times := make([]time.Time, 0, len(cutoffs))
+	for service, cutoff := range cutoffs {
+		services = append(services, service)
+		times = append(times, cutoff)
+	}
+	return []interface{}{terminalStatuses(), services, times, defaultCutoff}
+}
+
+// GetTasksForFilePurge lấy tối đa limit task đã kết thúc trước finishedBefore mà file chưa bị xoá
+func (r *taskRepository) GetTasksForFilePurge(finishedBefore time.Time, limit int) ([]domain.Task, error) {
+	return r.queryTasks(
+		"SELECT "+taskColumns+" FROM tasks WHERE status = ANY($1) AND files_purged_at IS NULL AND COALESCE(finished_at, updated_at) < $2 ORDER BY id LIMIT $3",
+		terminalStatuses(), finishedBefore, limit,
+	)
+}
+
+// GetTasksWithFiles lấy các task mà file chưa bị xoá, kể cả task chưa kết thúc
+func (r *taskRepository) GetTasksWithFiles() ([]domain.Task, error) {
+	return r.queryTasks("SELECT " + taskColumns + " FROM tasks WHERE files_purged_at IS NULL")
+}
+
+func (r *taskRepository) MarkTaskFilesPurged(ids []int) error {
+	_, err := r.db.Exec(context.Background(),
+		"UPDATE tasks SET files_purged_at=NOW() WHERE id = ANY($1)",
+		ids,
+	)
+	return err
+}
+
+// CountExpiredTasks đếm task hết hạn theo service_name
+func (r *taskRepository) CountExpiredTasks(cutoffs map[string]time.Time, defaultCutoff time.Time) (map[string]int, error) {
+	rows, err := r.db.Query(context.Background(),
+		"SELECT service_name, COUNT(*) FROM tasks WHERE "+expiredTaskCondition+" GROUP BY service_name",
+		cutoffArgs(cutoffs, defaultCutoff)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var service string
+		var count int
+		if err := rows.Scan(&service, &count); err != nil {
+			return nil, err
+		}
+		counts[service] = count
+	}
+	return counts, rows.Err()
+}
+
+// RemoveExpiredTasks xoá task hết hạn cùng lịch sử webhook và artifact của chúng, trong một transaction.
+// archive bằng true thì task được chép sang tasks_archive trước khi xoá. Trả về số task đã xoá.
+func (r *taskRepository) RemoveExpiredTasks(cutoffs map[string]time.Time, defaultCutoff time.Time, archive bool) (int, error) {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var ids []int
+	rows, err := tx.Query(ctx, "SELECT id FROM tasks WHERE "+expiredTaskCondition+" FOR UPDATE", cutoffArgs(cutoffs, defaultCutoff)...)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	statements := []string{
+		"UPDATE tasks SET parent_task_id=NULL WHERE parent_task_id = ANY($1)",
+		"UPDATE tasks SET document_task_id=NULL WHERE document_task_id = ANY($1)",
+		"DELETE FROM webhook_deliveries WHERE task_id = ANY($1)",
+		"DELETE FROM artifacts WHERE task_id = ANY($1)",
+	}
+	if archive {
+		statements = append(statements, "INSERT INTO tasks_archive ("+taskColumns+") SELECT "+taskColumns+" FROM tasks WHERE id = ANY($1)")
+	}
+	statements = append(statements, "DELETE FROM tasks WHERE id = ANY($1)")
+
+	for _, sql := range statements {
+		if _, err := tx.Exec(ctx, sql, ids); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), tx.Commit(ctx)
+}
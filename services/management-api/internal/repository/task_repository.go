@@ -3,27 +3,80 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"management-api/internal/config"
 	"management-api/internal/domain"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type TaskRepository interface {
 	GetTask(id int) (*domain.Task, error)
 	GetAllTasks() ([]domain.Task, error)
+	CreateTask(serviceName string, status domain.TaskStatus, input interface{}, batchID *int) (int, error)
+	CreateToolTask(tool string, status domain.TaskStatus, input interface{}, callbackURL string) (int, error)
+	CreateRetryTask(parent *domain.Task, input interface{}, callbackURL *string) (int, error)
+	UpdateTask(id int, status domain.TaskStatus, output interface{}) error
+	UpdateTaskInput(id int, input interface{}) error
+	CreatePageTask(documentTaskID, page int, input interface{}) (int, error)
+	GetStuckTasks(startedBefore time.Time) ([]domain.Task, error)
+	RequeueTask(id int) error
 	Close()
-	// Các phương thức khác như tạo, cập nhật task
+
+	CreateBatch(tool string) (int, error)
+	GetBatch(id int) (*domain.Batch, error)
+	GetBatchTasks(batchID int) ([]domain.Task, error)
+	UpdateBatchStatus(id int, status string) error
+
+	CreatePipeline(pipeline *domain.Pipeline) (int, error)
+	GetPipeline(id int) (*domain.Pipeline, error)
+	GetPipelineTasks(pipelineID int) ([]domain.Task, error)
+	CreatePipelineTask(pipeline *domain.Pipeline, step domain.PipelineStep) (int, error)
+	UpdatePipelineStatus(id int, status string) error
+
+	CreateTemplate(name, description string, steps []domain.PipelineStep, params []domain.TemplateParameter) (int, error)
+	UpdateTemplateDescription(id int, description string) error
+	GetTemplateByName(name string) (*domain.PipelineTemplate, error)
+	GetAllTemplates() ([]domain.PipelineTemplate, error)
+	DeleteTemplate(id int) error
+	CreateTemplateVersion(templateID int, steps []domain.PipelineStep, params []domain.TemplateParameter) (*domain.PipelineTemplateVersion, error)
+	GetTemplateVersion(templateID, version int) (*domain.PipelineTemplateVersion, error)
+	GetTemplateVersions(templateID int) ([]domain.PipelineTemplateVersion, error)
+
+	CreateGallery(name, description string) (int, error)
+	GetGalleryByName(name string) (*domain.FaceGallery, error)
+	GetAllGalleries() ([]domain.FaceGallery, error)
+	DeleteGallery(id int) error
+	CreateEnrollment(enrollment *domain.FaceEnrollment) error
+	GetEnrollments(galleryID int, personID string) ([]domain.FaceEnrollment, error)
+	DeleteEnrollments(galleryID, id int, personID string) (int, error)
+
+	CreateArtifact(artifact *domain.Artifact) error
+	GetArtifact(id string) (*domain.Artifact, error)
+	GetTaskArtifacts(taskID int) ([]domain.Artifact, error)
+
+	CreateWebhookDelivery(delivery *domain.WebhookDelivery) error
+	GetTaskDeliveries(taskID int) ([]domain.WebhookDelivery, error)
+
+	GetTasksForFilePurge(finishedBefore time.Time, limit int) ([]domain.Task, error)
+	GetTasksWithFiles() ([]domain.Task, error)
+	MarkTaskFilesPurged(ids []int) error
+	CountExpiredTasks(cutoffs map[string]time.Time, defaultCutoff time.Time) (map[string]int, error)
+	RemoveExpiredTasks(cutoffs map[string]time.Time, defaultCutoff time.Time, archive bool) (int, error)
 }
 
 type taskRepository struct {
 	db *pgxpool.Pool
 }
 
+// taskColumns là danh sách cột dùng chung cho các câu SELECT task, theo đúng thứ tự của scanTask.
+// Bảng tasks_archive có cùng các cột này; khi thêm cột cho tasks cần thêm cả vào tasks_archive.
+const taskColumns = "id, service_name, status, input_data, output_data, batch_id, pipeline_id, pipeline_step, template_version_id, callback_url, attempt, parent_task_id, document_task_id, page_number, started_at, finished_at, created_at, updated_at"
+
 func (r *taskRepository) Close() {
-	//TODO implement me
-	panic("implement me")
+	r.db.Close()
 }
 
 func NewTaskRepository(cfg config.DatabaseConfig) (TaskRepository, error) {
@@ -41,20 +94,19 @@ func NewTaskRepository(cfg config.DatabaseConfig) (TaskRepository, error) {
 	return &taskRepository{db: pool}, nil
 }
 
-func (r *taskRepository) GetTask(id int) (*domain.Task, error) {
+// scanTask đọc một dòng có các cột taskColumns
+func scanTask(row pgx.Row) (*domain.Task, error) {
 	var task domain.Task
-	err := r.db.QueryRow(context.Background(),
-		"SELECT id, service_name, status, input_data, output_data, created_at, updated_at FROM tasks WHERE id=$1",
-		id,
-	).Scan(&task.ID, &task.ServiceName, &task.Status, &task.InputData, &task.OutputData, &task.CreatedAt, &task.UpdatedAt)
+	err := row.Scan(&task.ID, &task.ServiceName, &task.Status, &task.InputData, &task.OutputData, &task.BatchID, &task.PipelineID, &task.PipelineStep, &task.TemplateVersionID, &task.CallbackURL, &task.Attempt, &task.ParentTaskID, &task.DocumentTaskID, &task.PageNumber, &task.StartedAt, &task.FinishedAt, &task.CreatedAt, &task.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return &task, nil
 }
 
-func (r *taskRepository) GetAllTasks() ([]domain.Task, error) {
-	rows, err := r.db.Query(context.Background(), "SELECT id, service_name, status, input_data, output_data, created_at, updated_at FROM tasks ORDER BY created_at DESC")
+// queryTasks chạy câu truy vấn trả về danh sách task
+func (r *taskRepository) queryTasks(sql string, args ...interface{}) ([]domain.Task, error) {
+	rows, err := r.db.Query(context.Background(), sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -62,13 +114,135 @@ func (r *taskRepository) GetAllTasks() ([]domain.Task, error) {
 
 	var tasks []domain.Task
 	for rows.Next() {
-		var task domain.Task
-		err := rows.Scan(&task.ID, &task.ServiceName, &task.Status, &task.InputData, &task.OutputData, &task.CreatedAt, &task.UpdatedAt)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
-		tasks = append(tasks, task)
+		tasks = append(tasks, *task)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (r *taskRepository) GetTask(id int) (*domain.Task, error) {
+	return scanTask(r.db.QueryRow(context.Background(),
+		"SELECT "+taskColumns+" FROM tasks WHERE id=$1",
+		id,
+	))
+}
+
+func (r *taskRepository) GetAllTasks() ([]domain.Task, error) {
+	return r.queryTasks("SELECT " + taskColumns + " FROM tasks ORDER BY created_at DESC")
+}
+
+// CreateTask tạo một task mới và trả về ID của task
+func (r *taskRepository) CreateTask(serviceName string, status domain.TaskStatus, input interface{}, batchID *int) (int, error) {
+	var id int
+	err := r.db.QueryRow(context.Background(),
+		`INSERT INTO tasks (service_name, status, input_data, batch_id, started_at)
+		VALUES ($1, $2, $3, $4, CASE WHEN $5 THEN NOW() END) RETURNING id`,
+		serviceName, string(status), input, batchID, status == domain.TaskStatusProcessing,
+	).Scan(&id)
+	return id, err
+}
+
+// CreateToolTask tạo task cho một lần gọi endpoint tool: processing nếu chạy ngay, queued nếu chạy ở background.
+// callbackURL rỗng nghĩa là không gửi webhook.
+func (r *taskRepository) CreateToolTask(tool string, status domain.TaskStatus, input interface{}, callbackURL string) (int, error) {
+	var callback *string
+	if callbackURL != "" {
+		callback = &callbackURL
 	}
+	var id int
+	err := r.db.QueryRow(context.Background(),
+		`INSERT INTO tasks (service_name, status, input_data, callback_url, started_at)
+		VALUES ($1, $2, $3, $4, CASE WHEN $5 THEN NOW() END) RETURNING id`,
+		tool, string(status), input, callback, status == domain.TaskStatusProcessing,
+	).Scan(&id)
+	return id, err
+}
 
-	return tasks, nil
+// CreateRetryTask tạo task queued chạy lại parent, với số lần thử tiếp theo
+func (r *taskRepository) CreateRetryTask(parent *domain.Task, input interface{}, callbackURL *string) (int, error) {
+	var id int
+	err := r.db.QueryRow(context.Background(),
+		`INSERT INTO tasks (service_name, status, input_data, callback_url, parent_task_id, attempt)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		parent.ServiceName, string(domain.TaskStatusQueued), input, callbackURL, parent.ID, parent.Attempt+1,
+	).Scan(&id)
+	return id, err
+}
+
+// CreatePageTask tạo task queued OCR một trang (page bắt đầu từ 1) của task tài liệu documentTaskID
+func (r *taskRepository) CreatePageTask(documentTaskID, page int, input interface{}) (int, error) {
+	var id int
+	err := r.db.QueryRow(context.Background(),
+		`INSERT INTO tasks (service_name, status, input_data, document_task_id, page_number)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		domain.ToolOCR, string(domain.TaskStatusQueued), input, documentTaskID, page,
+	).Scan(&id)
+	return id, err
+}
+
+// UpdateTask chuyển task sang trạng thái mới. output bằng nil thì giữ nguyên output_data hiện tại.
+// started_at được ghi khi task bắt đầu processing, finished_at khi task kết thúc.
+// Trả về lỗi bọc domain.ErrInvalidTransition nếu trạng thái hiện tại không thể chuyển sang status.
+func (r *taskRepository) UpdateTask(id int, status domain.TaskStatus, output interface{}) error {
+	sources := make([]string, 0, len(domain.TaskStatuses))
+	for _, s := range domain.TransitionSources(status) {
+		sources = append(sources, string(s))
+	}
+
+	tag, err := r.db.Exec(context.Background(),
+		`UPDATE tasks SET status=$1, output_data=COALESCE($2, output_data), updated_at=NOW(),
+			started_at = CASE WHEN $4 THEN COALESCE(started_at, NOW()) ELSE started_at END,
+			finished_at = CASE WHEN $5 THEN NOW() ELSE finished_at END
+		WHERE id=$3 AND status = ANY($6)`,
+		string(status), output, id, status == domain.TaskStatusProcessing, status.IsTerminal(), sources,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	// Không có dòng nào được cập nhật: task không tồn tại hoặc chuyển trạng thái không hợp lệ
+	task, err := r.GetTask(id)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: task %d is %s, cannot become %s", domain.ErrInvalidTransition, id, task.Status, status)
+}
+
+// UpdateTaskInput ghi lại input_data của task, ví dụ sau khi các tham chiếu đã được giải quyết
+func (r *taskRepository) UpdateTaskInput(id int, input interface{}) error {
+	_, err := r.db.Exec(context.Background(),
+		"UPDATE tasks SET input_data=$1, updated_at=NOW() WHERE id=$2",
+		input, id,
+	)
+	return err
+}
+
+// GetStuckTasks lấy các task đang processing bắt đầu trước startedBefore
+func (r *taskRepository) GetStuckTasks(startedBefore time.Time) ([]domain.Task, error) {
+	return r.queryTasks(
+		"SELECT "+taskColumns+" FROM tasks WHERE status=$1 AND COALESCE(started_at, updated_at) < $2 ORDER BY id",
+		string(domain.TaskStatusProcessing), startedBefore,
+	)
+}
+
+// RequeueTask đưa task đang processing trở lại hàng đợi và tăng số lần thử
+func (r *taskRepository) RequeueTask(id int) error {
+	tag, err := r.db.Exec(context.Background(),
+		"UPDATE tasks SET status=$1, attempt=attempt+1, started_at=NULL, updated_at=NOW() WHERE id=$2 AND status=$3",
+		string(domain.TaskStatusQueued), id, string(domain.TaskStatusProcessing),
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: task %d is not processing", domain.ErrInvalidTransition, id)
+	}
+	return nil
 }
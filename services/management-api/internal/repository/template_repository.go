@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+
+	"management-api/internal/domain"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// templateColumns lấy thông tin template kèm số phiên bản mới nhất
+const templateColumns = `t.id, t.name, t.description,
+	COALESCE((SELECT MAX(v.version) FROM pipeline_template_versions v WHERE v.template_id = t.id), 0),
+	t.created_at, t.updated_at`
+
+func scanTemplate(row pgx.Row) (*domain.PipelineTemplate, error) {
+	var t domain.PipelineTemplate
+	err := row.Scan(&t.ID, &t.Name, &t.Description, &t.LatestVersion, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func scanTemplateVersion(row pgx.Row) (*domain.PipelineTemplateVersion, error) {
+	var v domain.PipelineTemplateVersion
+	err := row.Scan(&v.ID, &v.TemplateID, &v.Version, &v.Steps, &v.Parameters, &v.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// CreateTemplate tạo template mới cùng phiên bản 1 trong một transaction
+func (r *taskRepository) CreateTemplate(name, description string, steps []domain.PipelineStep, params []domain.TemplateParameter) (int, error) {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx,
+		"INSERT INTO pipeline_templates (name, description) VALUES ($1, $2) RETURNING id",
+		name, description,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(ctx,
+		"INSERT INTO pipeline_template_versions (template_id, version, steps, parameters) VALUES ($1, 1, $2, $3)",
+		id, steps, params,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit(ctx)
+}
+
+func (r *taskRepository) UpdateTemplateDescription(id int, description string) error {
+	_, err := r.db.Exec(context.Background(),
+		"UPDATE pipeline_templates SET description=$1, updated_at=NOW() WHERE id=$2",
+		description, id,
+	)
+	return err
+}
+
+// GetTemplateByName lấy template chưa bị xoá theo tên
+func (r *taskRepository) GetTemplateByName(name string) (*domain.PipelineTemplate, error) {
+	return scanTemplate(r.db.QueryRow(context.Background(),
+		"SELECT "+templateColumns+" FROM pipeline_templates t WHERE t.name=$1 AND t.deleted_at IS NULL",
+		name,
+	))
+}
+
+func (r *taskRepository) GetAllTemplates() ([]domain.PipelineTemplate, error) {
+	rows, err := r.db.Query(context.Background(),
+		"SELECT "+templateColumns+" FROM pipeline_templates t WHERE t.deleted_at IS NULL ORDER BY t.name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []domain.PipelineTemplate
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+	return templates, rows.Err()
+}
+
+// DeleteTemplate xoá mềm template. Các phiên bản vẫn được giữ lại vì task tham chiếu tới chúng.
+func (r *taskRepository) DeleteTemplate(id int) error {
+	_, err := r.db.Exec(context.Background(),
+		"UPDATE pipeline_templates SET deleted_at=NOW(), updated_at=NOW() WHERE id=$1",
+		id,
+	)
+	return err
+}
+
+// CreateTemplateVersion tạo phiên bản tiếp theo của template
+func (r *taskRepository) CreateTemplateVersion(templateID int, steps []domain.PipelineStep, params []domain.TemplateParameter) (*domain.PipelineTemplateVersion, error) {
+	return scanTemplateVersion(r.db.QueryRow(context.Background(),
+		`INSERT INTO pipeline_template_versions (template_id, version, steps, parameters)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3 FROM pipeline_template_versions WHERE template_id=$1
+		RETURNING id, template_id, version, steps, parameters, created_at`,
+		templateID, steps, params,
+	))
+}
+
+// GetTemplateVersion lấy một phiên bản của template; version bằng 0 là phiên bản mới nhất
+func (r *taskRepository) GetTemplateVersion(templateID, version int) (*domain.PipelineTemplateVersion, error) {
+	return scanTemplateVersion(r.db.QueryRow(context.Background(),
+		`SELECT id, template_id, version, steps, parameters, created_at FROM pipeline_template_versions
+		WHERE template_id=$1 AND ($2 = 0 OR version=$2) ORDER BY version DESC LIMIT 1`,
+		templateID, version,
+	))
+}
+
+func (r *taskRepository) GetTemplateVersions(templateID int) ([]domain.PipelineTemplateVersion, error) {
+	rows, err := r.db.Query(context.Background(),
+		"SELECT id, template_id, version, steps, parameters, created_at FROM pipeline_template_versions WHERE template_id=$1 ORDER BY version",
+		templateID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []domain.PipelineTemplateVersion
+	for rows.Next() {
+		v, err := scanTemplateVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *v)
+	}
+	return versions, rows.Err()
+}
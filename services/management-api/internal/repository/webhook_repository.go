@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"management-api/internal/domain"
+)
+
+// CreateWebhookDelivery ghi lại một lần gửi webhook
+func (r *taskRepository) CreateWebhookDelivery(d *domain.WebhookDelivery) error {
+	return r.db.QueryRow(context.Background(),
+		`INSERT INTO webhook_deliveries (task_id, url, event, attempt, status_code, error, success)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`,
+		d.TaskID, d.URL, d.Event, d.Attempt, d.StatusCode, d.Error, d.Success,
+	).Scan(&d.ID, &d.CreatedAt)
+}
+
+// GetTaskDeliveries lấy lịch sử gửi webhook của task theo thứ tự thời gian
+func (r *taskRepository) GetTaskDeliveries(taskID int) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.Query(context.Background(),
+		"SELECT id, task_id, url, event, attempt, status_code, error, success, created_at FROM webhook_deliveries WHERE task_id=$1 ORDER BY id",
+		taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.TaskID, &d.URL, &d.Event, &d.Attempt, &d.StatusCode, &d.Error, &d.Success, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
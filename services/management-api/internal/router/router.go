@@ -1,15 +1,21 @@
 package router
 
 import (
+	"crypto/subtle"
+	"expvar"
+	"net/http"
+	"strings"
+
 	"management-api/internal/config"
 	"management-api/internal/handler"
+	"management-api/internal/openapi"
 	"management-api/internal/service"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(taskService service.TaskService, cfg *config.Config) *gin.Engine {
+func SetupRouter(taskService service.TaskService, batchService service.BatchService, pipelineService service.PipelineService, templateService service.TemplateService, galleryService service.GalleryService, artifactService service.ArtifactService, retentionService service.RetentionService, cfg *config.Config) *gin.Engine {
 	r := gin.Default()
 
 	corsConfig := cors.Config{
@@ -23,15 +29,37 @@ func SetupRouter(taskService service.TaskService, cfg *config.Config) *gin.Engin
 
 	r.Use(cors.New(corsConfig))
 
+	// Kiểm tra request theo tài liệu OpenAPI trước khi tới handler
+	spec := openapi.MustLoad()
+	r.Use(spec.Validator())
+
 	r.Static("/uploads", "./uploads")
-	r.Static("/images", "/shared/images")
-	r.Static("/shared", "/shared/images")
+	r.Static("/images", cfg.Retention.SharedImagePath)
+	r.Static("/shared", cfg.Retention.SharedImagePath)
+
+	taskHandler := handler.NewTaskHandler(taskService, cfg)
+	taskV1Handler := handler.NewTaskV1Handler(taskService, cfg)
+	batchHandler := handler.NewBatchHandler(batchService, cfg)
+	pipelineHandler := handler.NewPipelineHandler(pipelineService, cfg)
+	templateHandler := handler.NewTemplateHandler(templateService, cfg)
+	galleryHandler := handler.NewGalleryHandler(galleryService, cfg)
+	artifactHandler := handler.NewArtifactHandler(artifactService)
+	retentionHandler := handler.NewRetentionHandler(retentionService)
+
+	// Tài liệu API
+	r.GET("/openapi.json", spec.ServeJSON)
+	r.GET("/docs", spec.ServeDocs)
 
-	taskHandler := handler.NewTaskHandler(taskService)
+	// Số liệu nội bộ (expvar), ví dụ số task bị reaper xử lý; chứa cmdline và memstats nên cần ADMIN_TOKEN
+	admin := adminAuth(cfg.Server.AdminToken)
+	r.GET("/debug/vars", admin, gin.WrapH(expvar.Handler()))
 
 	// Endpoint nhiệm vụ
 	r.GET("/tasks/:id", taskHandler.GetTaskStatus)
 	r.GET("/tasks", taskHandler.GetAllTasks)
+	r.GET("/tasks/:id/deliveries", taskHandler.GetTaskDeliveries)
+	r.POST("/tasks/:id/cancel", taskHandler.CancelTask)
+	r.POST("/tasks/:id/retry", taskHandler.RetryTask)
 
 	// Các endpoint tương ứng với từng service
 	r.POST("/tts", taskHandler.HandleTextToVoice)
@@ -39,9 +67,75 @@ func SetupRouter(taskService service.TaskService, cfg *config.Config) *gin.Engin
 	r.POST("/remove-bg", taskHandler.HandleBackgroundRemoval)
 	r.POST("/speech-recognition", taskHandler.HandleSpeechRecognition)
 	r.POST("/face-recognition", taskHandler.HandleFaceRecognition)
+	r.POST("/face-verify", taskHandler.HandleFaceVerify)
+	r.POST("/face-anonymize", taskHandler.HandleFaceAnonymize)
 	r.POST("/ocr", taskHandler.HandleOCR)
 	r.POST("/translate", taskHandler.HandleTranslation)
 	r.POST("/upload-audio", taskHandler.UploadAudio)
 
+	// API /v1: mọi tool dùng chung một endpoint tạo task, mọi endpoint trả về task có output theo kiểu của tool.
+	// Các route ở trên được giữ lại cho frontend cho tới khi chuyển sang /v1.
+	v1 := r.Group("/v1")
+	v1.POST("/tools/:tool/tasks", taskV1Handler.CreateTask)
+	v1.GET("/tasks", taskV1Handler.ListTasks)
+	v1.GET("/tasks/:id", taskV1Handler.GetTask)
+	v1.POST("/tasks/:id/cancel", taskV1Handler.CancelTask)
+	v1.POST("/tasks/:id/retry", taskV1Handler.RetryTask)
+	v1.GET("/tasks/:id/deliveries", taskV1Handler.GetTaskDeliveries)
+
+	// Endpoint batch: chạy một tool trên nhiều input
+	r.POST("/batches", batchHandler.CreateBatch)
+	r.GET("/batches/:id", batchHandler.GetBatch)
+	r.GET("/batches/:id/results", batchHandler.GetBatchResults)
+
+	// Endpoint pipeline: chạy nhiều tool nối tiếp nhau
+	r.POST("/pipelines", pipelineHandler.CreatePipeline)
+	r.GET("/pipelines/:id", pipelineHandler.GetPipeline)
+
+	// Endpoint pipeline template: lưu pipeline theo tên, mỗi lần sửa tạo phiên bản mới
+	r.POST("/pipeline-templates", templateHandler.CreateTemplate)
+	r.GET("/pipeline-templates", templateHandler.GetAllTemplates)
+	r.GET("/pipeline-templates/:name", templateHandler.GetTemplate)
+	r.PUT("/pipeline-templates/:name", templateHandler.UpdateTemplate)
+	r.DELETE("/pipeline-templates/:name", templateHandler.DeleteTemplate)
+	r.GET("/pipeline-templates/:name/versions", templateHandler.GetTemplateVersions)
+	r.GET("/pipeline-templates/:name/versions/:version", templateHandler.GetTemplateVersion)
+	r.POST("/pipeline-templates/:name/run", templateHandler.RunTemplate)
+
+	// Endpoint gallery khuôn mặt: đăng ký khuôn mặt theo person_id và tìm người trong ảnh
+	r.POST("/face-galleries", galleryHandler.CreateGallery)
+	r.GET("/face-galleries", galleryHandler.GetAllGalleries)
+	r.GET("/face-galleries/:name", galleryHandler.GetGallery)
+	r.DELETE("/face-galleries/:name", galleryHandler.DeleteGallery)
+	r.POST("/face-galleries/:name/enrollments", galleryHandler.Enroll)
+	r.GET("/face-galleries/:name/enrollments", galleryHandler.GetEnrollments)
+	r.DELETE("/face-galleries/:name/enrollments/:id", galleryHandler.DeleteEnrollment)
+	r.DELETE("/face-galleries/:name/persons/:person_id", galleryHandler.DeletePerson)
+	r.POST("/face-galleries/:name/search", galleryHandler.Search)
+
+	// Endpoint artifact: file kết quả của task (ví dụ ảnh đã xoá nền) theo ID ổn định
+	r.GET("/artifacts/:id", artifactHandler.GetArtifact)
+	r.GET("/artifacts/:id/download", artifactHandler.DownloadArtifact)
+
+	// Endpoint cho người vận hành: báo cáo dry-run duyệt toàn bộ thư mục upload nên cần ADMIN_TOKEN
+	r.GET("/admin/retention/report", admin, retentionHandler.GetReport)
+
 	return r
 }
+
+// adminAuth là middleware của các endpoint cho người vận hành: request phải có header
+// "Authorization: Bearer <ADMIN_TOKEN>". Khi chưa cấu hình ADMIN_TOKEN mọi request bị từ chối.
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin endpoints are disabled, set ADMIN_TOKEN to enable them"})
+			return
+		}
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
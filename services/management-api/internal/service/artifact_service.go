@@ -0,0 +1,173 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"image"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/repository"
+
+	"github.com/jackc/pgx/v4"
+)
+
+var (
+	ErrArtifactNotFound = errors.New("artifact not found")
+	// ErrArtifactGone là lỗi khi artifact vẫn còn nhưng file đã bị janitor xoá
+	ErrArtifactGone = errors.New("artifact file no longer exists")
+)
+
+// artifactIDPattern là dạng ID do newArtifactID tạo ra
+var artifactIDPattern = regexp.MustCompile(`^[0-9a-f]{24}$`)
+
+// backendSharedPath là thư mục ảnh dùng chung bên trong container của các service Python
+const backendSharedPath = "/shared/images"
+
+type ArtifactService interface {
+	GetArtifact(id string) (*domain.Artifact, error)
+	// ArtifactFile trả về artifact cùng đường dẫn file trên server
+	ArtifactFile(id string) (*domain.Artifact, string, error)
+}
+
+type artifactService struct {
+	repo repository.TaskRepository
+	// sharedImagePath là thư mục ảnh dùng chung chứa file của artifact
+	sharedImagePath string
+}
+
+func NewArtifactService(repo repository.TaskRepository, cfg *config.Config) ArtifactService {
+	return &artifactService{repo: repo, sharedImagePath: cfg.Retention.SharedImagePath}
+}
+
+// GetArtifact lấy artifact theo ID; ErrArtifactGone nếu file của artifact đã bị xoá
+func (s *artifactService) GetArtifact(id string) (*domain.Artifact, error) {
+	artifact, _, err := s.ArtifactFile(id)
+	return artifact, err
+}
+
+func (s *artifactService) ArtifactFile(id string) (*domain.Artifact, string, error) {
+	if !artifactIDPattern.MatchString(id) {
+		return nil, "", ErrArtifactNotFound
+	}
+	artifact, err := s.repo.GetArtifact(id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", ErrArtifactNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	path := filepath.Join(s.sharedImagePath, filepath.Clean("/"+artifact.Path))
+	if _, err := os.Stat(path); err != nil {
+		return nil, "", ErrArtifactGone
+	}
+	return artifact, path, nil
+}
+
+// recordArtifact ghi file kết quả của task thành artifact và thêm ID, đường dẫn tải vào output:
+// ảnh của remove-bg và face-anonymize, searchable PDF của OCR. Lỗi chỉ được ghi log vì output vẫn
+// còn đường dẫn file.
+func (s *taskService) recordArtifact(taskID int, output interface{}) {
+	switch result := output.(type) {
+	case *domain.BackgroundRemovalResult:
+		if result != nil && result.ProcessedImagePath != "" {
+			result.ArtifactID, result.URL = s.createArtifact(taskID, result.ProcessedImagePath)
+		}
+	case *domain.FaceAnonymizeResult:
+		if result != nil && result.ImagePath != "" {
+			result.ArtifactID, result.ImageURL = s.createArtifact(taskID, result.ImagePath)
+		}
+	case *domain.OCRResult:
+		if result != nil && result.SearchablePDF != "" {
+			result.SearchablePDFArtifactID, result.SearchablePDFURL = s.createArtifact(taskID, result.SearchablePDF)
+		}
+	}
+}
+
+// createArtifact ghi file path (tương đối với thư mục ảnh dùng chung) thành artifact của task và trả về
+// ID, đường dẫn tải của artifact; rỗng nếu không ghi được
+func (s *taskService) createArtifact(taskID int, path string) (string, string) {
+	artifact, err := describeArtifact(s.sharedImagePath, path)
+	if err == nil {
+		artifact.TaskID = taskID
+		err = s.repo.CreateArtifact(artifact)
+	}
+	if err != nil {
+		log.Printf("recordArtifact: Cannot record artifact of task %d. Error: %v", taskID, err)
+		return "", ""
+	}
+	return artifact.ID, artifact.URL
+}
+
+// describeArtifact tạo artifact với ID mới cho file path (tương đối với sharedImagePath):
+// content type được nhận diện theo nội dung file, kích thước ảnh đọc từ header ảnh
+// (các định dạng được package imaging đăng ký: PNG, JPEG, GIF, WebP).
+func describeArtifact(sharedImagePath, path string) (*domain.Artifact, error) {
+	id, err := newArtifactID()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(sharedImagePath, filepath.Clean("/"+path)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	artifact := &domain.Artifact{
+		ID:          id,
+		Path:        path,
+		ContentType: http.DetectContentType(header[:n]),
+		Size:        info.Size(),
+		URL:         domain.ArtifactURL(id),
+	}
+	if strings.HasPrefix(artifact.ContentType, "image/") {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if cfg, _, err := image.DecodeConfig(f); err == nil {
+			artifact.Width, artifact.Height = cfg.Width, cfg.Height
+		}
+	}
+	return artifact, nil
+}
+
+func newArtifactID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// sharedRelativePath chuyển đường dẫn file do service trả về thành đường dẫn tương đối trong thư mục
+// ảnh dùng chung. Service có thể trả về đường dẫn tuyệt đối trong container (/shared/images/...)
+// hoặc đã là đường dẫn tương đối.
+func sharedRelativePath(sharedImagePath, path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	for _, dir := range []string{sharedImagePath, backendSharedPath} {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return rel
+		}
+	}
+	return strings.TrimLeft(path, "/")
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"management-api/internal/domain"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// BackendError là lỗi do service AI phía sau trả về, hoặc lỗi khi gọi service đó.
+// StatusCode là status HTTP của service (0 nếu không gọi được service).
+type BackendError struct {
+	Service    string
+	StatusCode int
+	Message    string
+}
+
+func (e *BackendError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s service unavailable: %s", e.Service, e.Message)
+	}
+	return fmt.Sprintf("%s service error (status %d): %s", e.Service, e.StatusCode, e.Message)
+}
+
+// Response của từng service, đúng theo dạng JSON mà service trả về
+
+type textToVoiceResponse struct {
+	AudioURL    string              `json:"audio_url"`
+	Marks       []domain.SpeechMark `json:"marks"`
+	MarksSource string              `json:"marks_source"`
+}
+
+type voiceToTextResponse struct {
+	Text string `json:"text"`
+}
+
+type backgroundRemovalResponse struct {
+	ProcessedImagePath string `json:"processed_image_path"`
+}
+
+type speechRecognitionResponse struct {
+	Text string `json:"text"`
+}
+
+// faceRecognitionResponse: face_locations là danh sách [top, right, bottom, left] theo pixel
+type faceRecognitionResponse struct {
+	FaceCount     int      `json:"face_count"`
+	FaceLocations [][4]int `json:"face_locations"`
+}
+
+// encodeFaceResponse: location là [top, right, bottom, left] theo pixel, encoding là embedding 128 chiều
+type encodeFaceResponse struct {
+	Faces []struct {
+		Location [4]int    `json:"location"`
+		Encoding []float64 `json:"encoding"`
+	} `json:"faces"`
+}
+
+// ocrResponse: orientation và blocks chỉ có với output json, hocr và tsv với output tương ứng;
+// pdf (base64) chỉ có khi request yêu cầu searchable PDF
+type ocrResponse struct {
+	Text        string                 `json:"text"`
+	Languages   string                 `json:"languages"`
+	Orientation *domain.OCROrientation `json:"orientation"`
+	Blocks      []domain.OCRBlock      `json:"blocks"`
+	HOCR        string                 `json:"hocr"`
+	TSV         string                 `json:"tsv"`
+	PDF         []byte                 `json:"pdf"`
+}
+
+type translationResponse struct {
+	TranslatedText string `json:"translated_text"`
+}
+
+// decodeBackendResponse đọc response của service vào out. Lỗi khi gọi service, status khác 200
+// và body có trường "error" (một số service trả lỗi với status 200) đều trả về *BackendError.
+func decodeBackendResponse(service string, resp *resty.Response, err error, out interface{}) error {
+	if err != nil {
+		return &BackendError{Service: service, Message: err.Error()}
+	}
+
+	body := resp.Body()
+	if message, ok := errorEnvelope(body); ok {
+		return &BackendError{Service: service, StatusCode: resp.StatusCode(), Message: message}
+	}
+	if resp.StatusCode() != http.StatusOK {
+		message := strings.TrimSpace(string(body))
+		if message == "" || len(message) > 200 {
+			message = http.StatusText(resp.StatusCode())
+		}
+		return &BackendError{Service: service, StatusCode: resp.StatusCode(), Message: message}
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return &BackendError{Service: service, StatusCode: resp.StatusCode(), Message: fmt.Sprintf("invalid response: %v", err)}
+	}
+	return nil
+}
+
+// errorEnvelope trả về thông báo lỗi nếu body có dạng {"error": "..."} hoặc {"error": {"message": "..."}}
+func errorEnvelope(body []byte) (string, bool) {
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || len(envelope.Error) == 0 || string(envelope.Error) == "null" {
+		return "", false
+	}
+
+	var message string
+	if err := json.Unmarshal(envelope.Error, &message); err == nil {
+		return message, message != ""
+	}
+	var detail struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(envelope.Error, &detail); err == nil && detail.Message != "" {
+		return detail.Message, true
+	}
+	return string(envelope.Error), true
+}
+
+// failureOutput là output_data của task failed. Lỗi từ service phía sau được ghi thêm
+// tên service và status HTTP của service.
+func failureOutput(err error) map[string]interface{} {
+	output := map[string]interface{}{"error": err.Error()}
+	var backendErr *BackendError
+	if errors.As(err, &backendErr) {
+		output["reason"] = domain.FailureReasonBackendError
+		output["service"] = backendErr.Service
+		if backendErr.StatusCode != 0 {
+			output["backend_status"] = backendErr.StatusCode
+		}
+	}
+	if errors.Is(err, ErrNoFace) {
+		output["reason"] = domain.FailureReasonNoFace
+	}
+	return output
+}
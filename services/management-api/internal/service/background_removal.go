@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"management-api/internal/domain"
+	"management-api/internal/imaging"
+)
+
+// runBackgroundRemoval xoá nền ảnh bằng service background-removal (kèm alpha matting nếu input yêu cầu).
+// Service chỉ trả về PNG đã xoá nền, nên nền thay thế, crop, mask_only và định dạng webp được xử lý
+// trong management-api; khi đó ảnh trung gian của service bị xoá và processed_image_path là ảnh mới.
+func (s *taskService) runBackgroundRemoval(ctx context.Context, input domain.ToolInput) (*domain.BackgroundRemovalResult, error) {
+	result, err := s.HandleBackgroundRemoval(ctx, input.FilePath, input.AlphaMatting)
+	if err != nil {
+		return nil, err
+	}
+	if input.BackgroundColor == "" && input.BackgroundFilePath == "" && !input.Crop && !input.MaskOnly && input.Format != domain.ImageFormatWebP {
+		return result, nil
+	}
+
+	opts := imaging.BackgroundOptions{
+		ImagePath: input.BackgroundFilePath,
+		Crop:      input.Crop,
+		Padding:   input.CropPadding,
+		MaskOnly:  input.MaskOnly,
+	}
+	if input.BackgroundColor != "" {
+		c, err := domain.ParseHexColor(input.BackgroundColor)
+		if err != nil {
+			return nil, err
+		}
+		opts.Color = &c
+	}
+
+	processed := filepath.Join(s.sharedImagePath, filepath.Clean("/"+result.ProcessedImagePath))
+	path, err := s.sharedPath(fmt.Sprintf("nobg_%d.png", time.Now().UnixNano()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save processed image: %v", err)
+	}
+	out := filepath.Join(s.sharedImagePath, path)
+	width, height, box, err := imaging.Background(processed, out, opts)
+	if err != nil {
+		os.Remove(out)
+		return nil, fmt.Errorf("background post-processing failed: %v", err)
+	}
+
+	format := domain.ImageFormatPNG
+	if input.Format == domain.ImageFormatWebP {
+		webpPath := strings.TrimSuffix(path, ".png") + ".webp"
+		err := imaging.EncodeWebP(ctx, out, filepath.Join(s.sharedImagePath, webpPath))
+		os.Remove(out)
+		if err != nil {
+			return nil, err
+		}
+		path, format = webpPath, domain.ImageFormatWebP
+	}
+	if err := os.Remove(processed); err != nil {
+		log.Printf("runBackgroundRemoval: Cannot remove intermediate image %s. Error: %v", processed, err)
+	}
+
+	return &domain.BackgroundRemovalResult{
+		ProcessedImagePath: path,
+		Format:             format,
+		Width:              width,
+		Height:             height,
+		SubjectBox:         box,
+	}, nil
+}
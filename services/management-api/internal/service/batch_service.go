@@ -0,0 +1,271 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/repository"
+)
+
+// Định dạng tải kết quả batch
+const (
+	BatchResultsJSONL = "jsonl"
+	BatchResultsZip   = "zip"
+)
+
+type BatchService interface {
+	CreateBatch(tool string, inputs []domain.ToolInput) (*domain.Batch, error)
+	GetBatch(id int) (*domain.Batch, error)
+	GetBatchTasks(id int) ([]domain.Task, error)
+	ExportResults(id int, format string, w io.Writer) error
+}
+
+type batchService struct {
+	repo        repository.TaskRepository
+	tasks       TaskService
+	uploads     config.UploadConfig
+	concurrency int
+	// sharedImagePath là thư mục ảnh dùng chung chứa file artifact của các task con
+	sharedImagePath string
+}
+
+func NewBatchService(repo repository.TaskRepository, tasks TaskService, cfg *config.Config) BatchService {
+	concurrency := cfg.Batch.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &batchService{
+		repo:        repo,
+		tasks:       tasks,
+		uploads:     cfg.Uploads,
+		concurrency: concurrency,
+
+		sharedImagePath: cfg.Retention.SharedImagePath,
+	}
+}
+
+// batchResult là một dòng trong file kết quả JSONL
+type batchResult struct {
+	TaskID int               `json:"task_id"`
+	Index  int               `json:"index"`
+	Status domain.TaskStatus `json:"status"`
+	Input  json.RawMessage   `json:"input"`
+	Output json.RawMessage   `json:"output"`
+}
+
+// CreateBatch tạo batch và các task con, sau đó xử lý chúng ở background.
+// Input có tuỳ chọn không hợp lệ bị từ chối với ErrInvalidToolInput trước khi tạo batch.
+func (s *batchService) CreateBatch(tool string, inputs []domain.ToolInput) (*domain.Batch, error) {
+	if !domain.IsValidTool(tool) {
+		return nil, fmt.Errorf("%w: unknown tool '%s'", ErrInvalidToolInput, tool)
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: batch has no inputs", ErrInvalidToolInput)
+	}
+	for i, input := range inputs {
+		if err := validateToolInput(tool, input); err != nil {
+			return nil, fmt.Errorf("%w: input %d: %v", ErrInvalidToolInput, i, err)
+		}
+	}
+
+	batchID, err := s.repo.CreateBatch(tool)
+	if err != nil {
+		return nil, err
+	}
+
+	taskIDs := make([]int, len(inputs))
+	for i, input := range inputs {
+		taskIDs[i], err = s.repo.CreateTask(tool, domain.TaskStatusQueued, input, &batchID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("CreateBatch: Created batch %d with %d '%s' tasks", batchID, len(inputs), tool)
+
+	go s.process(batchID, tool, taskIDs, inputs)
+
+	return s.repo.GetBatch(batchID)
+}
+
+// process chạy các task con với số lượng đồng thời giới hạn bởi concurrency
+func (s *batchService) process(batchID int, tool string, taskIDs []int, inputs []domain.ToolInput) {
+	if err := s.repo.UpdateBatchStatus(batchID, "processing"); err != nil {
+		log.Printf("process: Failed to update batch %d. Error: %v", batchID, err)
+	}
+
+	sem := make(chan struct{}, s.concurrency)
+	var wg sync.WaitGroup
+	for i := range taskIDs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(taskID int, input domain.ToolInput) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.runTask(batchID, tool, taskID, input)
+		}(taskIDs[i], inputs[i])
+	}
+	wg.Wait()
+
+	status := "completed"
+	if batch, err := s.repo.GetBatch(batchID); err != nil {
+		log.Printf("process: Failed to get batch %d. Error: %v", batchID, err)
+	} else {
+		status = batchFinalStatus(batch.Progress)
+	}
+	if err := s.repo.UpdateBatchStatus(batchID, status); err != nil {
+		log.Printf("process: Failed to update batch %d. Error: %v", batchID, err)
+	}
+	log.Printf("process: Batch %d finished with status %s", batchID, status)
+}
+
+// batchFinalStatus là trạng thái của batch khi mọi task con đã kết thúc: completed nếu mọi task con
+// completed, failed nếu không task con nào completed, còn lại là partial
+func batchFinalStatus(p domain.BatchProgress) string {
+	switch {
+	case p.Completed == p.Total:
+		return "completed"
+	case p.Completed == 0:
+		return "failed"
+	default:
+		return "partial"
+	}
+}
+
+// runTask chạy một task con và ghi kết quả hoặc lỗi vào task
+func (s *batchService) runTask(batchID int, tool string, taskID int, input domain.ToolInput) {
+	// Task đã bị huỷ khi còn trong hàng đợi thì không chạy nữa
+	if err := s.repo.UpdateTask(taskID, domain.TaskStatusProcessing, nil); err != nil {
+		log.Printf("runTask: Skipping task %d of batch %d. Error: %v", taskID, batchID, err)
+		return
+	}
+
+	output, err := s.runTool(batchID, tool, taskID, input)
+	if errors.Is(err, ErrTaskCancelled) {
+		log.Printf("runTask: Task %d of batch %d was cancelled", taskID, batchID)
+		return
+	}
+	if err != nil {
+		log.Printf("runTask: Task %d of batch %d failed. Error: %v", taskID, batchID, err)
+		if err := s.repo.UpdateTask(taskID, domain.TaskStatusFailed, failureOutput(err)); err != nil {
+			log.Printf("runTask: Failed to update task %d. Error: %v", taskID, err)
+		}
+		return
+	}
+
+	if err := s.repo.UpdateTask(taskID, domain.TaskStatusCompleted, output); err != nil {
+		log.Printf("runTask: Failed to update task %d. Error: %v", taskID, err)
+	}
+}
+
+func (s *batchService) runTool(batchID int, tool string, taskID int, input domain.ToolInput) (interface{}, error) {
+	input, err := resolveToolInput(tool, input, s.batchDir(s.uploads.ImagePath, batchID), s.uploads)
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks.RunTask(taskID, tool, input)
+}
+
+func (s *batchService) batchDir(base string, batchID int) string {
+	return filepath.Join(base, "batches", fmt.Sprintf("%d", batchID))
+}
+
+func (s *batchService) GetBatch(id int) (*domain.Batch, error) {
+	return s.repo.GetBatch(id)
+}
+
+func (s *batchService) GetBatchTasks(id int) ([]domain.Task, error) {
+	return s.repo.GetBatchTasks(id)
+}
+
+// ExportResults ghi kết quả của tất cả task con ra w dưới dạng JSONL hoặc zip.
+// File zip gồm results.jsonl và các file artifact của task con nếu có.
+func (s *batchService) ExportResults(id int, format string, w io.Writer) error {
+	if _, err := s.repo.GetBatch(id); err != nil {
+		return err
+	}
+	tasks, err := s.repo.GetBatchTasks(id)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case BatchResultsJSONL:
+		return writeBatchResults(tasks, w)
+	case BatchResultsZip:
+		zw := zip.NewWriter(w)
+		f, err := zw.Create("results.jsonl")
+		if err != nil {
+			return err
+		}
+		if err := writeBatchResults(tasks, f); err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if err := s.addArtifacts(zw, task); err != nil {
+				log.Printf("ExportResults: Skipping artifacts of task %d. Error: %v", task.ID, err)
+			}
+		}
+		return zw.Close()
+	default:
+		return fmt.Errorf("unsupported format '%s'", format)
+	}
+}
+
+func writeBatchResults(tasks []domain.Task, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for i, task := range tasks {
+		err := enc.Encode(batchResult{
+			TaskID: task.ID,
+			Index:  i,
+			Status: task.Status,
+			Input:  task.InputData,
+			Output: task.OutputData,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addArtifacts thêm các file artifact của task đã completed (ảnh đã xoá nền, mask, ảnh đã che
+// khuôn mặt, searchable PDF...) vào file zip với tên "task_<id>_<tên file>"
+func (s *batchService) addArtifacts(zw *zip.Writer, task domain.Task) error {
+	if task.Status != domain.TaskStatusCompleted {
+		return nil
+	}
+	artifacts, err := s.repo.GetTaskArtifacts(task.ID)
+	if err != nil {
+		return err
+	}
+	for _, artifact := range artifacts {
+		if err := s.addArtifact(zw, task.ID, artifact.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *batchService) addArtifact(zw *zip.Writer, taskID int, path string) error {
+	src, err := os.Open(filepath.Join(s.sharedImagePath, filepath.Clean("/"+path)))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.Create(fmt.Sprintf("task_%d_%s", taskID, filepath.Base(path)))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
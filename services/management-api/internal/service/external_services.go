@@ -1,10 +1,13 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"net/http"
+	"os"
+
+	"management-api/internal/domain"
+	"management-api/pkg/utils"
 )
 
 // // HandleTextToVoice xử lý dịch vụ Text-to-Voice
@@ -31,7 +34,7 @@ import (
 //	}
 //
 // HandleTextToVoice xử lý dịch vụ Text-to-Voice
-func (s *taskService) HandleTextToVoice(text, language string) (map[string]string, error) {
+func (s *taskService) HandleTextToVoice(ctx context.Context, text, language string) (*domain.TextToVoiceResult, error) {
 	log.Printf("HandleTextToVoice: Received request with text '%s' and language '%s'", text, language)
 
 	if language == "" {
@@ -39,139 +42,168 @@ func (s *taskService) HandleTextToVoice(text, language string) (map[string]strin
 		log.Printf("HandleTextToVoice: Defaulting language to '%s'", language)
 	}
 
-	resp, err := s.client.R().
+	resp, err := s.client.R().SetContext(ctx).
 		SetHeader("Content-Type", "application/json").
 		SetBody(map[string]string{"text": text, "language": language}).
-		Post("http://text_to_voice_service:5001/convert")
-	if err != nil || resp.StatusCode() != 200 {
-		log.Printf("HandleTextToVoice: Error calling service. StatusCode: %d, Error: %v", resp.StatusCode(), err)
-		return nil, fmt.Errorf("failed to call Text-to-Voice service")
-	}
-
-	var ttsResp map[string]string
-	if err := json.Unmarshal(resp.Body(), &ttsResp); err != nil {
-		log.Printf("HandleTextToVoice: Error parsing response. Error: %v", err)
-		return nil, fmt.Errorf("failed to parse Text-to-Voice response")
+		Post(s.backends.TextToVoiceURL + "/convert")
+	var ttsResp textToVoiceResponse
+	if err := decodeBackendResponse("Text-to-Voice", resp, err, &ttsResp); err != nil {
+		log.Printf("HandleTextToVoice: Error calling service. Error: %v", err)
+		return nil, err
 	}
 
-	log.Printf("HandleTextToVoice: Successfully converted text to voice")
-	return ttsResp, nil
+	log.Printf("HandleTextToVoice: Successfully converted text to voice with %d marks", len(ttsResp.Marks))
+	return &domain.TextToVoiceResult{AudioURL: ttsResp.AudioURL, Marks: ttsResp.Marks, MarksSource: ttsResp.MarksSource}, nil
 }
 
-// HandleVoiceToText xử lý dịch vụ Voice-to-Text
-func (s *taskService) HandleVoiceToText(audioURL string) (map[string]string, error) {
-	resp, err := s.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(map[string]string{"audio_url": audioURL}).
-		Post("http://voice_to_text_service:5002/convert")
-	if err != nil || resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("failed to call Voice-to-Text service")
+// HandleVoiceToText xử lý dịch vụ Voice-to-Text. Service nhận file audio dạng multipart (trường "audio"),
+// nên audio ở URL http(s) được tải về trước khi gửi; audio_url dạng đường dẫn phải nằm trong thư mục upload.
+func (s *taskService) HandleVoiceToText(ctx context.Context, audioURL string) (*domain.TextResult, error) {
+	if err := checkAudioURL(audioURL, s.uploads); err != nil {
+		return nil, err
+	}
+	audioPath := audioURL
+	if isHTTPURL(audioURL) {
+		downloaded, err := utils.DownloadFile(audioURL, os.TempDir())
+		if err != nil {
+			return nil, fmt.Errorf("failed to download audio: %v", err)
+		}
+		defer os.Remove(downloaded)
+		audioPath = downloaded
 	}
 
-	var vtsResp map[string]string
-	if err := json.Unmarshal(resp.Body(), &vtsResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Voice-to-Text response")
+	resp, err := s.client.R().SetContext(ctx).
+		SetFile("audio", audioPath).
+		Post(s.backends.VoiceToTextURL + "/convert")
+	var vtsResp voiceToTextResponse
+	if err := decodeBackendResponse("Voice-to-Text", resp, err, &vtsResp); err != nil {
+		return nil, err
 	}
 
-	return vtsResp, nil
+	return &domain.TextResult{Text: vtsResp.Text}, nil
 }
 
-func (s *taskService) HandleBackgroundRemoval(imagePath string) (string, error) {
+// HandleBackgroundRemoval xử lý dịch vụ Background Removal; alphaMatting yêu cầu service tinh chỉnh viền chủ thể
+func (s *taskService) HandleBackgroundRemoval(ctx context.Context, imagePath string, alphaMatting bool) (*domain.BackgroundRemovalResult, error) {
 	log.Printf("HandleBackgroundRemoval: Received request with image path '%s'", imagePath)
 
-	resp, err := s.client.R().
-		SetFile("image", imagePath).
-		Post("http://background_removal_service:5003/remove-bg")
-	if err != nil {
-		log.Printf("HandleBackgroundRemoval: Failed to call Background Removal service. Error: %v", err)
-		return "", fmt.Errorf("failed to call Background Removal service")
-	}
-
-	if resp.StatusCode() != http.StatusOK {
-		log.Printf("HandleBackgroundRemoval: Service returned non-200 status. StatusCode: %d, Response: %s", resp.StatusCode(), resp.String())
-		return "", fmt.Errorf("failed to call Background Removal service with StatusCode: %d", resp.StatusCode())
+	req := s.client.R().SetContext(ctx).SetFile("image", imagePath)
+	if alphaMatting {
+		req.SetFormData(map[string]string{"alpha_matting": "true"})
 	}
-
-	var brResp struct {
-		ProcessedImagePath string `json:"processed_image_path"`
-	}
-	if err := json.Unmarshal(resp.Body(), &brResp); err != nil {
-		log.Printf("HandleBackgroundRemoval: Failed to parse service response. Error: %v, Raw Response: %s", err, resp.String())
-		return "", fmt.Errorf("failed to parse Background Removal response")
+	resp, err := req.Post(s.backends.BackgroundRemovalURL + "/remove-bg")
+	var brResp backgroundRemovalResponse
+	if err := decodeBackendResponse("Background Removal", resp, err, &brResp); err != nil {
+		log.Printf("HandleBackgroundRemoval: Failed to call Background Removal service. Error: %v", err)
+		return nil, err
 	}
 
 	log.Printf("HandleBackgroundRemoval: Successfully processed background removal for image '%s'", imagePath)
-	return brResp.ProcessedImagePath, nil
+	return &domain.BackgroundRemovalResult{ProcessedImagePath: sharedRelativePath(s.sharedImagePath, brResp.ProcessedImagePath)}, nil
 }
 
 // HandleSpeechRecognition xử lý dịch vụ Speech Recognition
-func (s *taskService) HandleSpeechRecognition(audioURL string) (map[string]string, error) {
-	resp, err := s.client.R().
+func (s *taskService) HandleSpeechRecognition(ctx context.Context, audioURL string) (*domain.TextResult, error) {
+	resp, err := s.client.R().SetContext(ctx).
 		SetHeader("Content-Type", "application/json").
 		SetBody(map[string]string{"audio_url": audioURL}).
-		Post("http://speech_recognition_service:5004/recognize")
-	if err != nil || resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("failed to call Speech Recognition service")
+		Post(s.backends.SpeechRecognitionURL + "/recognize")
+	var srResp speechRecognitionResponse
+	if err := decodeBackendResponse("Speech Recognition", resp, err, &srResp); err != nil {
+		return nil, err
 	}
 
-	var srResp map[string]string
-	if err := json.Unmarshal(resp.Body(), &srResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Speech Recognition response")
-	}
-
-	return srResp, nil
+	return &domain.TextResult{Text: srResp.Text}, nil
 }
 
 // HandleFaceRecognition xử lý dịch vụ Face Recognition
-func (s *taskService) HandleFaceRecognition(imagePath string) (map[string]interface{}, error) {
-	resp, err := s.client.R().
+func (s *taskService) HandleFaceRecognition(ctx context.Context, imagePath string) (*domain.FaceRecognitionResult, error) {
+	resp, err := s.client.R().SetContext(ctx).
 		SetFile("image", imagePath).
-		Post("http://face_recognition_service:5005/recognize-face")
-	if err != nil || resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("failed to call Face Recognition service")
-	}
+		Post(s.backends.FaceRecognitionURL + "/recognize-face")
+	var frResp faceRecognitionResponse
+	if err := decodeBackendResponse("Face Recognition", resp, err, &frResp); err != nil {
+		return nil, err
+	}
+
+	result := &domain.FaceRecognitionResult{FaceCount: frResp.FaceCount}
+	for _, location := range frResp.FaceLocations {
+		result.Faces = append(result.Faces, domain.FaceBox{
+			Top:    location[0],
+			Right:  location[1],
+			Bottom: location[2],
+			Left:   location[3],
+		})
+	}
+	return result, nil
+}
 
-	var frResp map[string]interface{}
-	if err := json.Unmarshal(resp.Body(), &frResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Face Recognition response")
+// EncodeFaces gọi service Face Recognition để lấy vị trí và embedding của mọi khuôn mặt trong ảnh
+func (s *taskService) EncodeFaces(ctx context.Context, imagePath string) ([]domain.FaceEncoding, error) {
+	resp, err := s.client.R().SetContext(ctx).
+		SetFile("image", imagePath).
+		Post(s.backends.FaceRecognitionURL + "/encode-face")
+	var efResp encodeFaceResponse
+	if err := decodeBackendResponse("Face Recognition", resp, err, &efResp); err != nil {
+		return nil, err
 	}
 
-	return frResp, nil
+	faces := make([]domain.FaceEncoding, len(efResp.Faces))
+	for i, face := range efResp.Faces {
+		faces[i] = domain.FaceEncoding{
+			Face:      domain.FaceBox{Top: face.Location[0], Right: face.Location[1], Bottom: face.Location[2], Left: face.Location[3]},
+			Embedding: face.Encoding,
+		}
+	}
+	return faces, nil
 }
 
-// HandleOCR xử lý dịch vụ OCR
-func (s *taskService) HandleOCR(imagePath string) (map[string]string, error) {
-	resp, err := s.client.R().
-		SetFile("image", imagePath).
-		Post("http://ocr_service:5006/ocr")
-	if err != nil || resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("failed to call OCR service")
+// HandleOCR xử lý dịch vụ OCR cho một ảnh. languages (ví dụ "vie+eng") và output (text, hocr, json, tsv)
+// rỗng thì dùng mặc định của service. searchablePDF bằng true thì kết quả có thêm PDF một trang có lớp text.
+func (s *taskService) HandleOCR(ctx context.Context, imagePath, languages, output string, searchablePDF bool) (*domain.OCRResult, error) {
+	form := map[string]string{}
+	if searchablePDF {
+		form["pdf"] = "true"
 	}
-
-	var ocrResp map[string]string
-	if err := json.Unmarshal(resp.Body(), &ocrResp); err != nil {
-		return nil, fmt.Errorf("failed to parse OCR response")
+	if languages != "" {
+		form["languages"] = languages
+	}
+	if output != "" {
+		form["output"] = output
 	}
 
-	return ocrResp, nil
+	resp, err := s.client.R().SetContext(ctx).
+		SetFile("image", imagePath).
+		SetFormData(form).
+		Post(s.backends.OCRURL + "/ocr")
+	var ocrResp ocrResponse
+	if err := decodeBackendResponse("OCR", resp, err, &ocrResp); err != nil {
+		return nil, err
+	}
+
+	return &domain.OCRResult{
+		Text:        ocrResp.Text,
+		Languages:   ocrResp.Languages,
+		Orientation: ocrResp.Orientation,
+		Blocks:      ocrResp.Blocks,
+		HOCR:        ocrResp.HOCR,
+		TSV:         ocrResp.TSV,
+		PDF:         ocrResp.PDF,
+	}, nil
 }
 
 // HandleTranslation xử lý dịch vụ Translation
-func (s *taskService) HandleTranslation(text, destLang string) (map[string]string, error) {
-	resp, err := s.client.R().
+func (s *taskService) HandleTranslation(ctx context.Context, text, destLang string) (*domain.TranslationResult, error) {
+	resp, err := s.client.R().SetContext(ctx).
 		SetHeader("Content-Type", "application/json").
 		SetBody(map[string]string{"text": text, "dest_lang": destLang}).
-		Post("http://translation_service:5007/translate")
-	if err != nil || resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("failed to call Translation service")
-	}
-
-	var trResp map[string]string
-	if err := json.Unmarshal(resp.Body(), &trResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Translation response")
+		Post(s.backends.TranslationURL + "/translate")
+	var trResp translationResponse
+	if err := decodeBackendResponse("Translation", resp, err, &trResp); err != nil {
+		return nil, err
 	}
 
-	return trResp, nil
+	return &domain.TranslationResult{TranslatedText: trResp.TranslatedText}, nil
 }
 
 // UploadAudio xử lý tải lên file audio
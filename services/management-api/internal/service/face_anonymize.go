@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"management-api/internal/domain"
+	"management-api/internal/imaging"
+)
+
+// runFaceAnonymize tìm khuôn mặt trong ảnh (đã tiền xử lý nếu input yêu cầu) bằng service face-recognition,
+// che các khuôn mặt theo mode và strength rồi lưu ảnh kết quả vào thư mục ảnh dùng chung.
+// Ảnh JPEG được giữ dạng JPEG, các định dạng khác được ghi thành PNG.
+func (s *taskService) runFaceAnonymize(ctx context.Context, input domain.ToolInput) (*domain.FaceAnonymizeResult, error) {
+	mode := input.Mode
+	if mode == "" {
+		mode = domain.AnonymizeBlur
+	}
+	strength := input.Strength
+	if strength == 0 {
+		strength = domain.DefaultAnonymizeStrength
+	}
+
+	imagePath := toolFile(input)
+	faces, err := s.HandleFaceRecognition(ctx, imagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := ".png"
+	if e := strings.ToLower(filepath.Ext(imagePath)); e == ".jpg" || e == ".jpeg" {
+		ext = ".jpg"
+	}
+	path, err := s.sharedPath(fmt.Sprintf("anonymized_%d%s", time.Now().UnixNano(), ext))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save anonymized image: %v", err)
+	}
+	if err := imaging.Anonymize(imagePath, filepath.Join(s.sharedImagePath, path), faces.Faces, mode, strength); err != nil {
+		return nil, fmt.Errorf("face anonymization failed: %v", err)
+	}
+
+	result := &domain.FaceAnonymizeResult{
+		FaceCount: len(faces.Faces),
+		Faces:     faces.Faces,
+		Mode:      mode,
+		Strength:  strength,
+		ImagePath: path,
+	}
+	if result.Faces == nil {
+		result.Faces = []domain.FaceBox{}
+	}
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"management-api/internal/domain"
+	"management-api/internal/imaging"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// runFaceVerify so khớp 1:1 khuôn mặt lớn nhất của ảnh file_path với khuôn mặt lớn nhất của ảnh
+// reference_file_path, hoặc với enrollment giống nhất của person_id trong gallery.
+// Threshold bằng 0 thì dùng ngưỡng mặc định FACE_VERIFY_THRESHOLD.
+func (s *taskService) runFaceVerify(ctx context.Context, input domain.ToolInput) (*domain.FaceVerifyResult, error) {
+	threshold := input.Threshold
+	if threshold == 0 {
+		threshold = s.face.VerifyThreshold
+	}
+
+	probe, embedding, err := s.verifyFace(ctx, input.FilePath, "image")
+	if err != nil {
+		return nil, err
+	}
+	if probe.FaceCount > 1 && probe.Liveness != nil {
+		probe.Liveness.Hints = append(probe.Liveness.Hints, domain.LivenessMultipleFaces)
+		probe.Liveness.Suspicious = true
+	}
+	result := &domain.FaceVerifyResult{Threshold: threshold, Probe: *probe}
+
+	if input.Gallery != "" {
+		reference, similarity, err := s.enrolledFace(input.Gallery, input.PersonID, embedding)
+		if err != nil {
+			return nil, err
+		}
+		result.Reference = *reference
+		result.Similarity = similarity
+	} else {
+		reference, referenceEmbedding, err := s.verifyFace(ctx, input.ReferenceFilePath, "reference image")
+		if err != nil {
+			return nil, err
+		}
+		result.Reference = *reference
+		result.Similarity = domain.CosineSimilarity(embedding, referenceEmbedding)
+	}
+	result.Match = result.Similarity >= threshold
+	return result, nil
+}
+
+// verifyFace lấy khuôn mặt lớn nhất của ảnh kèm chất lượng và dấu hiệu liveness của khuôn mặt đó.
+// Ảnh mà service face-recognition đọc được nhưng Go không decode được thì bỏ qua quality và liveness.
+func (s *taskService) verifyFace(ctx context.Context, path, name string) (*domain.FaceVerifyFace, []float64, error) {
+	faces, err := s.EncodeFaces(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(faces) == 0 {
+		return nil, nil, fmt.Errorf("%w: no face found in %s", ErrNoFace, name)
+	}
+
+	largest := faces[0]
+	for _, face := range faces[1:] {
+		if faceArea(face.Face) > faceArea(largest.Face) {
+			largest = face
+		}
+	}
+	result := &domain.FaceVerifyFace{Face: largest.Face, FaceCount: len(faces)}
+	result.Quality, result.Liveness, err = imaging.AnalyzeFace(path, largest.Face)
+	if err != nil {
+		log.Printf("verifyFace: Cannot analyze face in %s. Error: %v", filepath.Base(path), err)
+	}
+	return result, largest.Embedding, nil
+}
+
+// enrolledFace tìm enrollment của personID trong gallery giống embedding nhất
+func (s *taskService) enrolledFace(galleryName, personID string, embedding []float64) (*domain.FaceVerifyFace, float64, error) {
+	gallery, err := s.repo.GetGalleryByName(galleryName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, fmt.Errorf("%w: gallery '%s' does not exist", ErrNoFace, galleryName)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	enrollments, err := s.repo.GetEnrollments(gallery.ID, personID)
+	if err != nil {
+		return nil, 0, err
+	}
+	matches := bestMatches(embedding, enrollments, 1, -1)
+	if len(matches) == 0 {
+		return nil, 0, fmt.Errorf("%w: person '%s' has no enrolled face in gallery '%s'", ErrNoFace, personID, galleryName)
+	}
+
+	match := matches[0]
+	reference := &domain.FaceVerifyFace{Gallery: gallery.Name, PersonID: personID, EnrollmentID: match.EnrollmentID}
+	for _, e := range enrollments {
+		if e.ID == match.EnrollmentID {
+			reference.Face = e.Face
+		}
+	}
+	return reference, match.Similarity, nil
+}
+
+func faceArea(face domain.FaceBox) int {
+	return (face.Right - face.Left) * (face.Bottom - face.Top)
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+
+	"management-api/internal/domain"
+	"management-api/internal/repository"
+
+	"github.com/jackc/pgx/v4"
+)
+
+var (
+	ErrGalleryNotFound    = errors.New("gallery not found")
+	ErrEnrollmentNotFound = errors.New("enrollment not found")
+	ErrInvalidGallery     = errors.New("invalid gallery request")
+)
+
+const (
+	// DefaultSearchTopK là số người trả về cho mỗi khuôn mặt khi không chỉ định top_k
+	DefaultSearchTopK = 5
+	// MaxSearchTopK là giới hạn của top_k
+	MaxSearchTopK = 100
+)
+
+// galleryNamePattern giới hạn tên gallery ở dạng slug, giống tên pipeline template
+var galleryNamePattern = templateNamePattern
+
+// personIDPattern giới hạn person_id ở các ký tự an toàn cho đường dẫn URL
+var personIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@-]{0,254}$`)
+
+type GalleryService interface {
+	CreateGallery(name, description string) (*domain.FaceGallery, error)
+	GetGallery(name string) (*domain.FaceGallery, error)
+	GetAllGalleries() ([]domain.FaceGallery, error)
+	DeleteGallery(name string) error
+	Enroll(ctx context.Context, gallery, personID string, metadata map[string]interface{}, imagePath string) (*domain.FaceEnrollment, error)
+	GetEnrollments(gallery, personID string) ([]domain.FaceEnrollment, error)
+	DeleteEnrollment(gallery string, id int) error
+	DeletePerson(gallery, personID string) (int, error)
+	Search(ctx context.Context, gallery, imagePath string, topK int, minSimilarity float64) (*domain.FaceSearchResult, error)
+}
+
+type galleryService struct {
+	repo  repository.TaskRepository
+	tasks TaskService
+}
+
+func NewGalleryService(repo repository.TaskRepository, tasks TaskService) GalleryService {
+	return &galleryService{repo: repo, tasks: tasks}
+}
+
+func (s *galleryService) CreateGallery(name, description string) (*domain.FaceGallery, error) {
+	if !galleryNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: name must match %s", ErrInvalidGallery, galleryNamePattern)
+	}
+	if _, err := s.repo.GetGalleryByName(name); err == nil {
+		return nil, fmt.Errorf("%w: gallery '%s' already exists", ErrInvalidGallery, name)
+	}
+
+	if _, err := s.repo.CreateGallery(name, description); err != nil {
+		return nil, err
+	}
+	log.Printf("CreateGallery: Created gallery '%s'", name)
+	return s.repo.GetGalleryByName(name)
+}
+
+func (s *galleryService) GetGallery(name string) (*domain.FaceGallery, error) {
+	gallery, err := s.repo.GetGalleryByName(name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrGalleryNotFound
+	}
+	return gallery, err
+}
+
+func (s *galleryService) GetAllGalleries() ([]domain.FaceGallery, error) {
+	return s.repo.GetAllGalleries()
+}
+
+// DeleteGallery xoá gallery cùng mọi enrollment của gallery
+func (s *galleryService) DeleteGallery(name string) error {
+	gallery, err := s.GetGallery(name)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteGallery(gallery.ID); err != nil {
+		return err
+	}
+	log.Printf("DeleteGallery: Deleted gallery '%s' with %d enrollments", name, gallery.EnrollmentCount)
+	return nil
+}
+
+// Enroll đăng ký khuôn mặt trong ảnh cho personID. Ảnh phải có đúng một khuôn mặt;
+// chỉ embedding và vị trí khuôn mặt được lưu, không lưu ảnh.
+func (s *galleryService) Enroll(ctx context.Context, galleryName, personID string, metadata map[string]interface{}, imagePath string) (*domain.FaceEnrollment, error) {
+	gallery, err := s.GetGallery(galleryName)
+	if err != nil {
+		return nil, err
+	}
+	if !personIDPattern.MatchString(personID) {
+		return nil, fmt.Errorf("%w: person_id must match %s", ErrInvalidGallery, personIDPattern)
+	}
+
+	faces, err := s.tasks.EncodeFaces(ctx, imagePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(faces) != 1 {
+		return nil, fmt.Errorf("%w: enrollment image must contain exactly one face, found %d", ErrInvalidGallery, len(faces))
+	}
+
+	enrollment := &domain.FaceEnrollment{
+		GalleryID: gallery.ID,
+		PersonID:  personID,
+		Metadata:  metadata,
+		Face:      faces[0].Face,
+		Embedding: faces[0].Embedding,
+	}
+	if err := s.repo.CreateEnrollment(enrollment); err != nil {
+		return nil, err
+	}
+	log.Printf("Enroll: Enrolled face %d of person '%s' in gallery '%s'", enrollment.ID, personID, galleryName)
+	return enrollment, nil
+}
+
+// GetEnrollments lấy các enrollment của gallery; personID khác rỗng thì chỉ lấy của người đó
+func (s *galleryService) GetEnrollments(galleryName, personID string) ([]domain.FaceEnrollment, error) {
+	gallery, err := s.GetGallery(galleryName)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetEnrollments(gallery.ID, personID)
+}
+
+func (s *galleryService) DeleteEnrollment(galleryName string, id int) error {
+	gallery, err := s.GetGallery(galleryName)
+	if err != nil {
+		return err
+	}
+	if id <= 0 {
+		return ErrEnrollmentNotFound
+	}
+	deleted, err := s.repo.DeleteEnrollments(gallery.ID, id, "")
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrEnrollmentNotFound
+	}
+	return nil
+}
+
+// DeletePerson xoá mọi enrollment của personID trong gallery; trả về số enrollment đã xoá
+func (s *galleryService) DeletePerson(galleryName, personID string) (int, error) {
+	gallery, err := s.GetGallery(galleryName)
+	if err != nil {
+		return 0, err
+	}
+	if personID == "" {
+		return 0, ErrEnrollmentNotFound
+	}
+	deleted, err := s.repo.DeleteEnrollments(gallery.ID, 0, personID)
+	if err != nil {
+		return 0, err
+	}
+	if deleted == 0 {
+		return 0, ErrEnrollmentNotFound
+	}
+	log.Printf("DeletePerson: Deleted %d enrollments of person '%s' in gallery '%s'", deleted, personID, galleryName)
+	return deleted, nil
+}
+
+// Search tìm từng khuôn mặt trong ảnh trong gallery: mỗi khuôn mặt được so với mọi enrollment
+// bằng cosine similarity, mỗi người lấy enrollment giống nhất, trả về tối đa topK người có
+// similarity từ minSimilarity trở lên.
+func (s *galleryService) Search(ctx context.Context, galleryName, imagePath string, topK int, minSimilarity float64) (*domain.FaceSearchResult, error) {
+	gallery, err := s.GetGallery(galleryName)
+	if err != nil {
+		return nil, err
+	}
+	if topK == 0 {
+		topK = DefaultSearchTopK
+	}
+	if topK < 0 || topK > MaxSearchTopK {
+		return nil, fmt.Errorf("%w: top_k must be between 1 and %d", ErrInvalidGallery, MaxSearchTopK)
+	}
+
+	faces, err := s.tasks.EncodeFaces(ctx, imagePath)
+	if err != nil {
+		return nil, err
+	}
+	enrollments, err := s.repo.GetEnrollments(gallery.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.FaceSearchResult{Gallery: gallery.Name, FaceCount: len(faces), Faces: []domain.FaceSearchFace{}}
+	for _, face := range faces {
+		result.Faces = append(result.Faces, domain.FaceSearchFace{
+			Face:    face.Face,
+			Matches: bestMatches(face.Embedding, enrollments, topK, minSimilarity),
+		})
+	}
+	return result, nil
+}
+
+// bestMatches so embedding với các enrollment và trả về tối đa topK người giống nhất, similarity giảm dần
+func bestMatches(embedding []float64, enrollments []domain.FaceEnrollment, topK int, minSimilarity float64) []domain.FaceMatch {
+	best := make(map[string]domain.FaceMatch)
+	for _, e := range enrollments {
+		similarity := domain.CosineSimilarity(embedding, e.Embedding)
+		if similarity < minSimilarity {
+			continue
+		}
+		if match, ok := best[e.PersonID]; ok && match.Similarity >= similarity {
+			continue
+		}
+		best[e.PersonID] = domain.FaceMatch{PersonID: e.PersonID, EnrollmentID: e.ID, Similarity: similarity, Metadata: e.Metadata}
+	}
+
+	matches := make([]domain.FaceMatch, 0, len(best))
+	for _, match := range best {
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].PersonID < matches[j].PersonID
+	})
+	if len(matches) > topK {
+		matches = matches[:topK]
+	}
+	return matches
+}
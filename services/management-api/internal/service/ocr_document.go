@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"management-api/internal/document"
+	"management-api/internal/domain"
+)
+
+// runOCR chạy OCR cho input: ảnh thường được gửi thẳng tới service OCR, tài liệu PDF hoặc TIFF
+// được tách trang bằng runOCRDocument. taskID là task đang chạy (0 nếu chạy qua RunTool).
+func (s *taskService) runOCR(ctx context.Context, taskID int, input domain.ToolInput) (*domain.OCRResult, error) {
+	kind, err := document.Detect(input.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if kind != "" {
+		return s.runOCRDocument(ctx, taskID, input)
+	}
+
+	result, err := s.HandleOCR(ctx, toolFile(input), input.Languages, input.Output, input.SearchablePDF)
+	if err != nil {
+		return nil, err
+	}
+	if result.PDF != nil {
+		path, err := s.ocrPDFPath(taskID)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(s.sharedImagePath, path), result.PDF, 0644)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save searchable PDF: %v", err)
+		}
+		result.SearchablePDF = path
+		result.PDF = nil
+	}
+	return result, nil
+}
+
+// runOCRDocument OCR tài liệu PDF hoặc TIFF: tách tài liệu thành ảnh từng trang, OCR mỗi trang như một
+// task con (document_task_id, page_number) với tối đa OCRConfig.PageConcurrency trang cùng lúc, rồi ghép
+// kết quả các trang. Tài liệu failed nếu có trang failed hoặc bị huỷ; kết quả từng trang vẫn nằm ở task con.
+// taskID bằng 0 thì các trang được OCR mà không tạo task con và ảnh trang được xoá sau khi chạy.
+func (s *taskService) runOCRDocument(ctx context.Context, taskID int, input domain.ToolInput) (*domain.OCRResult, error) {
+	name := "ocr_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if taskID != 0 {
+		name = "task_" + strconv.Itoa(taskID)
+	}
+	dir := filepath.Join(s.uploads.ImagePath, "documents", name)
+	if taskID == 0 {
+		defer os.RemoveAll(dir)
+	}
+
+	paths, err := document.SplitPages(ctx, input.FilePath, dir, s.ocr.PDFDPI, s.ocr.MaxPages)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]domain.OCRPage, len(paths))
+	inputs := make([]domain.ToolInput, len(paths))
+	for i, path := range paths {
+		inputs[i] = input
+		inputs[i].FilePath = path
+		inputs[i].URL = ""
+		pages[i] = domain.OCRPage{Page: i + 1, Status: domain.TaskStatusQueued}
+		if taskID == 0 {
+			continue
+		}
+		pages[i].TaskID, err = s.repo.CreatePageTask(taskID, i+1, inputs[i])
+		if err != nil {
+			log.Printf("runOCRDocument: Failed to create task for page %d of task %d. Error: %v", i+1, taskID, err)
+			return nil, fmt.Errorf("failed to create page tasks")
+		}
+	}
+	log.Printf("runOCRDocument: Split %s into %d pages (task %d)", filepath.Base(input.FilePath), len(pages), taskID)
+
+	concurrency := s.ocr.PageConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	results := make([]*domain.OCRResult, len(pages))
+	errs := make([]error, len(pages))
+	var wg sync.WaitGroup
+	for i := range pages {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+			}
+			results[i], errs[i] = s.runOCRPage(ctx, &pages[i], inputs[i])
+		}(i)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	texts := make([]string, len(pages))
+	var pdfs []string
+	var failed []string
+	var firstErr error
+	for i := range pages {
+		if errs[i] != nil {
+			failed = append(failed, strconv.Itoa(pages[i].Page))
+			if firstErr == nil {
+				firstErr = fmt.Errorf("page %d: %w", pages[i].Page, errs[i])
+			}
+			continue
+		}
+		pages[i].Result = results[i]
+		texts[i] = results[i].Text
+		if results[i].PDF != nil {
+			pdf := strings.TrimSuffix(paths[i], filepath.Ext(paths[i])) + ".pdf"
+			if err := os.WriteFile(pdf, results[i].PDF, 0644); err != nil {
+				return nil, fmt.Errorf("failed to save searchable PDF of page %d: %v", pages[i].Page, err)
+			}
+			defer os.Remove(pdf)
+			pdfs = append(pdfs, pdf)
+			results[i].PDF = nil
+		}
+	}
+	if firstErr != nil {
+		return nil, fmt.Errorf("%d of %d pages failed (pages %s), %w", len(failed), len(pages), strings.Join(failed, ", "), firstErr)
+	}
+
+	result := &domain.OCRResult{
+		Text:      strings.Join(texts, "\f"),
+		Languages: results[0].Languages,
+		PageCount: len(pages),
+		Pages:     pages,
+	}
+	if input.SearchablePDF {
+		path, err := s.ocrPDFPath(taskID)
+		if err == nil {
+			err = document.MergePDF(ctx, pdfs, filepath.Join(s.sharedImagePath, path))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to build searchable PDF: %v", err)
+		}
+		result.SearchablePDF = path
+	}
+	return result, nil
+}
+
+// runOCRPage OCR một trang và ghi trạng thái, kết quả vào task con của trang (nếu có).
+// Task con có thể bị huỷ riêng bằng CancelTask; khi đó trang có trạng thái cancelled.
+func (s *taskService) runOCRPage(ctx context.Context, page *domain.OCRPage, input domain.ToolInput) (*domain.OCRResult, error) {
+	pageCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if page.TaskID != 0 {
+		defer s.startRun(page.TaskID, cancel)()
+	}
+
+	var result *domain.OCRResult
+	err := pageCtx.Err()
+	if err == nil {
+		err = s.updatePageTask(page, domain.TaskStatusProcessing, nil)
+	}
+	if err == nil {
+		input, err = s.preprocessInput(page.TaskID, domain.ToolOCR, input)
+	}
+	if err == nil {
+		result, err = s.HandleOCR(pageCtx, toolFile(input), input.Languages, input.Output, input.SearchablePDF)
+	}
+
+	switch {
+	case pageCtx.Err() != nil:
+		page.Status = domain.TaskStatusCancelled
+		page.Error = "page was cancelled"
+		s.updatePageTask(page, domain.TaskStatusCancelled, nil)
+		return nil, errors.New(page.Error)
+	case err != nil:
+		page.Status = domain.TaskStatusFailed
+		page.Error = err.Error()
+		s.updatePageTask(page, domain.TaskStatusFailed, failureOutput(err))
+		return nil, err
+	}
+	page.Status = domain.TaskStatusCompleted
+	if err := s.updatePageTask(page, domain.TaskStatusCompleted, result); err != nil {
+		// Trang đã bị huỷ hoặc đánh dấu failed ở nơi khác (reaper)
+		page.Status = domain.TaskStatusFailed
+		page.Error = err.Error()
+		return nil, err
+	}
+	return result, nil
+}
+
+// updatePageTask ghi trạng thái của trang vào task con; trang không có task con thì bỏ qua
+func (s *taskService) updatePageTask(page *domain.OCRPage, status domain.TaskStatus, output interface{}) error {
+	if page.TaskID == 0 {
+		return nil
+	}
+	err := s.repo.UpdateTask(page.TaskID, status, output)
+	if err != nil {
+		log.Printf("runOCRPage: Failed to update page task %d. Error: %v", page.TaskID, err)
+	}
+	return err
+}
+
+// ocrPDFPath tạo thư mục và trả về đường dẫn (tương đối với thư mục ảnh dùng chung) của searchable PDF
+func (s *taskService) ocrPDFPath(taskID int) (string, error) {
+	name := fmt.Sprintf("ocr_%d.pdf", time.Now().UnixNano())
+	if taskID != 0 {
+		name = fmt.Sprintf("ocr_task_%d.pdf", taskID)
+	}
+	return s.sharedPath(name)
+}
+
+// sharedPath tạo thư mục theo ngày và trả về đường dẫn (tương đối với thư mục ảnh dùng chung) của file name
+func (s *taskService) sharedPath(name string) (string, error) {
+	date := time.Now().Format("2006-01-02")
+	if err := os.MkdirAll(filepath.Join(s.sharedImagePath, date), 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(date, name), nil
+}
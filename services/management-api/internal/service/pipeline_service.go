@@ -0,0 +1,477 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/repository"
+)
+
+type PipelineService interface {
+	ValidatePipeline(steps []domain.PipelineStep, params []string) error
+	CreatePipeline(pipeline *domain.Pipeline) (*domain.Pipeline, error)
+	GetPipeline(id int) (*domain.Pipeline, error)
+	GetPipelineTasks(id int) ([]domain.Task, error)
+}
+
+type pipelineService struct {
+	repo    repository.TaskRepository
+	tasks   TaskService
+	uploads config.UploadConfig
+}
+
+func NewPipelineService(repo repository.TaskRepository, tasks TaskService, cfg *config.Config) PipelineService {
+	return &pipelineService{
+		repo:    repo,
+		tasks:   tasks,
+		uploads: cfg.Uploads,
+	}
+}
+
+// stepResult là kết quả chạy một bước, output đã được decode để tra cứu theo đường dẫn
+type stepResult struct {
+	output interface{}
+	err    error
+}
+
+// ValidatePipeline kiểm tra các bước: ID duy nhất, tool hợp lệ, params không chứa đường dẫn file
+// trên server, tham chiếu và phụ thuộc tồn tại, và các phụ thuộc không tạo thành chu trình.
+// params là tên các tham số mà tham chiếu "params.<name>" được phép dùng.
+func (s *pipelineService) ValidatePipeline(steps []domain.PipelineStep, params []string) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("pipeline has no steps")
+	}
+
+	ids := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		if step.ID == "" {
+			return fmt.Errorf("step is missing 'id'")
+		}
+		if ids[step.ID] {
+			return fmt.Errorf("duplicate step id '%s'", step.ID)
+		}
+		ids[step.ID] = true
+		if !domain.IsValidTool(step.Tool) {
+			return fmt.Errorf("step '%s': unknown tool '%s'", step.ID, step.Tool)
+		}
+		if step.Params.HasServerFiles() {
+			return fmt.Errorf("step '%s': params cannot contain server file paths", step.ID)
+		}
+	}
+
+	for _, step := range steps {
+		fields := make(map[string]interface{}, len(step.Inputs))
+		for field, ref := range step.Inputs {
+			fields[field] = inputPlaceholder(field)
+			path, err := parseRef(ref)
+			if err != nil {
+				return fmt.Errorf("step '%s': %v", step.ID, err)
+			}
+			if path[0] == "params" && !containsString(params, path[1]) {
+				return fmt.Errorf("step '%s': unknown parameter '%s'", step.ID, path[1])
+			}
+		}
+		if _, err := decodeToolInput(fields); err != nil {
+			return fmt.Errorf("step '%s': invalid inputs: %v", step.ID, err)
+		}
+		for _, dep := range stepDependencies(step) {
+			if !ids[dep] {
+				return fmt.Errorf("step '%s' depends on unknown step '%s'", step.ID, dep)
+			}
+			if dep == step.ID {
+				return fmt.Errorf("step '%s' depends on itself", step.ID)
+			}
+		}
+	}
+
+	// Kahn: nếu không sắp xếp topo được hết các bước thì có chu trình
+	indegree := make(map[string]int, len(steps))
+	dependents := make(map[string][]string)
+	for _, step := range steps {
+		deps := stepDependencies(step)
+		indegree[step.ID] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], step.ID)
+		}
+	}
+	var queue []string
+	for _, step := range steps {
+		if indegree[step.ID] == 0 {
+			queue = append(queue, step.ID)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, next := range dependents[id] {
+			indegree[next]--
+			if indegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+	if visited != len(steps) {
+		return fmt.Errorf("pipeline steps contain a dependency cycle")
+	}
+
+	return nil
+}
+
+// CreatePipeline lưu pipeline, tạo một task pending cho mỗi bước và chạy pipeline ở background.
+// Pipeline phải được kiểm tra bằng ValidatePipeline trước.
+func (s *pipelineService) CreatePipeline(pipeline *domain.Pipeline) (*domain.Pipeline, error) {
+	pipelineID, err := s.repo.CreatePipeline(pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline, err = s.repo.GetPipeline(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	taskIDs := make(map[string]int, len(pipeline.Steps))
+	for _, step := range pipeline.Steps {
+		taskIDs[step.ID], err = s.repo.CreatePipelineTask(pipeline, step)
+		if err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("CreatePipeline: Created pipeline %d with %d steps", pipelineID, len(pipeline.Steps))
+
+	go s.run(*pipeline, taskIDs)
+
+	return pipeline, nil
+}
+
+// run chạy các bước theo thứ tự phụ thuộc; các bước đã sẵn sàng được chạy song song.
+// Khi một bước lỗi, các bước chưa chạy được đánh dấu cancelled và pipeline failed.
+func (s *pipelineService) run(pipeline domain.Pipeline, taskIDs map[string]int) {
+	if err := s.repo.UpdatePipelineStatus(pipeline.ID, "processing"); err != nil {
+		log.Printf("run: Failed to update pipeline %d. Error: %v", pipeline.ID, err)
+	}
+
+	outputs := make(map[string]interface{}, len(pipeline.Steps))
+	for len(outputs) < len(pipeline.Steps) {
+		var ready []domain.PipelineStep
+		for _, step := range pipeline.Steps {
+			if _, done := outputs[step.ID]; !done && dependenciesDone(step, outputs) {
+				ready = append(ready, step)
+			}
+		}
+
+		results := make([]stepResult, len(ready))
+		var wg sync.WaitGroup
+		for i, step := range ready {
+			wg.Add(1)
+			go func(i int, step domain.PipelineStep) {
+				defer wg.Done()
+				output, err := s.runStep(pipeline, step, taskIDs[step.ID], outputs)
+				results[i] = stepResult{output: output, err: err}
+			}(i, step)
+		}
+		wg.Wait()
+
+		failed := false
+		for i, step := range ready {
+			if results[i].err != nil {
+				log.Printf("run: Step '%s' of pipeline %d failed. Error: %v", step.ID, pipeline.ID, results[i].err)
+				failed = true
+				continue
+			}
+			outputs[step.ID] = results[i].output
+		}
+
+		if failed {
+			for _, step := range pipeline.Steps {
+				if _, done := outputs[step.ID]; done || containsStep(ready, step.ID) {
+					continue
+				}
+				if err := s.repo.UpdateTask(taskIDs[step.ID], domain.TaskStatusCancelled, nil); err != nil {
+					log.Printf("run: Failed to update task %d. Error: %v", taskIDs[step.ID], err)
+				}
+			}
+			if err := s.repo.UpdatePipelineStatus(pipeline.ID, "failed"); err != nil {
+				log.Printf("run: Failed to update pipeline %d. Error: %v", pipeline.ID, err)
+			}
+			return
+		}
+	}
+
+	if err := s.repo.UpdatePipelineStatus(pipeline.ID, "completed"); err != nil {
+		log.Printf("run: Failed to update pipeline %d. Error: %v", pipeline.ID, err)
+	}
+	log.Printf("run: Pipeline %d completed", pipeline.ID)
+}
+
+// runStep giải quyết input của bước, chạy tool và ghi kết quả vào task của bước
+func (s *pipelineService) runStep(pipeline domain.Pipeline, step domain.PipelineStep, taskID int, outputs map[string]interface{}) (interface{}, error) {
+	output, err := s.execute(pipeline, step, taskID, outputs)
+	if err != nil {
+		if err := s.repo.UpdateTask(taskID, domain.TaskStatusFailed, failureOutput(err)); err != nil {
+			log.Printf("runStep: Failed to update task %d. Error: %v", taskID, err)
+		}
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTask(taskID, domain.TaskStatusCompleted, output); err != nil {
+		log.Printf("runStep: Failed to update task %d. Error: %v", taskID, err)
+	}
+
+	// Decode lại output thành map/slice để các bước sau tra cứu theo đường dẫn
+	var decoded interface{}
+	raw, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func (s *pipelineService) execute(pipeline domain.Pipeline, step domain.PipelineStep, taskID int, outputs map[string]interface{}) (interface{}, error) {
+	input, err := buildStepInput(pipeline, step, outputs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateTaskInput(taskID, input); err != nil {
+		log.Printf("execute: Failed to update input of task %d. Error: %v", taskID, err)
+	}
+	if err := s.repo.UpdateTask(taskID, domain.TaskStatusProcessing, nil); err != nil {
+		return nil, err
+	}
+
+	imageDir := filepath.Join(s.uploads.ImagePath, "pipelines", strconv.Itoa(pipeline.ID))
+	input, err = resolveToolInput(step.Tool, input, imageDir, s.uploads)
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks.RunTask(taskID, step.Tool, input)
+}
+
+func (s *pipelineService) GetPipeline(id int) (*domain.Pipeline, error) {
+	return s.repo.GetPipeline(id)
+}
+
+func (s *pipelineService) GetPipelineTasks(id int) ([]domain.Task, error) {
+	return s.repo.GetPipelineTasks(id)
+}
+
+// buildStepInput ghép Params của bước với các giá trị lấy từ tham chiếu trong Inputs
+func buildStepInput(pipeline domain.Pipeline, step domain.PipelineStep, outputs map[string]interface{}) (domain.ToolInput, error) {
+	fields, err := toMap(step.Params)
+	if err != nil {
+		return domain.ToolInput{}, err
+	}
+	source, err := toMap(pipeline.Input)
+	if err != nil {
+		return domain.ToolInput{}, err
+	}
+
+	for field, ref := range step.Inputs {
+		path, err := parseRef(ref)
+		if err != nil {
+			return domain.ToolInput{}, err
+		}
+
+		var value interface{}
+		switch path[0] {
+		case "input":
+			value, err = lookupPath(source, path[1:])
+		case "params":
+			value, err = lookupPath(map[string]interface{}(pipeline.Params), path[1:])
+		default:
+			value, err = lookupPath(outputs[path[1]], path[2:])
+		}
+		if err != nil {
+			return domain.ToolInput{}, fmt.Errorf("cannot resolve '%s': %v", ref, err)
+		}
+
+		fields[field], err = inputValue(field, value)
+		if err != nil {
+			return domain.ToolInput{}, err
+		}
+	}
+
+	input, err := decodeToolInput(fields)
+	if err != nil {
+		return domain.ToolInput{}, fmt.Errorf("invalid inputs: %v", err)
+	}
+	return input, nil
+}
+
+// parseRef tách tham chiếu "input.<field>", "params.<name>" hoặc "steps.<id>.<path>" thành các phần
+func parseRef(ref string) ([]string, error) {
+	path := strings.Split(ref, ".")
+	switch {
+	case (path[0] == "input" || path[0] == "params") && len(path) >= 2:
+		return path, nil
+	case path[0] == "steps" && len(path) >= 3:
+		return path, nil
+	default:
+		return nil, fmt.Errorf("invalid reference '%s', expected 'input.<field>', 'params.<name>' or 'steps.<id>.<field>'", ref)
+	}
+}
+
+// stepDependencies trả về các bước mà step phải chờ: DependsOn và các bước được tham chiếu
+func stepDependencies(step domain.PipelineStep) []string {
+	seen := make(map[string]bool)
+	var deps []string
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			deps = append(deps, id)
+		}
+	}
+	for _, dep := range step.DependsOn {
+		add(dep)
+	}
+	for _, ref := range step.Inputs {
+		if path, err := parseRef(ref); err == nil && path[0] == "steps" {
+			add(path[1])
+		}
+	}
+	return deps
+}
+
+func dependenciesDone(step domain.PipelineStep, outputs map[string]interface{}) bool {
+	for _, dep := range stepDependencies(step) {
+		if _, done := outputs[dep]; !done {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStep(steps []domain.PipelineStep, id string) bool {
+	for _, step := range steps {
+		if step.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupPath đi theo đường dẫn trong giá trị JSON đã decode; phần tử số là chỉ số mảng
+func lookupPath(value interface{}, path []string) (interface{}, error) {
+	for _, key := range path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[key]
+			if !ok {
+				return nil, fmt.Errorf("field '%s' not found", key)
+			}
+			value = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("invalid index '%s'", key)
+			}
+			value = v[i]
+		default:
+			return nil, fmt.Errorf("field '%s' not found", key)
+		}
+	}
+	return value, nil
+}
+
+// toolInputFields là kiểu của các trường ToolInput theo tên JSON
+var toolInputFields = func() map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	t := reflect.TypeOf(domain.ToolInput{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = t.Field(i).Type
+		}
+	}
+	return fields
+}()
+
+// inputPlaceholder là giá trị cùng kiểu với trường field của ToolInput, dùng khi kiểm tra Inputs của bước
+// trước khi có giá trị thật; trường không tồn tại trả về chuỗi rỗng để decodeToolInput báo lỗi
+func inputPlaceholder(field string) interface{} {
+	if t, ok := toolInputFields[field]; ok {
+		return reflect.Zero(t).Interface()
+	}
+	return ""
+}
+
+// inputValue chuyển giá trị JSON lấy từ tham chiếu thành giá trị của trường field trong ToolInput. Giá trị được
+// giữ nguyên kiểu (số, boolean, ...), trừ giá trị không phải chuỗi gán vào trường chuỗi (ví dụ text) được chuyển
+// thành chuỗi, và chuỗi gán vào trường danh sách (preprocess) được tách theo dấu phẩy như trường form.
+func inputValue(field string, value interface{}) (interface{}, error) {
+	t := toolInputFields[field]
+	if t == nil {
+		return value, nil
+	}
+	str, isString := value.(string)
+	switch {
+	case t.Kind() == reflect.String && !isString:
+		return stringValue(value)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String && isString:
+		items := []string{}
+		for _, item := range strings.Split(str, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+	return value, nil
+}
+
+// stringValue chuyển giá trị JSON thành chuỗi để gán vào trường chuỗi của ToolInput
+func stringValue(value interface{}) (string, error) {
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+func toMap(input domain.ToolInput) (map[string]interface{}, error) {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	return fields, json.Unmarshal(raw, &fields)
+}
+
+// decodeToolInput chuyển map thành ToolInput, báo lỗi nếu có trường không tồn tại
+func decodeToolInput(fields map[string]interface{}) (domain.ToolInput, error) {
+	var input domain.ToolInput
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return input, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&input)
+	return input, err
+}
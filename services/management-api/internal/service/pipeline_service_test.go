@@ -0,0 +1,62 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"management-api/internal/domain"
+)
+
+func TestValidatePipelineTypedInputs(t *testing.T) {
+	s := &pipelineService{}
+	steps := []domain.PipelineStep{{
+		ID:   "anonymize",
+		Tool: domain.ToolFaceAnonymize,
+		Inputs: map[string]string{
+			"strength":      "params.strength",
+			"max_dimension": "params.size",
+			"preprocess":    "params.preprocess",
+		},
+	}}
+	if err := s.ValidatePipeline(steps, []string{"strength", "size", "preprocess"}); err != nil {
+		t.Fatal(err)
+	}
+
+	steps[0].Inputs = map[string]string{"unknown": "params.strength"}
+	if err := s.ValidatePipeline(steps, []string{"strength"}); err == nil {
+		t.Fatal("expected error for unknown input field")
+	}
+}
+
+func TestBuildStepInputKeepsValueTypes(t *testing.T) {
+	pipeline := domain.Pipeline{
+		Params: map[string]interface{}{
+			"strength":   float64(70),
+			"matting":    true,
+			"preprocess": "auto_orient, downscale",
+		},
+	}
+	step := domain.PipelineStep{
+		ID:   "step",
+		Tool: domain.ToolBackgroundRemoval,
+		Inputs: map[string]string{
+			"strength":      "params.strength",
+			"alpha_matting": "params.matting",
+			"preprocess":    "params.preprocess",
+			"max_dimension": "steps.ocr.page_count",
+			"text":          "steps.ocr.page_count",
+		},
+	}
+	outputs := map[string]interface{}{"ocr": map[string]interface{}{"page_count": float64(3)}}
+
+	input, err := buildStepInput(pipeline, step, outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input.Strength != 70 || !input.AlphaMatting || input.MaxDimension != 3 || input.Text != "3" {
+		t.Fatalf("unexpected input %#v", input)
+	}
+	if want := []string{"auto_orient", "downscale"}; !reflect.DeepEqual(input.Preprocess, want) {
+		t.Fatalf("preprocess = %v, want %v", input.Preprocess, want)
+	}
+}
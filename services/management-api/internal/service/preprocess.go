@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"management-api/internal/document"
+	"management-api/internal/domain"
+	"management-api/internal/imaging"
+)
+
+// preprocessInput tiền xử lý ảnh đầu vào của ocr, face-recognition và face-anonymize theo input.Preprocess và ghi các bước
+// đã áp dụng vào input.Preprocessing; taskID khác 0 thì input_data của task được cập nhật theo.
+// Input không yêu cầu tiền xử lý, đã được tiền xử lý (task chạy lại sau reaper) hoặc là tài liệu
+// nhiều trang (mỗi trang được tiền xử lý riêng) được giữ nguyên.
+func (s *taskService) preprocessInput(taskID int, tool string, input domain.ToolInput) (domain.ToolInput, error) {
+	if len(input.Preprocess) == 0 || input.Preprocessing != nil || input.FilePath == "" {
+		return input, nil
+	}
+	if tool != domain.ToolOCR && tool != domain.ToolFaceRecognition && tool != domain.ToolFaceAnonymize {
+		return input, nil
+	}
+	if kind, err := document.Detect(input.FilePath); err != nil || kind != "" {
+		return input, nil
+	}
+	if err := domain.ValidatePreprocess(tool, input.Preprocess, input.MaxDimension); err != nil {
+		return input, err
+	}
+
+	var opts imaging.Options
+	for _, step := range input.Preprocess {
+		switch step {
+		case domain.PreprocessAutoOrient:
+			opts.AutoOrient = true
+		case domain.PreprocessDownscale:
+			opts.MaxDimension = input.MaxDimension
+			if opts.MaxDimension == 0 {
+				opts.MaxDimension = s.preprocess.MaxDimension
+			}
+		case domain.PreprocessGrayscale:
+			opts.Grayscale = true
+		case domain.PreprocessBinarize:
+			opts.Binarize = true
+		case domain.PreprocessDeskew:
+			opts.Deskew = true
+		}
+	}
+
+	out := strings.TrimSuffix(input.FilePath, filepath.Ext(input.FilePath)) + "_preprocessed.png"
+	result, err := imaging.Process(input.FilePath, out, opts)
+	if err != nil {
+		return input, fmt.Errorf("image preprocessing failed: %v", err)
+	}
+	input.Preprocessing = result
+	log.Printf("preprocessInput: Applied %d preprocessing operations to %s (task %d)", len(result.Operations), filepath.Base(input.FilePath), taskID)
+
+	if taskID != 0 {
+		if err := s.repo.UpdateTaskInput(taskID, input); err != nil {
+			log.Printf("preprocessInput: Failed to record preprocessing of task %d. Error: %v", taskID, err)
+		}
+	}
+	return input, nil
+}
+
+// toolFile là file ảnh được gửi tới service: ảnh đã tiền xử lý nếu có, ngược lại là file_path
+func toolFile(input domain.ToolInput) string {
+	if input.Preprocessing != nil {
+		return input.Preprocessing.FilePath
+	}
+	return input.FilePath
+}
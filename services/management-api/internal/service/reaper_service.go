@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"time"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/repository"
+)
+
+// reapedTasks đếm số task bị treo theo "<service_name>.<failed|requeued>", xem tại /debug/vars
+var reapedTasks = expvar.NewMap("reaped_tasks")
+
+type ReaperService interface {
+	// Run quét định kỳ các task bị treo cho tới khi ctx bị huỷ
+	Run(ctx context.Context)
+}
+
+type reaperService struct {
+	repo     repository.TaskRepository
+	tasks    TaskService
+	webhooks WebhookService
+	cfg      config.ReaperConfig
+}
+
+func NewReaperService(repo repository.TaskRepository, tasks TaskService, webhooks WebhookService, cfg *config.Config) ReaperService {
+	return &reaperService{repo: repo, tasks: tasks, webhooks: webhooks, cfg: cfg.Reaper}
+}
+
+func (s *reaperService) Run(ctx context.Context) {
+	log.Printf("Run: Reaping tasks processing longer than %s every %s", s.cfg.Timeout, s.cfg.Interval)
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reap()
+		}
+	}
+}
+
+// reap tìm các task processing quá timeout của service và đánh dấu failed hoặc đưa lại vào hàng đợi
+func (s *reaperService) reap() {
+	now := time.Now()
+	tasks, err := s.repo.GetStuckTasks(now.Add(-s.minTimeout()))
+	if err != nil {
+		log.Printf("reap: Failed to load stuck tasks. Error: %v", err)
+		return
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		timeout := s.cfg.TimeoutFor(task.ServiceName)
+		started := task.UpdatedAt
+		if task.StartedAt != nil {
+			started = *task.StartedAt
+		}
+		if now.Sub(started) < timeout {
+			continue
+		}
+
+		if s.canRequeue(task) {
+			s.requeue(task, timeout)
+		} else {
+			s.fail(task, timeout)
+		}
+	}
+}
+
+// canRequeue cho biết task có thể chạy lại: còn lượt thử, do management-api chạy và không thuộc pipeline,
+// batch hay tài liệu OCR nhiều trang (các task này do pipeline, batch hoặc task tài liệu chạy)
+func (s *reaperService) canRequeue(task *domain.Task) bool {
+	return task.Attempt < s.cfg.MaxAttempts && task.PipelineID == nil && task.BatchID == nil &&
+		task.DocumentTaskID == nil && domain.IsValidTool(task.ServiceName)
+}
+
+// requeue dừng lần chạy cũ của task (nếu còn chạy trong process này) rồi đưa task lại vào hàng đợi,
+// để kết quả muộn của lần chạy cũ không bị ghi thành kết quả của lần thử mới
+func (s *reaperService) requeue(task *domain.Task, timeout time.Duration) {
+	s.tasks.StopRun(task.ID)
+	if err := s.repo.RequeueTask(task.ID); err != nil {
+		log.Printf("requeue: Failed to requeue task %d. Error: %v", task.ID, err)
+		return
+	}
+	reapedTasks.Add(task.ServiceName+".requeued", 1)
+	log.Printf("requeue: Task %d (%s) processing longer than %s, requeued for attempt %d/%d",
+		task.ID, task.ServiceName, timeout, task.Attempt+1, s.cfg.MaxAttempts)
+
+	go func() {
+		if err := s.tasks.ResumeTask(task); err != nil {
+			log.Printf("requeue: Failed to resume task %d. Error: %v", task.ID, err)
+			s.fail(task, timeout)
+		}
+	}()
+}
+
+func (s *reaperService) fail(task *domain.Task, timeout time.Duration) {
+	output := map[string]string{
+		"error":  fmt.Sprintf("task timed out after %s", timeout),
+		"reason": domain.FailureReasonTimeout,
+	}
+	if err := s.repo.UpdateTask(task.ID, domain.TaskStatusFailed, output); err != nil {
+		log.Printf("fail: Failed to mark task %d as failed. Error: %v", task.ID, err)
+		return
+	}
+	reapedTasks.Add(task.ServiceName+".failed", 1)
+	log.Printf("fail: Task %d (%s) processing longer than %s, marked failed with reason %s",
+		task.ID, task.ServiceName, timeout, domain.FailureReasonTimeout)
+
+	updated, err := s.repo.GetTask(task.ID)
+	if err != nil {
+		log.Printf("fail: Failed to load task %d for webhook. Error: %v", task.ID, err)
+		return
+	}
+	s.webhooks.Notify(updated)
+}
+
+// minTimeout là timeout nhỏ nhất trong cấu hình, dùng để lọc sơ bộ trong database
+func (s *reaperService) minTimeout() time.Duration {
+	min := s.cfg.Timeout
+	for _, timeout := range s.cfg.ServiceTimeouts {
+		if timeout < min {
+			min = timeout
+		}
+	}
+	return min
+}
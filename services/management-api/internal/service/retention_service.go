@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/repository"
+)
+
+const (
+	// purgeBatchSize là số task tối đa được xử lý file trong một lần dọn dẹp
+	purgeBatchSize = 1000
+	// maxReportFiles là số file tối đa được liệt kê trong báo cáo
+	maxReportFiles = 500
+)
+
+type RetentionService interface {
+	// Run dọn dẹp định kỳ cho tới khi ctx bị huỷ
+	Run(ctx context.Context)
+	// Sweep xoá file và task hết hạn; dryRun bằng true chỉ trả về báo cáo, không xoá gì
+	Sweep(dryRun bool) (*domain.RetentionReport, error)
+}
+
+// sweep là trạng thái của một lần dọn dẹp
+type sweep struct {
+	report *domain.RetentionReport
+	// seen tránh liệt kê một file hai lần (file của task cũng có thể bị quét là file mồ côi khi dry-run)
+	seen map[string]bool
+}
+
+type retentionService struct {
+	repo  repository.TaskRepository
+	cfg   config.RetentionConfig
+	roots []string
+}
+
+func NewRetentionService(repo repository.TaskRepository, cfg *config.Config) RetentionService {
+	return &retentionService{
+		repo:  repo,
+		cfg:   cfg.Retention,
+		roots: []string{cfg.Uploads.ImagePath, cfg.Uploads.AudioPath, cfg.Retention.SharedImagePath},
+	}
+}
+
+func (s *retentionService) Run(ctx context.Context) {
+	log.Printf("Run: Cleaning up files older than %s and tasks older than %s every %s", s.cfg.FileTTL, s.cfg.TaskTTL, s.cfg.Interval)
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := s.Sweep(false)
+			if err != nil {
+				log.Printf("Run: Cleanup failed. Error: %v", err)
+				continue
+			}
+			log.Printf("Run: Removed %d files (%d bytes) and %d tasks (%s)", report.FileCount, report.FileBytes, report.TaskCount, report.TaskAction)
+		}
+	}
+}
+
+func (s *retentionService) Sweep(dryRun bool) (*domain.RetentionReport, error) {
+	report := &domain.RetentionReport{
+		DryRun:     dryRun,
+		Files:      []domain.RetentionFile{},
+		TaskAction: "purge",
+		CreatedAt:  time.Now(),
+	}
+	if s.cfg.Archive {
+		report.TaskAction = "archive"
+	}
+
+	sw := &sweep{report: report, seen: make(map[string]bool)}
+	if err := s.sweepTaskFiles(sw); err != nil {
+		return nil, err
+	}
+	if err := s.sweepOrphanFiles(sw); err != nil {
+		return nil, err
+	}
+	if err := s.sweepTasks(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// sweepTaskFiles xoá file đầu vào và file kết quả của các task đã quá thời gian giữ file của tool
+func (s *retentionService) sweepTaskFiles(sw *sweep) error {
+	minTTL := s.minFileTTL()
+	if minTTL == 0 {
+		return nil
+	}
+	now := time.Now()
+	tasks, err := s.repo.GetTasksForFilePurge(now.Add(-minTTL), purgeBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var purged []int
+	for _, task := range tasks {
+		finished := task.UpdatedAt
+		if task.FinishedAt != nil {
+			finished = *task.FinishedAt
+		}
+		if !s.cfg.FileExpired(task.ServiceName, finished, now) {
+			continue
+		}
+
+		id := task.ID
+		for _, path := range s.taskFiles(task) {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			s.removeFile(sw, domain.RetentionFile{Path: path, Size: info.Size(), TaskID: &id})
+		}
+		purged = append(purged, id)
+	}
+
+	if sw.report.DryRun || len(purged) == 0 {
+		return nil
+	}
+	return s.repo.MarkTaskFilesPurged(purged)
+}
+
+// sweepOrphanFiles xoá file cũ hơn thời gian giữ file lớn nhất mà không task nào chưa bị xoá file tham chiếu
+// (file của task đang chờ hay đang chạy, hoặc task chưa hết thời gian giữ file, được giữ lại),
+// ví dụ ảnh tải về từ URL khi chạy batch, rồi xoá các thư mục rỗng
+func (s *retentionService) sweepOrphanFiles(sw *sweep) error {
+	maxTTL := s.maxFileTTL()
+	if maxTTL == 0 {
+		return nil
+	}
+	cutoff := time.Now().Add(-maxTTL)
+	referenced, err := s.referencedFiles()
+	if err != nil {
+		return err
+	}
+	for _, root := range s.roots {
+		var dirs []string
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				if path != root {
+					dirs = append(dirs, path)
+				}
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil || !info.ModTime().Before(cutoff) || referenced[absPath(path)] {
+				return nil
+			}
+			s.removeFile(sw, domain.RetentionFile{Path: path, Size: info.Size()})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if sw.report.DryRun {
+			continue
+		}
+		// Xoá thư mục con trước thư mục cha; os.Remove không xoá được thư mục còn file
+		sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+		for _, dir := range dirs {
+			os.Remove(dir)
+		}
+	}
+	return nil
+}
+
+// sweepTasks lưu trữ hoặc xoá các task đã quá thời gian giữ task của tool
+func (s *retentionService) sweepTasks(report *domain.RetentionReport) error {
+	now := time.Now()
+	cutoffs := make(map[string]time.Time, len(s.cfg.TaskTTLs))
+	for tool, ttl := range s.cfg.TaskTTLs {
+		cutoffs[tool] = now.Add(-ttl)
+	}
+	defaultCutoff := now.Add(-s.cfg.TaskTTL)
+
+	counts, err := s.repo.CountExpiredTasks(cutoffs, defaultCutoff)
+	if err != nil {
+		return err
+	}
+	report.Tasks = counts
+	for _, count := range counts {
+		report.TaskCount += count
+	}
+
+	if report.DryRun || report.TaskCount == 0 {
+		return nil
+	}
+	removed, err := s.repo.RemoveExpiredTasks(cutoffs, defaultCutoff, s.cfg.Archive)
+	if err != nil {
+		return err
+	}
+	report.TaskCount = removed
+	return nil
+}
+
+// taskFiles trả về các file mà task tham chiếu: file đầu vào đã tải lên (kèm ảnh đã tiền xử lý, ảnh
+// tham chiếu của face-verify và ảnh nền của remove-bg), ảnh kết quả của remove-bg và face-anonymize,
+// searchable PDF của ocr
+func (s *retentionService) taskFiles(task domain.Task) []string {
+	var paths []string
+
+	var input domain.ToolInput
+	if err := json.Unmarshal(task.InputData, &input); err == nil && input.FilePath != "" {
+		paths = append(paths, input.FilePath)
+		if input.Preprocessing != nil && input.Preprocessing.FilePath != "" {
+			paths = append(paths, input.Preprocessing.FilePath)
+		}
+		if input.ReferenceFilePath != "" {
+			paths = append(paths, input.ReferenceFilePath)
+		}
+		if input.BackgroundFilePath != "" {
+			paths = append(paths, input.BackgroundFilePath)
+		}
+	}
+
+	var output struct {
+		ProcessedImagePath string `json:"processed_image_path"`
+		SearchablePDF      string `json:"searchable_pdf"`
+		ImagePath          string `json:"image_path"`
+	}
+	if err := json.Unmarshal(task.OutputData, &output); err == nil {
+		for _, path := range []string{output.ProcessedImagePath, output.SearchablePDF, output.ImagePath} {
+			if path != "" {
+				paths = append(paths, filepath.Join(s.cfg.SharedImagePath, filepath.Clean("/"+path)))
+			}
+		}
+	}
+
+	// Chỉ xoá file nằm trong các thư mục do management-api quản lý
+	var managed []string
+	for _, path := range paths {
+		if s.isManaged(path) {
+			managed = append(managed, path)
+		}
+	}
+	return managed
+}
+
+// referencedFiles trả về đường dẫn tuyệt đối của các file mà task chưa bị xoá file tham chiếu
+func (s *retentionService) referencedFiles() (map[string]bool, error) {
+	tasks, err := s.repo.GetTasksWithFiles()
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool)
+	for _, task := range tasks {
+		for _, path := range s.taskFiles(task) {
+			referenced[absPath(path)] = true
+		}
+	}
+	return referenced, nil
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func (s *retentionService) isManaged(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, root := range s.roots {
+		rootAbs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(abs, rootAbs+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFile ghi file vào báo cáo và xoá file nếu không phải dry-run
+func (s *retentionService) removeFile(sw *sweep, file domain.RetentionFile) {
+	key := absPath(file.Path)
+	if sw.seen[key] {
+		return
+	}
+	sw.seen[key] = true
+
+	report := sw.report
+	if !report.DryRun {
+		if err := os.Remove(file.Path); err != nil {
+			log.Printf("removeFile: Failed to remove %s. Error: %v", file.Path, err)
+			return
+		}
+	}
+
+	report.FileCount++
+	report.FileBytes += file.Size
+	if len(report.Files) < maxReportFiles {
+		report.Files = append(report.Files, file)
+	} else {
+		report.FilesTruncated = true
+	}
+}
+
+// minFileTTL là thời gian giữ file nhỏ nhất khác 0; 0 nếu mọi tool đều không xoá file
+func (s *retentionService) minFileTTL() time.Duration {
+	var min time.Duration
+	for _, ttl := range s.fileTTLs() {
+		if ttl > 0 && (min == 0 || ttl < min) {
+			min = ttl
+		}
+	}
+	return min
+}
+
+// maxFileTTL là thời gian giữ file lớn nhất, để file không rõ thuộc task nào không bị xoá sớm;
+// 0 nếu có tool không xoá file, khi đó file mồ côi không bị xoá vì có thể là file của tool đó
+func (s *retentionService) maxFileTTL() time.Duration {
+	var max time.Duration
+	for _, ttl := range s.fileTTLs() {
+		if ttl <= 0 {
+			return 0
+		}
+		if ttl > max {
+			max = ttl
+		}
+	}
+	return max
+}
+
+func (s *retentionService) fileTTLs() []time.Duration {
+	ttls := []time.Duration{s.cfg.FileTTL}
+	for _, ttl := range s.cfg.FileTTLs {
+		ttls = append(ttls, ttl)
+	}
+	return ttls
+}
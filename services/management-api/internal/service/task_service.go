@@ -1,34 +1,92 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"management-api/internal/config"
 	"management-api/internal/domain"
 	"management-api/internal/repository"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/jackc/pgx/v4"
 )
 
 type TaskService interface {
 	GetTaskStatus(id int) (*domain.Task, error)
 	GetAllTasks() ([]domain.Task, error)
-	HandleTextToVoice(text, language string) (map[string]string, error)
-	HandleVoiceToText(audioURL string) (map[string]string, error)
-	HandleBackgroundRemoval(imagePath string) (string, error)
-	HandleSpeechRecognition(audioURL string) (map[string]string, error)
-	HandleFaceRecognition(imagePath string) (map[string]interface{}, error)
-	HandleOCR(imagePath string) (map[string]string, error)
-	HandleTranslation(text, destLang string) (map[string]string, error)
+	HandleTextToVoice(ctx context.Context, text, language string) (*domain.TextToVoiceResult, error)
+	HandleVoiceToText(ctx context.Context, audioURL string) (*domain.TextResult, error)
+	HandleBackgroundRemoval(ctx context.Context, imagePath string, alphaMatting bool) (*domain.BackgroundRemovalResult, error)
+	HandleSpeechRecognition(ctx context.Context, audioURL string) (*domain.TextResult, error)
+	HandleFaceRecognition(ctx context.Context, imagePath string) (*domain.FaceRecognitionResult, error)
+	EncodeFaces(ctx context.Context, imagePath string) ([]domain.FaceEncoding, error)
+	HandleOCR(ctx context.Context, imagePath, languages, output string, searchablePDF bool) (*domain.OCRResult, error)
+	HandleTranslation(ctx context.Context, text, destLang string) (*domain.TranslationResult, error)
 	UploadAudio(filePath string) (string, error)
+	RunTool(ctx context.Context, tool string, input domain.ToolInput) (interface{}, error)
+	RunTask(taskID int, tool string, input domain.ToolInput) (interface{}, error)
+	SubmitTool(tool string, input domain.ToolInput, callbackURL string, wait bool) (*domain.Task, error)
+	ResumeTask(task *domain.Task) error
+	CancelTask(id int) (*domain.Task, error)
+	// StopRun huỷ lần chạy đang diễn ra của task (nếu có) mà không đổi trạng thái của task
+	StopRun(id int) bool
+	RetryTask(id int, overrides map[string]interface{}, callbackURL string) (*domain.Task, error)
+	GetTaskDeliveries(id int) ([]domain.WebhookDelivery, error)
 }
 
+var (
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrTaskCancelled là lỗi trả về khi task bị huỷ trong lúc đang chạy
+	ErrTaskCancelled = errors.New("task was cancelled")
+	// ErrTaskNotRetryable là lỗi khi task chưa kết thúc hoặc không phải task tool của management-api
+	ErrTaskNotRetryable = errors.New("task cannot be retried")
+	ErrInvalidOverrides = errors.New("invalid overrides")
+	// ErrInvalidToolInput là lỗi khi input thiếu trường mà tool cần
+	ErrInvalidToolInput = errors.New("invalid tool input")
+	// ErrNoFace là lỗi khi face-verify không tìm thấy khuôn mặt trong ảnh hoặc người cần so khớp chưa đăng ký
+	ErrNoFace = errors.New("no face to compare")
+)
+
 type taskService struct {
-	repo   repository.TaskRepository
-	client *resty.Client
+	repo       repository.TaskRepository
+	client     *resty.Client
+	webhooks   WebhookService
+	uploads    config.UploadConfig
+	backends   config.BackendConfig
+	ocr        config.OCRConfig
+	preprocess config.PreprocessConfig
+	face       config.FaceConfig
+	// sharedImagePath là thư mục ảnh dùng chung, nơi lưu searchable PDF của OCR
+	sharedImagePath string
+
+	// running giữ lần chạy đang diễn ra của các task, theo ID task
+	mu      sync.Mutex
+	running map[int]*taskRun
+}
+
+// taskRun là một lần chạy của task; con trỏ phân biệt các lần chạy khác nhau của cùng một task
+type taskRun struct {
+	cancel context.CancelFunc
 }
 
-func NewTaskService(repo repository.TaskRepository) TaskService {
+func NewTaskService(repo repository.TaskRepository, webhooks WebhookService, cfg *config.Config) TaskService {
 	return &taskService{
-		repo:   repo,
-		client: resty.New(),
+		repo:            repo,
+		client:          resty.New(),
+		webhooks:        webhooks,
+		uploads:         cfg.Uploads,
+		backends:        cfg.Backends,
+		ocr:             cfg.OCR,
+		preprocess:      cfg.Preprocess,
+		face:            cfg.Face,
+		sharedImagePath: cfg.Retention.SharedImagePath,
+		running:         make(map[int]*taskRun),
 	}
 }
 
@@ -40,4 +98,275 @@ func (s *taskService) GetAllTasks() ([]domain.Task, error) {
 	return s.repo.GetAllTasks()
 }
 
+func (s *taskService) GetTaskDeliveries(id int) ([]domain.WebhookDelivery, error) {
+	if _, err := s.repo.GetTask(id); err != nil {
+		return nil, err
+	}
+	return s.webhooks.GetTaskDeliveries(id)
+}
+
+// SubmitTool tạo task cho một request tới tool và gửi webhook tới callbackURL (nếu có) khi task kết thúc.
+// wait bằng true thì chạy tool ngay và trả về task đã kết thúc (completed, failed hoặc cancelled);
+// wait bằng false thì trả về task queued và chạy tool ở background.
+// URL trong input được tải về trước khi tạo task.
+func (s *taskService) SubmitTool(tool string, input domain.ToolInput, callbackURL string, wait bool) (*domain.Task, error) {
+	if !domain.IsValidTool(tool) {
+		return nil, fmt.Errorf("%w: unknown tool '%s'", ErrInvalidToolInput, tool)
+	}
+	input, err := resolveToolInput(tool, input, s.uploads.ImagePath, s.uploads)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToolInput, err)
+	}
+	if err := validateToolInput(tool, input); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToolInput, err)
+	}
+
+	status := domain.TaskStatusQueued
+	if wait {
+		status = domain.TaskStatusProcessing
+	}
+	taskID, err := s.repo.CreateToolTask(tool, status, input, callbackURL)
+	if err != nil {
+		log.Printf("SubmitTool: Failed to create '%s' task. Error: %v", tool, err)
+		return nil, fmt.Errorf("failed to create task")
+	}
+	task, err := s.repo.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !wait {
+		go func(task domain.Task) {
+			if err := s.ResumeTask(&task); err != nil {
+				log.Printf("SubmitTool: Failed to run task %d. Error: %v", task.ID, err)
+			}
+		}(*task)
+		return task, nil
+	}
+
+	output, runErr := s.RunTask(taskID, tool, input)
+	s.finishTask(taskID, tool, output, runErr)
+	return s.repo.GetTask(taskID)
+}
+
+// RunTask chạy tool cho một task đã tạo. Task có thể bị huỷ bằng CancelTask trong lúc chạy,
+// khi đó request tới backend bị huỷ, output đã sinh ra bị xoá và trả về ErrTaskCancelled.
+// Ảnh đầu vào được tiền xử lý (nếu input yêu cầu) và các bước đã áp dụng được ghi vào input_data của task.
+func (s *taskService) RunTask(taskID int, tool string, input domain.ToolInput) (interface{}, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer s.startRun(taskID, cancel)()
+
+	input, err := s.preprocessInput(taskID, tool, input)
+	if err != nil {
+		return nil, err
+	}
+	var output interface{}
+	if tool == domain.ToolOCR {
+		// OCR tài liệu nhiều trang cần ID task để tạo task con cho từng trang
+		output, err = s.runOCR(ctx, taskID, input)
+	} else {
+		output, err = s.RunTool(ctx, tool, input)
+	}
+	if ctx.Err() != nil {
+		s.removeToolOutput(tool, input, output)
+		return nil, ErrTaskCancelled
+	}
+	if err == nil {
+		s.recordArtifact(taskID, output)
+	}
+	return output, err
+}
+
+// CancelTask huỷ task chưa kết thúc và dừng request đang chạy của task (nếu có)
+func (s *taskService) CancelTask(id int) (*domain.Task, error) {
+	if err := s.repo.UpdateTask(id, domain.TaskStatusCancelled, nil); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	ok := s.StopRun(id)
+	log.Printf("CancelTask: Cancelled task %d (in flight: %t)", id, ok)
+
+	return s.repo.GetTask(id)
+}
+
+func (s *taskService) StopRun(id int) bool {
+	s.mu.Lock()
+	run, ok := s.running[id]
+	s.mu.Unlock()
+	if ok {
+		run.cancel()
+	}
+	return ok
+}
+
+// startRun ghi lần chạy của task vào running và trả về hàm kết thúc lần chạy. Hàm kết thúc chỉ xoá
+// running[taskID] nếu nó vẫn là lần chạy này, để lần chạy cũ kết thúc muộn không xoá lần chạy mới.
+func (s *taskService) startRun(taskID int, cancel context.CancelFunc) func() {
+	run := &taskRun{cancel: cancel}
+	s.mu.Lock()
+	s.running[taskID] = run
+	s.mu.Unlock()
+	return func() {
+		s.mu.Lock()
+		if s.running[taskID] == run {
+			delete(s.running, taskID)
+		}
+		s.mu.Unlock()
+		cancel()
+	}
+}
+
+// ResumeTask chạy lại một task queued (ví dụ task được reaper đưa lại vào hàng đợi hoặc lần thử của RetryTask)
+// với input_data đã lưu. Input được resolveToolInput như khi tạo task, vì input của task con batch
+// vẫn còn url chưa tải về; không resolve được thì task failed.
+func (s *taskService) ResumeTask(task *domain.Task) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(task.InputData, &fields); err != nil {
+		return err
+	}
+	input, err := decodeToolInput(fields)
+	if err != nil {
+		return fmt.Errorf("task %d has no tool input: %v", task.ID, err)
+	}
+	if err := s.repo.UpdateTask(task.ID, domain.TaskStatusProcessing, nil); err != nil {
+		return err
+	}
+
+	input, err = resolveToolInput(task.ServiceName, input, s.uploads.ImagePath, s.uploads)
+	if err != nil {
+		s.finishTask(task.ID, task.ServiceName, nil, err)
+		return nil
+	}
+	output, runErr := s.RunTask(task.ID, task.ServiceName, input)
+	s.finishTask(task.ID, task.ServiceName, output, runErr)
+	return nil
+}
+
+// overridableFields là các trường input mà RetryTask cho phép ghi đè: tuỳ chọn của tool. Đầu vào của task
+// (text, URL và đường dẫn file trên server) được giữ nguyên như task gốc.
+var overridableFields = map[string]bool{
+	"language":         true,
+	"dest_lang":        true,
+	"languages":        true,
+	"output":           true,
+	"searchable_pdf":   true,
+	"preprocess":       true,
+	"max_dimension":    true,
+	"gallery":          true,
+	"person_id":        true,
+	"threshold":        true,
+	"mode":             true,
+	"strength":         true,
+	"background_color": true,
+	"alpha_matting":    true,
+	"crop":             true,
+	"crop_padding":     true,
+	"mask_only":        true,
+	"format":           true,
+}
+
+// RetryTask tạo lần thử mới cho một task đã kết thúc, dùng input_data đã lưu (kể cả file đã tải lên).
+// overrides ghi đè các tuỳ chọn trong overridableFields, ví dụ {"language": "fr"}; callbackURL rỗng thì dùng lại của task gốc.
+// Task mới được chạy ở background.
+func (s *taskService) RetryTask(id int, overrides map[string]interface{}, callbackURL string) (*domain.Task, error) {
+	parent, err := s.repo.GetTask(id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !parent.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: task %d is %s", ErrTaskNotRetryable, id, parent.Status)
+	}
+	if !domain.IsValidTool(parent.ServiceName) {
+		return nil, fmt.Errorf("%w: unknown tool '%s'", ErrTaskNotRetryable, parent.ServiceName)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(parent.InputData, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("%w: task %d has no stored input", ErrTaskNotRetryable, id)
+	}
+	if _, err := decodeToolInput(fields); err != nil {
+		return nil, fmt.Errorf("%w: task %d has no tool input", ErrTaskNotRetryable, id)
+	}
+	for key, value := range overrides {
+		if !overridableFields[key] {
+			return nil, fmt.Errorf("%w: '%s' cannot be overridden", ErrInvalidOverrides, key)
+		}
+		fields[key] = value
+	}
+	// Ảnh được tiền xử lý lại theo input mới khi task chạy
+	delete(fields, "preprocessing")
+	input, err := decodeToolInput(fields)
+	if err == nil {
+		err = validateToolInput(parent.ServiceName, input)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOverrides, err)
+	}
+	for _, path := range []string{input.FilePath, input.ReferenceFilePath, input.BackgroundFilePath} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("%w: uploaded file of task %d no longer exists", ErrTaskNotRetryable, id)
+		}
+	}
+
+	callback := parent.CallbackURL
+	if callbackURL != "" {
+		callback = &callbackURL
+	}
+	retryID, err := s.repo.CreateRetryTask(parent, input, callback)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.repo.GetTask(retryID)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("RetryTask: Created task %d as attempt %d of task %d", task.ID, task.Attempt, id)
+
+	go func(task domain.Task) {
+		if err := s.ResumeTask(&task); err != nil {
+			log.Printf("RetryTask: Failed to run task %d. Error: %v", task.ID, err)
+		}
+	}(*task)
+
+	return task, nil
+}
+
+// finishTask ghi kết quả hoặc lỗi của tool vào task và gửi webhook.
+// Nếu task đã bị đổi trạng thái ở nơi khác (reaper, huỷ) thì kết quả muộn bị bỏ qua.
+func (s *taskService) finishTask(taskID int, tool string, output interface{}, runErr error) {
+	if errors.Is(runErr, ErrTaskCancelled) {
+		return
+	}
+
+	status := domain.TaskStatusCompleted
+	var result interface{} = output
+	if runErr != nil {
+		status = domain.TaskStatusFailed
+		result = failureOutput(runErr)
+	}
+	if err := s.repo.UpdateTask(taskID, status, result); err != nil {
+		log.Printf("finishTask: Failed to update task %d. Error: %v", taskID, err)
+		if errors.Is(err, domain.ErrInvalidTransition) {
+			s.removeToolOutput(tool, domain.ToolInput{}, output)
+		}
+		return
+	}
+
+	task, err := s.repo.GetTask(taskID)
+	if err != nil {
+		log.Printf("finishTask: Failed to load task %d for webhook. Error: %v", taskID, err)
+		return
+	}
+	s.webhooks.Notify(task)
+}
+
 // Các phương thức xử lý các dịch vụ như Text-to-Voice, Voice-to-Text, ...
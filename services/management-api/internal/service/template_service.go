@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+
+	"management-api/internal/domain"
+	"management-api/internal/repository"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// pgerrUniqueViolation là mã lỗi unique_violation của Postgres
+const pgerrUniqueViolation = "23505"
+
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+	ErrInvalidTemplate  = errors.New("invalid template")
+)
+
+// templateNamePattern giới hạn tên template ở dạng slug, ví dụ "sign-reader"
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+type TemplateService interface {
+	CreateTemplate(name, description string, steps []domain.PipelineStep, params []domain.TemplateParameter) (*domain.PipelineTemplate, error)
+	CreateTemplateVersion(name string, description *string, steps []domain.PipelineStep, params []domain.TemplateParameter) (*domain.PipelineTemplateVersion, error)
+	GetTemplate(name string) (*domain.PipelineTemplate, error)
+	GetAllTemplates() ([]domain.PipelineTemplate, error)
+	GetTemplateVersions(name string) ([]domain.PipelineTemplateVersion, error)
+	GetTemplateVersion(name string, version int) (*domain.PipelineTemplateVersion, error)
+	DeleteTemplate(name string) error
+	RunTemplate(name string, version int, input domain.ToolInput, params map[string]interface{}) (*domain.Pipeline, error)
+}
+
+type templateService struct {
+	repo      repository.TaskRepository
+	pipelines PipelineService
+}
+
+func NewTemplateService(repo repository.TaskRepository, pipelines PipelineService) TemplateService {
+	return &templateService{repo: repo, pipelines: pipelines}
+}
+
+// CreateTemplate tạo template mới cùng phiên bản 1
+func (s *templateService) CreateTemplate(name, description string, steps []domain.PipelineStep, params []domain.TemplateParameter) (*domain.PipelineTemplate, error) {
+	if !templateNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: name must match %s", ErrInvalidTemplate, templateNamePattern)
+	}
+	if err := s.validateDefinition(steps, params); err != nil {
+		return nil, err
+	}
+
+	// Tên trùng được nhận ra qua unique index của pipeline_templates, kể cả khi hai request tạo cùng lúc
+	if _, err := s.repo.CreateTemplate(name, description, steps, params); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrUniqueViolation {
+			return nil, fmt.Errorf("%w: template '%s' already exists", ErrInvalidTemplate, name)
+		}
+		return nil, err
+	}
+	log.Printf("CreateTemplate: Created template '%s'", name)
+
+	return s.repo.GetTemplateByName(name)
+}
+
+// CreateTemplateVersion tạo phiên bản mới cho template; các phiên bản cũ không bị thay đổi
+func (s *templateService) CreateTemplateVersion(name string, description *string, steps []domain.PipelineStep, params []domain.TemplateParameter) (*domain.PipelineTemplateVersion, error) {
+	template, err := s.GetTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateDefinition(steps, params); err != nil {
+		return nil, err
+	}
+
+	if description != nil {
+		if err := s.repo.UpdateTemplateDescription(template.ID, *description); err != nil {
+			return nil, err
+		}
+	}
+
+	version, err := s.repo.CreateTemplateVersion(template.ID, steps, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("CreateTemplateVersion: Created version %d of template '%s'", version.Version, name)
+	return version, nil
+}
+
+func (s *templateService) GetTemplate(name string) (*domain.PipelineTemplate, error) {
+	template, err := s.repo.GetTemplateByName(name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	return template, err
+}
+
+func (s *templateService) GetAllTemplates() ([]domain.PipelineTemplate, error) {
+	return s.repo.GetAllTemplates()
+}
+
+func (s *templateService) GetTemplateVersions(name string) ([]domain.PipelineTemplateVersion, error) {
+	template, err := s.GetTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetTemplateVersions(template.ID)
+}
+
+// GetTemplateVersion lấy một phiên bản của template; version bằng 0 là phiên bản mới nhất
+func (s *templateService) GetTemplateVersion(name string, version int) (*domain.PipelineTemplateVersion, error) {
+	template, err := s.GetTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.repo.GetTemplateVersion(template.ID, version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	return v, err
+}
+
+func (s *templateService) DeleteTemplate(name string) error {
+	template, err := s.GetTemplate(name)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteTemplate(template.ID)
+}
+
+// RunTemplate kiểm tra tham số theo schema của phiên bản và chạy pipeline của phiên bản đó
+func (s *templateService) RunTemplate(name string, version int, input domain.ToolInput, params map[string]interface{}) (*domain.Pipeline, error) {
+	v, err := s.GetTemplateVersion(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := validateParams(v.Parameters, params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	log.Printf("RunTemplate: Running version %d of template '%s'", v.Version, name)
+	return s.pipelines.CreatePipeline(&domain.Pipeline{
+		Input:             input,
+		Params:            values,
+		Steps:             v.Steps,
+		TemplateVersionID: &v.ID,
+	})
+}
+
+// validateDefinition kiểm tra định nghĩa tham số và các bước của template
+func (s *templateService) validateDefinition(steps []domain.PipelineStep, params []domain.TemplateParameter) error {
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		if p.Name == "" {
+			return fmt.Errorf("%w: parameter is missing 'name'", ErrInvalidTemplate)
+		}
+		if containsString(names, p.Name) {
+			return fmt.Errorf("%w: duplicate parameter '%s'", ErrInvalidTemplate, p.Name)
+		}
+		if p.Type != domain.ParamTypeString && p.Type != domain.ParamTypeNumber && p.Type != domain.ParamTypeBoolean {
+			return fmt.Errorf("%w: parameter '%s' has unknown type '%s'", ErrInvalidTemplate, p.Name, p.Type)
+		}
+		if p.Default != nil {
+			if err := checkParam(p, p.Default); err != nil {
+				return fmt.Errorf("%w: default of %v", ErrInvalidTemplate, err)
+			}
+		}
+		names = append(names, p.Name)
+	}
+
+	if err := s.pipelines.ValidatePipeline(steps, names); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	for _, step := range steps {
+		for field, ref := range step.Inputs {
+			path, _ := parseRef(ref)
+			if path[0] != "params" {
+				continue
+			}
+			for _, p := range params {
+				if p.Name == path[1] && !inputAccepts(field, p.Type) {
+					return fmt.Errorf("%w: step '%s': %s parameter '%s' cannot be used as '%s'", ErrInvalidTemplate, step.ID, p.Type, p.Name, field)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateParams kiểm tra giá trị tham số theo schema và điền giá trị mặc định. Tham số không bắt buộc,
+// không có giá trị mặc định và không được truyền nhận zero value theo kiểu ("", 0, false) để
+// "params.<name>" trong các bước luôn giải quyết được.
+func validateParams(defs []domain.TemplateParameter, values map[string]interface{}) (map[string]interface{}, error) {
+	for name := range values {
+		found := false
+		for _, def := range defs {
+			found = found || def.Name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown parameter '%s'", name)
+		}
+	}
+
+	result := make(map[string]interface{}, len(defs))
+	for _, def := range defs {
+		value, ok := values[def.Name]
+		if !ok || value == nil {
+			switch {
+			case def.Default != nil:
+				result[def.Name] = def.Default
+			case def.Required:
+				return nil, fmt.Errorf("missing required parameter '%s'", def.Name)
+			default:
+				result[def.Name] = paramZeroValue(def.Type)
+			}
+			continue
+		}
+		if err := checkParam(def, value); err != nil {
+			return nil, err
+		}
+		result[def.Name] = value
+	}
+	return result, nil
+}
+
+// inputAccepts cho biết trường field của ToolInput nhận được giá trị kiểu paramType của tham số template
+func inputAccepts(field, paramType string) bool {
+	t := toolInputFields[field]
+	if t == nil {
+		return false
+	}
+	switch paramType {
+	case domain.ParamTypeString:
+		return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String)
+	case domain.ParamTypeNumber:
+		switch t.Kind() {
+		case reflect.Int, reflect.Float64, reflect.String:
+			return true
+		}
+	case domain.ParamTypeBoolean:
+		return t.Kind() == reflect.Bool || t.Kind() == reflect.String
+	}
+	return false
+}
+
+// paramZeroValue là giá trị của tham số không được truyền và không có giá trị mặc định
+func paramZeroValue(paramType string) interface{} {
+	switch paramType {
+	case domain.ParamTypeNumber:
+		return float64(0)
+	case domain.ParamTypeBoolean:
+		return false
+	default:
+		return ""
+	}
+}
+
+// checkParam kiểm tra kiểu và giá trị enum của một tham số (giá trị đã decode từ JSON)
+func checkParam(def domain.TemplateParameter, value interface{}) error {
+	var ok bool
+	switch def.Type {
+	case domain.ParamTypeString:
+		_, ok = value.(string)
+	case domain.ParamTypeNumber:
+		_, ok = value.(float64)
+	case domain.ParamTypeBoolean:
+		_, ok = value.(bool)
+	}
+	if !ok {
+		return fmt.Errorf("parameter '%s' must be a %s", def.Name, def.Type)
+	}
+
+	if len(def.Enum) == 0 {
+		return nil
+	}
+	for _, allowed := range def.Enum {
+		if reflect.DeepEqual(allowed, value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("parameter '%s' must be one of %v", def.Name, def.Enum)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"management-api/internal/domain"
+	"management-api/internal/repository"
+)
+
+// templateRepo trả về một phiên bản template cố định; các phương thức khác không được dùng
+type templateRepo struct {
+	repository.TaskRepository
+	version domain.PipelineTemplateVersion
+}
+
+func (r *templateRepo) GetTemplateByName(name string) (*domain.PipelineTemplate, error) {
+	return &domain.PipelineTemplate{ID: r.version.TemplateID, Name: name, LatestVersion: r.version.Version}, nil
+}
+
+func (r *templateRepo) GetTemplateVersion(templateID, version int) (*domain.PipelineTemplateVersion, error) {
+	return &r.version, nil
+}
+
+// capturePipelines giữ lại pipeline mà RunTemplate tạo thay vì chạy nó
+type capturePipelines struct {
+	pipelineService
+	created *domain.Pipeline
+}
+
+func (p *capturePipelines) CreatePipeline(pipeline *domain.Pipeline) (*domain.Pipeline, error) {
+	p.created = pipeline
+	return pipeline, nil
+}
+
+func TestRunTemplateNumberParam(t *testing.T) {
+	steps := []domain.PipelineStep{{
+		ID:     "anonymize",
+		Tool:   domain.ToolFaceAnonymize,
+		Inputs: map[string]string{"strength": "params.strength", "max_dimension": "params.size"},
+	}}
+	params := []domain.TemplateParameter{
+		{Name: "strength", Type: domain.ParamTypeNumber, Default: float64(40)},
+		{Name: "size", Type: domain.ParamTypeNumber},
+	}
+	pipelines := &capturePipelines{}
+	s := &templateService{
+		repo:      &templateRepo{version: domain.PipelineTemplateVersion{ID: 1, TemplateID: 1, Version: 1, Steps: steps, Parameters: params}},
+		pipelines: pipelines,
+	}
+	if err := s.validateDefinition(steps, params); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		params       map[string]interface{}
+		strength     int
+		maxDimension int
+	}{
+		{map[string]interface{}{"strength": float64(70), "size": float64(1024)}, 70, 1024},
+		{nil, 40, 0},
+	} {
+		if _, err := s.RunTemplate("blur", 0, domain.ToolInput{}, c.params); err != nil {
+			t.Fatal(err)
+		}
+		input, err := buildStepInput(*pipelines.created, steps[0], nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if input.Strength != c.strength || input.MaxDimension != c.maxDimension {
+			t.Fatalf("params %v: strength %d, max_dimension %d; want %d, %d", c.params, input.Strength, input.MaxDimension, c.strength, c.maxDimension)
+		}
+	}
+}
+
+func TestTemplateParamTypeMustFitInput(t *testing.T) {
+	s := &templateService{pipelines: &pipelineService{}}
+	steps := []domain.PipelineStep{{
+		ID:     "anonymize",
+		Tool:   domain.ToolFaceAnonymize,
+		Inputs: map[string]string{"strength": "params.strength"},
+	}}
+	params := []domain.TemplateParameter{{Name: "strength", Type: domain.ParamTypeBoolean}}
+	if err := s.validateDefinition(steps, params); !errors.Is(err, ErrInvalidTemplate) {
+		t.Fatalf("expected ErrInvalidTemplate, got %v", err)
+	}
+}
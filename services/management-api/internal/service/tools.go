@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/pkg/utils"
+)
+
+// RunTool chạy một tool theo tên với đầu vào chung, dùng khi xử lý batch
+func (s *taskService) RunTool(ctx context.Context, tool string, input domain.ToolInput) (interface{}, error) {
+	input, err := s.preprocessInput(0, tool, input)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tool {
+	case domain.ToolTextToVoice:
+		return s.HandleTextToVoice(ctx, input.Text, input.Language)
+	case domain.ToolVoiceToText:
+		return s.HandleVoiceToText(ctx, input.AudioURL)
+	case domain.ToolBackgroundRemoval:
+		return s.runBackgroundRemoval(ctx, input)
+	case domain.ToolSpeechRecognition:
+		return s.HandleSpeechRecognition(ctx, input.AudioURL)
+	case domain.ToolFaceRecognition:
+		return s.HandleFaceRecognition(ctx, toolFile(input))
+	case domain.ToolFaceVerify:
+		return s.runFaceVerify(ctx, input)
+	case domain.ToolFaceAnonymize:
+		return s.runFaceAnonymize(ctx, input)
+	case domain.ToolOCR:
+		return s.runOCR(ctx, 0, input)
+	case domain.ToolTranslation:
+		return s.HandleTranslation(ctx, input.Text, input.DestLang)
+	default:
+		return nil, fmt.Errorf("unknown tool '%s'", tool)
+	}
+}
+
+// resolveToolInput chuyển URL hoặc file tải lên thành dạng đầu vào mà tool cần:
+// text cho tool xử lý văn bản, audio_url cho tool audio, file ảnh cho tool xử lý ảnh
+// (kèm ảnh tham chiếu reference_url của face-verify và ảnh nền background_url của remove-bg).
+// Ảnh tải về từ URL được lưu vào imageDir. File đầu vào nằm ngoài thư mục upload bị từ chối trước khi đọc.
+func resolveToolInput(tool string, input domain.ToolInput, imageDir string, uploads config.UploadConfig) (domain.ToolInput, error) {
+	if err := checkInputFiles(input, uploads); err != nil {
+		return input, err
+	}
+
+	switch tool {
+	case domain.ToolTextToVoice, domain.ToolTranslation:
+		if input.Text != "" {
+			return input, nil
+		}
+		path := input.FilePath
+		if path == "" && input.URL != "" {
+			downloaded, err := utils.DownloadFile(input.URL, os.TempDir())
+			if err != nil {
+				return input, err
+			}
+			defer os.Remove(downloaded)
+			path = downloaded
+		}
+		if path == "" {
+			return input, fmt.Errorf("missing 'text', 'url' or file input")
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return input, err
+		}
+		input.Text = string(content)
+
+	case domain.ToolVoiceToText, domain.ToolSpeechRecognition:
+		if input.AudioURL == "" {
+			input.AudioURL = input.URL
+		}
+		if input.AudioURL == "" {
+			input.AudioURL = input.FilePath
+		}
+		if input.AudioURL == "" {
+			return input, fmt.Errorf("missing 'audio_url', 'url' or file input")
+		}
+		if err := checkAudioURL(input.AudioURL, uploads); err != nil {
+			return input, err
+		}
+
+	default:
+		if input.FilePath == "" && input.URL != "" {
+			downloaded, err := utils.DownloadFile(input.URL, imageDir)
+			if err != nil {
+				return input, err
+			}
+			input.FilePath = downloaded
+		}
+		if input.FilePath == "" {
+			return input, fmt.Errorf("missing 'url' or file input")
+		}
+		if tool == domain.ToolFaceVerify && input.ReferenceFilePath == "" && input.ReferenceURL != "" {
+			downloaded, err := utils.DownloadFile(input.ReferenceURL, imageDir)
+			if err != nil {
+				return input, err
+			}
+			input.ReferenceFilePath = downloaded
+		}
+		if tool == domain.ToolBackgroundRemoval && input.BackgroundFilePath == "" && input.BackgroundURL != "" {
+			downloaded, err := utils.DownloadFile(input.BackgroundURL, imageDir)
+			if err != nil {
+				return input, err
+			}
+			input.BackgroundFilePath = downloaded
+		}
+	}
+
+	return input, nil
+}
+
+// checkInputFiles kiểm tra các file đầu vào trên server của input, kể cả ảnh đã tiền xử lý mà toolFile
+// gửi tới service, nằm trong thư mục upload. Các đường dẫn này chỉ được gán từ file mà server đã lưu;
+// đường dẫn khác (ví dụ lấy từ params của pipeline) bị từ chối để client không đọc được file tuỳ ý trên server.
+func checkInputFiles(input domain.ToolInput, uploads config.UploadConfig) error {
+	files := []struct{ field, path string }{
+		{"file_path", input.FilePath},
+		{"reference_file_path", input.ReferenceFilePath},
+		{"background_file_path", input.BackgroundFilePath},
+	}
+	if input.Preprocessing != nil {
+		files = append(files, struct{ field, path string }{"preprocessing.file_path", input.Preprocessing.FilePath})
+	}
+	for _, f := range files {
+		if f.path != "" && !uploads.Contains(f.path) {
+			return fmt.Errorf("'%s' is outside the upload directories", f.field)
+		}
+	}
+	if input.AudioURL != "" {
+		return checkAudioURL(input.AudioURL, uploads)
+	}
+	return nil
+}
+
+// checkAudioURL kiểm tra audio_url là URL http(s) hoặc file trong thư mục upload; audio_url dạng đường dẫn
+// được đọc trên server (voice-to-text) hoặc trên service speech-recognition
+func checkAudioURL(audioURL string, uploads config.UploadConfig) error {
+	if isHTTPURL(audioURL) || uploads.Contains(audioURL) {
+		return nil
+	}
+	return fmt.Errorf("'audio_url' must be an http(s) URL or a file in the upload directories")
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validateToolInput kiểm tra các tuỳ chọn của tool. Hàm dùng được cả trước resolveToolInput
+// (batch chỉ tải file khi chạy task con) lẫn sau đó, vì reference_url được tính như ảnh tham chiếu.
+func validateToolInput(tool string, input domain.ToolInput) error {
+	switch tool {
+	case domain.ToolTranslation:
+		if input.DestLang == "" {
+			return fmt.Errorf("missing 'dest_lang'")
+		}
+	case domain.ToolOCR:
+		if err := domain.ValidateOCROptions(input.Languages, input.Output); err != nil {
+			return err
+		}
+	case domain.ToolFaceVerify:
+		byPerson := input.Gallery != "" || input.PersonID != ""
+		hasReference := input.ReferenceFilePath != "" || input.ReferenceURL != ""
+		if byPerson == hasReference {
+			return fmt.Errorf("face-verify needs either a reference image ('reference_url' or reference file) or 'gallery' and 'person_id'")
+		}
+		if byPerson && (input.Gallery == "" || input.PersonID == "") {
+			return fmt.Errorf("missing 'gallery' or 'person_id'")
+		}
+		if input.Threshold < -1 || input.Threshold > 1 {
+			return fmt.Errorf("invalid 'threshold' %v, must be between -1 and 1", input.Threshold)
+		}
+	case domain.ToolFaceAnonymize:
+		if err := domain.ValidateAnonymize(input.Mode, input.Strength); err != nil {
+			return err
+		}
+	case domain.ToolBackgroundRemoval:
+		if err := domain.ValidateBackgroundRemoval(input); err != nil {
+			return err
+		}
+	}
+	return domain.ValidatePreprocess(tool, input.Preprocess, input.MaxDimension)
+}
+
+// removeToolOutput xoá file output của task bị huỷ hoặc có kết quả đến muộn.
+// Với remove-bg, nếu chưa nhận được đường dẫn thì xoá file mà service sẽ ghi ra
+// ("<ngày>/output_<tên file>" trong thư mục ảnh dùng chung) nếu file đã tồn tại.
+// Với ocr, xoá searchable PDF nếu đã được tạo; với face-anonymize, xoá ảnh đã che khuôn mặt.
+func (s *taskService) removeToolOutput(tool string, input domain.ToolInput, output interface{}) {
+	var paths []string
+	switch tool {
+	case domain.ToolBackgroundRemoval:
+		if result, ok := output.(*domain.BackgroundRemovalResult); ok && result != nil && result.ProcessedImagePath != "" {
+			paths = append(paths, result.ProcessedImagePath)
+		}
+		if input.FilePath != "" {
+			name := "output_" + filepath.Base(input.FilePath)
+			paths = append(paths, filepath.Join(time.Now().Format("2006-01-02"), name))
+		}
+	case domain.ToolOCR:
+		if result, ok := output.(*domain.OCRResult); ok && result != nil && result.SearchablePDF != "" {
+			paths = append(paths, result.SearchablePDF)
+		}
+	case domain.ToolFaceAnonymize:
+		if result, ok := output.(*domain.FaceAnonymizeResult); ok && result != nil && result.ImagePath != "" {
+			paths = append(paths, result.ImagePath)
+		}
+	default:
+		return
+	}
+
+	for _, path := range paths {
+		fullPath := filepath.Join(s.sharedImagePath, filepath.Clean("/"+path))
+		if err := os.Remove(fullPath); err == nil {
+			log.Printf("removeToolOutput: Removed output file %s", fullPath)
+		}
+	}
+}
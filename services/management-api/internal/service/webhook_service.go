@@ -0,0 +1,142 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/repository"
+	"management-api/pkg/utils"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Header gửi kèm webhook. Chữ ký là HMAC-SHA256 của "<timestamp>.<body>" với WEBHOOK_SECRET.
+const (
+	WebhookSignatureHeader = "X-Itool-Signature"
+	WebhookTimestampHeader = "X-Itool-Timestamp"
+	WebhookEventHeader     = "X-Itool-Event"
+)
+
+type WebhookService interface {
+	// Notify gửi webhook ở background nếu task có callback_url
+	Notify(task *domain.Task)
+	GetTaskDeliveries(taskID int) ([]domain.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	repo   repository.TaskRepository
+	client *resty.Client
+	cfg    config.WebhookConfig
+}
+
+func NewWebhookService(repo repository.TaskRepository, cfg *config.Config) WebhookService {
+	if cfg.Webhook.Secret == "" {
+		log.Printf("NewWebhookService: WEBHOOK_SECRET is not set, webhooks will not be sent")
+	}
+	// callback_url do client gửi nên webhook chỉ được gửi tới địa chỉ công khai
+	client := resty.New().SetTransport(utils.NewPublicTransport()).SetTimeout(cfg.Webhook.Timeout)
+	return &webhookService{
+		repo:   repo,
+		client: client,
+		cfg:    cfg.Webhook,
+	}
+}
+
+// Notify không gửi webhook khi chưa cấu hình WEBHOOK_SECRET (chữ ký với khoá rỗng không có giá trị):
+// lần gửi bị từ chối được ghi vào webhook_deliveries để client thấy lý do.
+func (s *webhookService) Notify(task *domain.Task) {
+	if task.CallbackURL == nil || *task.CallbackURL == "" {
+		return
+	}
+
+	event, ok := domain.WebhookEvent(task.Status)
+	if !ok {
+		log.Printf("Notify: No webhook event for task %d with status '%s'", task.ID, task.Status)
+		return
+	}
+
+	if s.cfg.Secret == "" {
+		msg := "WEBHOOK_SECRET is not configured, webhook not sent"
+		delivery := domain.WebhookDelivery{TaskID: task.ID, URL: *task.CallbackURL, Event: event, Attempt: 1, Error: &msg}
+		if err := s.repo.CreateWebhookDelivery(&delivery); err != nil {
+			log.Printf("Notify: Failed to record delivery for task %d. Error: %v", task.ID, err)
+		}
+		log.Printf("Notify: Not sending '%s' for task %d: %s", event, task.ID, msg)
+		return
+	}
+
+	body, err := json.Marshal(domain.WebhookPayload{Event: event, Task: *task})
+	if err != nil {
+		log.Printf("Notify: Failed to encode payload for task %d. Error: %v", task.ID, err)
+		return
+	}
+
+	go s.deliver(task.ID, *task.CallbackURL, event, body)
+}
+
+// deliver gửi payload, thử lại với backoff tăng gấp đôi cho tới khi nhận 2xx
+// hoặc hết số lần thử. Mỗi lần thử được ghi vào webhook_deliveries.
+func (s *webhookService) deliver(taskID int, url, event string, body []byte) {
+	backoff := s.cfg.InitialBackoff
+	for attempt := 1; attempt <= s.cfg.MaxAttempts; attempt++ {
+		delivery := domain.WebhookDelivery{TaskID: taskID, URL: url, Event: event, Attempt: attempt}
+
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		resp, err := s.client.R().
+			SetHeader("Content-Type", "application/json").
+			SetHeader(WebhookEventHeader, event).
+			SetHeader(WebhookTimestampHeader, timestamp).
+			SetHeader(WebhookSignatureHeader, "sha256="+Sign(s.cfg.Secret, timestamp, body)).
+			SetBody(body).
+			Post(url)
+		if err != nil {
+			msg := err.Error()
+			delivery.Error = &msg
+		} else {
+			code := resp.StatusCode()
+			delivery.StatusCode = &code
+			delivery.Success = resp.IsSuccess()
+			if !delivery.Success {
+				msg := fmt.Sprintf("callback returned StatusCode: %d", code)
+				delivery.Error = &msg
+			}
+		}
+
+		if err := s.repo.CreateWebhookDelivery(&delivery); err != nil {
+			log.Printf("deliver: Failed to record delivery for task %d. Error: %v", taskID, err)
+		}
+
+		if delivery.Success {
+			log.Printf("deliver: Delivered '%s' for task %d to %s on attempt %d", event, taskID, url, attempt)
+			return
+		}
+
+		log.Printf("deliver: Attempt %d for task %d failed. Error: %s", attempt, taskID, *delivery.Error)
+		if attempt < s.cfg.MaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	log.Printf("deliver: Giving up delivering '%s' for task %d after %d attempts", event, taskID, s.cfg.MaxAttempts)
+}
+
+func (s *webhookService) GetTaskDeliveries(taskID int) ([]domain.WebhookDelivery, error) {
+	return s.repo.GetTaskDeliveries(taskID)
+}
+
+// Sign tính chữ ký hex HMAC-SHA256 của "<timestamp>.<body>", dùng để bên nhận kiểm tra webhook
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Trạng thái của batch khi mọi task con đã kết thúc
+const (
+	// BatchStatusCompleted là khi mọi task con completed
+	BatchStatusCompleted = "completed"
+	// BatchStatusPartial là khi chỉ một phần task con completed
+	BatchStatusPartial = "partial"
+	// BatchStatusFailed là khi không task con nào completed
+	BatchStatusFailed = "failed"
+)
+
+// BatchFinished cho biết batch ở status đã kết thúc hay chưa
+func BatchFinished(status string) bool {
+	return status == BatchStatusCompleted || status == BatchStatusPartial || status == BatchStatusFailed
+}
+
+// CreateBatch gọi POST /batches; dùng multipart khi có Files
+func (c *Client) CreateBatch(ctx context.Context, req BatchRequest) (*Batch, error) {
+	var batch Batch
+	if len(req.Files) == 0 {
+		body := struct {
+			Tool   string      `json:"tool"`
+			Params ToolInput   `json:"params"`
+			Inputs []ToolInput `json:"inputs"`
+		}{req.Tool, req.Params, req.Inputs}
+		if _, err := c.doJSON(ctx, http.MethodPost, "/batches", body, &batch); err != nil {
+			return nil, err
+		}
+		return &batch, nil
+	}
+
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{"tool": req.Tool, "params": string(params)}
+	if len(req.Inputs) > 0 {
+		inputs, err := json.Marshal(req.Inputs)
+		if err != nil {
+			return nil, err
+		}
+		fields["inputs"] = string(inputs)
+	}
+	files := make([]formFile, 0, len(req.Files))
+	for _, file := range req.Files {
+		files = append(files, formFile{field: "files", file: file})
+	}
+	if _, err := c.doMultipart(ctx, "/batches", fields, files, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// GetBatch gọi GET /batches/:id, trả về batch, tiến độ và các task con
+func (c *Client) GetBatch(ctx context.Context, id int) (*BatchDetail, error) {
+	var detail BatchDetail
+	if _, err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/batches/%d", id), nil, &detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
@@ -0,0 +1,196 @@
+// Package client là Go client cho management-api.
+//
+//	c := client.New("http://management_api:81")
+//	res, err := c.Translate(ctx, client.TranslateRequest{Text: "Hello", DestLang: "vi"})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client gọi các endpoint của management-api. Client an toàn khi dùng từ nhiều goroutine.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+type Option func(*Client)
+
+// WithHTTPClient dùng http.Client riêng, ví dụ để đặt timeout hoặc transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError là lỗi khi management-api trả về status khác 2xx
+type APIError struct {
+	StatusCode int
+	Message    string
+	// TaskID là ID task trong header X-Task-ID (0 nếu không có)
+	TaskID int
+	// Service và BackendStatus có khi lỗi đến từ service AI phía sau (status 502)
+	Service       string
+	BackendStatus int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("management-api returned %d: %s", e.StatusCode, e.Message)
+}
+
+// File là file được tải lên theo dạng stream, không đọc toàn bộ vào bộ nhớ
+type File struct {
+	Name   string
+	Reader io.Reader
+}
+
+// doJSON gửi body dạng JSON (bỏ qua nếu nil) và decode response vào out (bỏ qua nếu nil).
+// Trả về ID task trong header X-Task-ID.
+func (c *Client) doJSON(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(req, out)
+}
+
+// formFile là một file trong request multipart
+type formFile struct {
+	field string
+	file  File
+}
+
+// doMultipart gửi các trường form và file theo dạng stream qua io.Pipe
+func (c *Client) doMultipart(ctx context.Context, path string, fields map[string]string, files []formFile, out interface{}) (int, error) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		err := writeMultipart(mw, fields, files)
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, pr)
+	if err != nil {
+		pr.Close()
+		return 0, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return c.do(req, out)
+}
+
+func writeMultipart(mw *multipart.Writer, fields map[string]string, files []formFile) error {
+	for key, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := mw.WriteField(key, value); err != nil {
+			return err
+		}
+	}
+	for _, f := range files {
+		part, err := mw.CreateFormFile(f.field, f.file.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, f.file.Reader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Download tải file từ management-api (đường dẫn tương đối, ví dụ "/shared/2024-01-01/output.png")
+// hoặc từ URL đầy đủ, ví dụ audio_url của TTS, và ghi vào w
+func (c *Client) Download(ctx context.Context, pathOrURL string, w io.Writer) error {
+	url := pathOrURL
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = c.baseURL + "/" + strings.TrimLeft(url, "/")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("failed to download %s", url)}
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func (c *Client) do(req *http.Request, out interface{}) (int, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	taskID, _ := strconv.Atoi(resp.Header.Get("X-Task-ID"))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var body struct {
+			Error         string `json:"error"`
+			Service       string `json:"service"`
+			BackendStatus int    `json:"backend_status"`
+		}
+		raw, _ := io.ReadAll(resp.Body)
+		message := strings.TrimSpace(string(raw))
+		if json.Unmarshal(raw, &body) == nil && body.Error != "" {
+			message = body.Error
+		}
+		return taskID, &APIError{
+			StatusCode:    resp.StatusCode,
+			Message:       message,
+			TaskID:        taskID,
+			Service:       body.Service,
+			BackendStatus: body.BackendStatus,
+		}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return taskID, nil
+	}
+	return taskID, json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateFaceGallery gọi POST /face-galleries
+func (c *Client) CreateFaceGallery(ctx context.Context, name, description string) (*FaceGallery, error) {
+	var gallery FaceGallery
+	body := map[string]string{"name": name, "description": description}
+	if _, err := c.doJSON(ctx, http.MethodPost, "/face-galleries", body, &gallery); err != nil {
+		return nil, err
+	}
+	return &gallery, nil
+}
+
+// ListFaceGalleries gọi GET /face-galleries
+func (c *Client) ListFaceGalleries(ctx context.Context) ([]FaceGallery, error) {
+	var galleries []FaceGallery
+	if _, err := c.doJSON(ctx, http.MethodGet, "/face-galleries", nil, &galleries); err != nil {
+		return nil, err
+	}
+	return galleries, nil
+}
+
+// DeleteFaceGallery gọi DELETE /face-galleries/:name, xoá cả các khuôn mặt đã đăng ký
+func (c *Client) DeleteFaceGallery(ctx context.Context, name string) error {
+	_, err := c.doJSON(ctx, http.MethodDelete, "/face-galleries/"+url.PathEscape(name), nil, nil)
+	return err
+}
+
+// EnrollFace gọi POST /face-galleries/:name/enrollments
+func (c *Client) EnrollFace(ctx context.Context, gallery string, req EnrollRequest) (*FaceEnrollment, error) {
+	fields := map[string]string{"person_id": req.PersonID}
+	if req.Metadata != nil {
+		metadata, err := json.Marshal(req.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		fields["metadata"] = string(metadata)
+	}
+
+	var enrollment FaceEnrollment
+	path := "/face-galleries/" + url.PathEscape(gallery) + "/enrollments"
+	if _, err := c.doMultipart(ctx, path, fields, []formFile{{field: "image", file: req.Image}}, &enrollment); err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// ListFaceEnrollments gọi GET /face-galleries/:name/enrollments; personID khác rỗng thì chỉ lấy của người đó
+func (c *Client) ListFaceEnrollments(ctx context.Context, gallery, personID string) ([]FaceEnrollment, error) {
+	path := "/face-galleries/" + url.PathEscape(gallery) + "/enrollments"
+	if personID != "" {
+		path += "?person_id=" + url.QueryEscape(personID)
+	}
+	var enrollments []FaceEnrollment
+	if _, err := c.doJSON(ctx, http.MethodGet, path, nil, &enrollments); err != nil {
+		return nil, err
+	}
+	return enrollments, nil
+}
+
+// DeleteFaceEnrollment gọi DELETE /face-galleries/:name/enrollments/:id
+func (c *Client) DeleteFaceEnrollment(ctx context.Context, gallery string, id int) error {
+	_, err := c.doJSON(ctx, http.MethodDelete, fmt.Sprintf("/face-galleries/%s/enrollments/%d", url.PathEscape(gallery), id), nil, nil)
+	return err
+}
+
+// DeleteFacePerson gọi DELETE /face-galleries/:name/persons/:person_id và trả về số khuôn mặt đã xoá
+func (c *Client) DeleteFacePerson(ctx context.Context, gallery, personID string) (int, error) {
+	var resp struct {
+		Deleted int `json:"deleted"`
+	}
+	path := "/face-galleries/" + url.PathEscape(gallery) + "/persons/" + url.PathEscape(personID)
+	_, err := c.doJSON(ctx, http.MethodDelete, path, nil, &resp)
+	return resp.Deleted, err
+}
+
+// SearchFaces gọi POST /face-galleries/:name/search
+func (c *Client) SearchFaces(ctx context.Context, gallery string, req FaceSearchRequest) (*FaceSearchResponse, error) {
+	fields := map[string]string{}
+	if req.TopK > 0 {
+		fields["top_k"] = strconv.Itoa(req.TopK)
+	}
+	if req.MinSimilarity != nil {
+		fields["min_similarity"] = strconv.FormatFloat(*req.MinSimilarity, 'f', -1, 64)
+	}
+
+	var resp FaceSearchResponse
+	path := "/face-galleries/" + url.PathEscape(gallery) + "/search"
+	if _, err := c.doMultipart(ctx, path, fields, []formFile{{field: "image", file: req.Image}}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultPollInterval là khoảng thời gian giữa hai lần kiểm tra task trong WaitForTask
+const defaultPollInterval = time.Second
+
+// GetTask gọi GET /tasks/:id
+func (c *Client) GetTask(ctx context.Context, id int) (*Task, error) {
+	var task Task
+	if _, err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/tasks/%d", id), nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ListTasks gọi GET /tasks
+func (c *Client) ListTasks(ctx context.Context) ([]Task, error) {
+	var tasks []Task
+	if _, err := c.doJSON(ctx, http.MethodGet, "/tasks", nil, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// CancelTask gọi POST /tasks/:id/cancel
+func (c *Client) CancelTask(ctx context.Context, id int) (*Task, error) {
+	var task Task
+	if _, err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("/tasks/%d/cancel", id), nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// RetryTask gọi POST /tasks/:id/retry và trả về task mới (đang chạy ở background)
+func (c *Client) RetryTask(ctx context.Context, id int, req RetryRequest) (*Task, error) {
+	var task Task
+	if _, err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("/tasks/%d/retry", id), req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetTaskDeliveries gọi GET /tasks/:id/deliveries
+func (c *Client) GetTaskDeliveries(ctx context.Context, id int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	if _, err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/tasks/%d/deliveries", id), nil, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// WaitForTask kiểm tra task mỗi interval (mặc định 1 giây) cho tới khi task kết thúc hoặc ctx bị huỷ.
+// Task kết thúc được trả về kể cả khi failed hoặc cancelled; kiểm tra Status để biết kết quả.
+func (c *Client) WaitForTask(ctx context.Context, id int, interval time.Duration) (*Task, error) {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		task, err := c.GetTask(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if task.Status.IsTerminal() {
+			return task, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return task, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
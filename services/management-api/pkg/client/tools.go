@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TextToVoice gọi POST /tts
+func (c *Client) TextToVoice(ctx context.Context, req TextToVoiceRequest) (*TextToVoiceResponse, error) {
+	var resp TextToVoiceResponse
+	taskID, err := c.doJSON(ctx, http.MethodPost, "/tts", req, &resp)
+	resp.TaskID = taskID
+	return &resp, err
+}
+
+// VoiceToText gọi POST /vts
+func (c *Client) VoiceToText(ctx context.Context, req AudioRequest) (*TextResponse, error) {
+	var resp TextResponse
+	taskID, err := c.doJSON(ctx, http.MethodPost, "/vts", req, &resp)
+	resp.TaskID = taskID
+	return &resp, err
+}
+
+// SpeechRecognition gọi POST /speech-recognition
+func (c *Client) SpeechRecognition(ctx context.Context, req AudioRequest) (*TextResponse, error) {
+	var resp TextResponse
+	taskID, err := c.doJSON(ctx, http.MethodPost, "/speech-recognition", req, &resp)
+	resp.TaskID = taskID
+	return &resp, err
+}
+
+// RemoveBackground gọi POST /remove-bg
+func (c *Client) RemoveBackground(ctx context.Context, req RemoveBackgroundRequest) (*RemoveBackgroundResponse, error) {
+	var resp RemoveBackgroundResponse
+	fields := map[string]string{
+		"background_color": req.BackgroundColor,
+		"format":           req.Format,
+		"callback_url":     req.CallbackURL,
+	}
+	for name, value := range map[string]bool{"alpha_matting": req.AlphaMatting, "crop": req.Crop, "mask_only": req.MaskOnly} {
+		if value {
+			fields[name] = "true"
+		}
+	}
+	if req.CropPadding > 0 {
+		fields["crop_padding"] = strconv.Itoa(req.CropPadding)
+	}
+	files := []formFile{{field: "image", file: req.Image}}
+	if req.Background.Reader != nil {
+		files = append(files, formFile{field: "background_image", file: req.Background})
+	}
+	taskID, err := c.doMultipart(ctx, "/remove-bg", fields, files, &resp)
+	resp.TaskID = taskID
+	return &resp, err
+}
+
+// FaceRecognition gọi POST /face-recognition
+func (c *Client) FaceRecognition(ctx context.Context, req ImageRequest) (*FaceRecognitionResponse, error) {
+	var resp FaceRecognitionResponse
+	fields := map[string]string{"callback_url": req.CallbackURL}
+	preprocessFields(fields, req.Preprocess, req.MaxDimension)
+	taskID, err := c.doMultipart(ctx, "/face-recognition", fields, []formFile{{field: "image", file: req.Image}}, &resp)
+	resp.TaskID = taskID
+	return &resp, err
+}
+
+// FaceVerify gọi POST /face-verify
+func (c *Client) FaceVerify(ctx context.Context, req FaceVerifyRequest) (*FaceVerifyResponse, error) {
+	var resp FaceVerifyResponse
+	fields := map[string]string{
+		"gallery":      req.Gallery,
+		"person_id":    req.PersonID,
+		"callback_url": req.CallbackURL,
+	}
+	if req.Threshold != 0 {
+		fields["threshold"] = strconv.FormatFloat(req.Threshold, 'f', -1, 64)
+	}
+	files := []formFile{{field: "image", file: req.Image}}
+	if req.Reference.Reader != nil {
+		files = append(files, formFile{field: "reference_image", file: req.Reference})
+	}
+	taskID, err := c.doMultipart(ctx, "/face-verify", fields, files, &resp)
+	resp.TaskID = taskID
+	return &resp, err
+}
+
+// FaceAnonymize gọi POST /face-anonymize
+func (c *Client) FaceAnonymize(ctx context.Context, req FaceAnonymizeRequest) (*FaceAnonymizeResponse, error) {
+	var resp FaceAnonymizeResponse
+	fields := map[string]string{"mode": req.Mode, "callback_url": req.CallbackURL}
+	if req.Strength > 0 {
+		fields["strength"] = strconv.Itoa(req.Strength)
+	}
+	preprocessFields(fields, req.Preprocess, req.MaxDimension)
+	taskID, err := c.doMultipart(ctx, "/face-anonymize", fields, []formFile{{field: "image", file: req.Image}}, &resp)
+	resp.TaskID = taskID
+	return &resp, err
+}
+
+// OCR gọi POST /ocr
+func (c *Client) OCR(ctx context.Context, req OCRRequest) (*OCRResponse, error) {
+	var resp OCRResponse
+	fields := map[string]string{
+		"languages":    req.Languages,
+		"output":       req.Output,
+		"callback_url": req.CallbackURL,
+	}
+	if req.SearchablePDF {
+		fields["searchable_pdf"] = "true"
+	}
+	preprocessFields(fields, req.Preprocess, req.MaxDimension)
+	taskID, err := c.doMultipart(ctx, "/ocr", fields, []formFile{{field: "image", file: req.Image}}, &resp)
+	resp.TaskID = taskID
+	return &resp, err
+}
+
+// preprocessFields thêm các trường form tiền xử lý ảnh (preprocess, max_dimension) vào fields
+func preprocessFields(fields map[string]string, steps []string, maxDimension int) {
+	if len(steps) > 0 {
+		fields["preprocess"] = strings.Join(steps, ",")
+	}
+	if maxDimension > 0 {
+		fields["max_dimension"] = strconv.Itoa(maxDimension)
+	}
+}
+
+// Translate gọi POST /translate
+func (c *Client) Translate(ctx context.Context, req TranslateRequest) (*TranslateResponse, error) {
+	var resp TranslateResponse
+	taskID, err := c.doJSON(ctx, http.MethodPost, "/translate", req, &resp)
+	resp.TaskID = taskID
+	return &resp, err
+}
+
+// ProcessedImageURL là đường dẫn tải ảnh kết quả qua management-api (dùng với Client.Download):
+// URL của artifact nếu có, ngược lại là đường dẫn trong /shared
+func (r *RemoveBackgroundResponse) ProcessedImageURL() string {
+	if r.URL != "" {
+		return r.URL
+	}
+	return "/shared/" + strings.TrimLeft(r.ProcessedImagePath, "/")
+}
+
+// GetArtifact gọi GET /artifacts/:id
+func (c *Client) GetArtifact(ctx context.Context, id string) (*Artifact, error) {
+	var artifact Artifact
+	if _, err := c.doJSON(ctx, http.MethodGet, "/artifacts/"+url.PathEscape(id), nil, &artifact); err != nil {
+		return nil, err
+	}
+	return &artifact, nil
+}
+
+// AnonymizedImageURL là đường dẫn tải ảnh đã che khuôn mặt qua management-api (dùng với Client.Download):
+// URL của artifact nếu có, ngược lại là đường dẫn trong /shared
+func (r *FaceAnonymizeResponse) AnonymizedImageURL() string {
+	if r.ImageURL != "" {
+		return r.ImageURL
+	}
+	return "/shared/" + strings.TrimLeft(r.ImagePath, "/")
+}
+
+// SearchablePDFURL là đường dẫn tải searchable PDF qua management-api (dùng với Client.Download):
+// URL của artifact nếu có, ngược lại là đường dẫn trong /shared; rỗng nếu request không yêu cầu searchable PDF
+func (r *OCRResponse) SearchablePDFURL() string {
+	if r.SearchablePDFArtifactURL != "" {
+		return r.SearchablePDFArtifactURL
+	}
+	if r.SearchablePDF == "" {
+		return ""
+	}
+	return "/shared/" + strings.TrimLeft(r.SearchablePDF, "/")
+}
+
+// UploadAudio gọi POST /upload-audio
+func (c *Client) UploadAudio(ctx context.Context, audio File) (*UploadAudioResponse, error) {
+	var resp UploadAudioResponse
+	_, err := c.doMultipart(ctx, "/upload-audio", nil, []formFile{{field: "audio", file: audio}}, &resp)
+	return &resp, err
+}
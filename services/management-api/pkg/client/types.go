@@ -0,0 +1,498 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TaskStatus là trạng thái của task
+type TaskStatus string
+
+const (
+	TaskStatusPending    TaskStatus = "pending"
+	TaskStatusQueued     TaskStatus = "queued"
+	TaskStatusProcessing TaskStatus = "processing"
+	TaskStatusCompleted  TaskStatus = "completed"
+	TaskStatusFailed     TaskStatus = "failed"
+	TaskStatusCancelled  TaskStatus = "cancelled"
+	TaskStatusExpired    TaskStatus = "expired"
+)
+
+// IsTerminal cho biết task đã kết thúc
+func (s TaskStatus) IsTerminal() bool {
+	switch s {
+	case TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled, TaskStatusExpired:
+		return true
+	}
+	return false
+}
+
+type Task struct {
+	ID                int             `json:"id"`
+	ServiceName       string          `json:"service_name"`
+	Status            TaskStatus      `json:"status"`
+	InputData         json.RawMessage `json:"input_data"`
+	OutputData        json.RawMessage `json:"output_data"`
+	BatchID           *int            `json:"batch_id,omitempty"`
+	PipelineID        *int            `json:"pipeline_id,omitempty"`
+	PipelineStep      *string         `json:"pipeline_step,omitempty"`
+	TemplateVersionID *int            `json:"template_version_id,omitempty"`
+	CallbackURL       *string         `json:"callback_url,omitempty"`
+	Attempt           int             `json:"attempt"`
+	ParentTaskID      *int            `json:"parent_task_id,omitempty"`
+	// DocumentTaskID và PageNumber có ở task OCR một trang của tài liệu nhiều trang
+	DocumentTaskID *int       `json:"document_task_id,omitempty"`
+	PageNumber     *int       `json:"page_number,omitempty"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// DecodeOutput decode output_data của task vào out, ví dụ *TranslateResponse
+func (t *Task) DecodeOutput(out interface{}) error {
+	return json.Unmarshal(t.OutputData, out)
+}
+
+type WebhookDelivery struct {
+	ID         int       `json:"id"`
+	TaskID     int       `json:"task_id"`
+	URL        string    `json:"url"`
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+	StatusCode *int      `json:"status_code,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RetryRequest là body của POST /tasks/:id/retry
+type RetryRequest struct {
+	// Overrides ghi đè các trường input của task gốc, ví dụ {"language": "fr"}
+	Overrides   map[string]interface{} `json:"overrides,omitempty"`
+	CallbackURL string                 `json:"callback_url,omitempty"`
+}
+
+type TextToVoiceRequest struct {
+	Text        string `json:"text"`
+	Language    string `json:"language,omitempty"`
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+// SpeechMark là mốc thời gian của một từ hoặc một câu trong audio
+type SpeechMark struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	StartMs   int    `json:"start_ms"`
+	EndMs     int    `json:"end_ms"`
+	CharStart int    `json:"char_start"`
+	CharEnd   int    `json:"char_end"`
+}
+
+type TextToVoiceResponse struct {
+	TaskID      int          `json:"-"`
+	AudioURL    string       `json:"audio_url"`
+	Marks       []SpeechMark `json:"marks"`
+	MarksSource string       `json:"marks_source,omitempty"`
+}
+
+// AudioRequest là đầu vào của voice-to-text và speech-recognition
+type AudioRequest struct {
+	AudioURL    string `json:"audio_url"`
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+// ImageRequest là đầu vào của face-recognition; Preprocess và MaxDimension xem OCRRequest
+type ImageRequest struct {
+	Image        File
+	Preprocess   []string
+	MaxDimension int
+	CallbackURL  string
+}
+
+// Các bước tiền xử lý ảnh; face-recognition chỉ hỗ trợ PreprocessAutoOrient và PreprocessDownscale
+const (
+	PreprocessAutoOrient = "auto_orient"
+	PreprocessDownscale  = "downscale"
+	PreprocessGrayscale  = "grayscale"
+	PreprocessBinarize   = "binarize"
+	PreprocessDeskew     = "deskew"
+)
+
+// TextResponse là kết quả của voice-to-text và speech-recognition
+type TextResponse struct {
+	TaskID int    `json:"-"`
+	Text   string `json:"text"`
+}
+
+// Định dạng kết quả OCR
+const (
+	OCROutputText = "text"
+	OCROutputHOCR = "hocr"
+	OCROutputJSON = "json"
+	OCROutputTSV  = "tsv"
+)
+
+// OCRRequest là đầu vào của ocr. Image là ảnh, hoặc tài liệu PDF/TIFF nhiều trang.
+// Languages là mã ngôn ngữ tesseract (ví dụ "vie+eng"), Output là một trong các OCROutput*;
+// giá trị rỗng dùng mặc định ("eng", "text"). SearchablePDF tạo thêm PDF có lớp text.
+// Preprocess là các bước Preprocess* áp dụng lên ảnh trước khi OCR; MaxDimension là cạnh dài
+// tối đa cho PreprocessDownscale (0 dùng mặc định của server).
+type OCRRequest struct {
+	Image         File
+	Languages     string
+	Output        string
+	SearchablePDF bool
+	Preprocess    []string
+	MaxDimension  int
+	CallbackURL   string
+}
+
+// OCRResponse là kết quả của ocr. Orientation và Blocks chỉ có với output json,
+// HOCR với output hocr, TSV với output tsv. Với tài liệu nhiều trang, Text là text
+// của các trang nối bằng "\f", PageCount và Pages là kết quả từng trang.
+type OCRResponse struct {
+	TaskID        int             `json:"-"`
+	Text          string          `json:"text"`
+	Languages     string          `json:"languages"`
+	Orientation   *OCROrientation `json:"orientation"`
+	Blocks        []OCRBlock      `json:"blocks"`
+	HOCR          string          `json:"hocr"`
+	TSV           string          `json:"tsv"`
+	PageCount     int             `json:"page_count"`
+	Pages         []OCRPage       `json:"pages"`
+	SearchablePDF string          `json:"searchable_pdf"`
+	// SearchablePDFArtifactID và SearchablePDFArtifactURL là artifact của searchable PDF, xem Client.GetArtifact;
+	// tải PDF qua SearchablePDFURL
+	SearchablePDFArtifactID  string `json:"searchable_pdf_artifact_id"`
+	SearchablePDFArtifactURL string `json:"searchable_pdf_url"`
+}
+
+// OCRPage là kết quả một trang của tài liệu; TaskID là task con của trang.
+// Result chỉ có khi trang completed, Error khi trang failed.
+type OCRPage struct {
+	Page   int          `json:"page"`
+	TaskID int          `json:"task_id"`
+	Status TaskStatus   `json:"status"`
+	Error  string       `json:"error"`
+	Result *OCRResponse `json:"result"`
+}
+
+// OCROrientation là hướng trang: Rotate là số độ cần xoay để trang đứng thẳng
+type OCROrientation struct {
+	Rotate     int     `json:"rotate"`
+	Confidence float64 `json:"confidence"`
+}
+
+// BoundingBox là một vùng trong ảnh (vùng chữ của OCR, chủ thể của remove-bg), tính theo pixel
+type BoundingBox struct {
+	Left   int `json:"left"`
+	Top    int `json:"top"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// OCRBlock là một khối chữ; Confidence từ 0 đến 100, -1 nếu không xác định
+type OCRBlock struct {
+	Text       string      `json:"text"`
+	BBox       BoundingBox `json:"bbox"`
+	Confidence float64     `json:"confidence"`
+	Lines      []OCRLine   `json:"lines"`
+}
+
+type OCRLine struct {
+	Text       string      `json:"text"`
+	BBox       BoundingBox `json:"bbox"`
+	Confidence float64     `json:"confidence"`
+	Words      []OCRWord   `json:"words"`
+}
+
+type OCRWord struct {
+	Text       string      `json:"text"`
+	BBox       BoundingBox `json:"bbox"`
+	Confidence float64     `json:"confidence"`
+}
+
+// Định dạng ảnh kết quả của remove-bg
+const (
+	ImageFormatPNG  = "png"
+	ImageFormatWebP = "webp"
+)
+
+// RemoveBackgroundRequest là đầu vào của remove-bg. BackgroundColor ("#rrggbb" hoặc "#rrggbbaa")
+// hoặc Background là nền thay thế, không có thì nền trong suốt. Crop cắt ảnh theo khung chủ thể,
+// chừa CropPadding pixel mỗi phía; MaskOnly trả về mask thay cho ảnh; Format là một trong các
+// ImageFormat*, rỗng là PNG.
+type RemoveBackgroundRequest struct {
+	Image           File
+	Background      File
+	BackgroundColor string
+	AlphaMatting    bool
+	Crop            bool
+	CropPadding     int
+	MaskOnly        bool
+	Format          string
+	CallbackURL     string
+}
+
+// RemoveBackgroundResponse là kết quả của remove-bg. ArtifactID và URL là ảnh kết quả, xem
+// Client.GetArtifact. Format, Width và Height chỉ có khi request dùng nền thay thế, Crop, MaskOnly
+// hoặc WebP; SubjectBox là khung chủ thể trong ảnh gốc khi Crop.
+type RemoveBackgroundResponse struct {
+	TaskID             int          `json:"-"`
+	ProcessedImagePath string       `json:"processed_image_path"`
+	ArtifactID         string       `json:"artifact_id"`
+	URL                string       `json:"url"`
+	Format             string       `json:"format"`
+	Width              int          `json:"width"`
+	Height             int          `json:"height"`
+	SubjectBox         *BoundingBox `json:"subject_box"`
+}
+
+// Artifact là file kết quả của task. URL là đường dẫn tải file (dùng với Client.Download);
+// Width và Height bằng 0 nếu file không phải ảnh.
+type Artifact struct {
+	ID          string    `json:"id"`
+	TaskID      int       `json:"task_id"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	URL         string    `json:"url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type FaceRecognitionResponse struct {
+	TaskID    int       `json:"-"`
+	FaceCount int       `json:"face_count"`
+	Faces     []FaceBox `json:"faces"`
+}
+
+// FaceBox là vị trí một khuôn mặt trong ảnh, tính theo pixel
+type FaceBox struct {
+	Top    int `json:"top"`
+	Right  int `json:"right"`
+	Bottom int `json:"bottom"`
+	Left   int `json:"left"`
+}
+
+// Các cách che khuôn mặt của face-anonymize
+const (
+	AnonymizeBlur     = "blur"
+	AnonymizePixelate = "pixelate"
+	AnonymizeBox      = "box"
+)
+
+// FaceAnonymizeRequest là đầu vào của face-anonymize. Mode rỗng là blur; Strength từ 1 đến 100,
+// 0 dùng mặc định của server. Preprocess chỉ hỗ trợ PreprocessAutoOrient và PreprocessDownscale.
+type FaceAnonymizeRequest struct {
+	Image        File
+	Mode         string
+	Strength     int
+	Preprocess   []string
+	MaxDimension int
+	CallbackURL  string
+}
+
+// FaceAnonymizeResponse là kết quả của face-anonymize; ảnh đã che khuôn mặt là artifact ArtifactID
+// và ImageURL, tải qua AnonymizedImageURL
+type FaceAnonymizeResponse struct {
+	TaskID     int       `json:"-"`
+	FaceCount  int       `json:"face_count"`
+	Faces      []FaceBox `json:"faces"`
+	Mode       string    `json:"mode"`
+	Strength   int       `json:"strength"`
+	ImagePath  string    `json:"image_path"`
+	ArtifactID string    `json:"artifact_id"`
+	ImageURL   string    `json:"image_url"`
+}
+
+// FaceVerifyRequest là đầu vào của face-verify: Image được so với Reference (ví dụ ảnh CCCD),
+// hoặc với người PersonID đã đăng ký trong Gallery. Threshold bằng 0 dùng ngưỡng mặc định của server.
+type FaceVerifyRequest struct {
+	Image       File
+	Reference   File
+	Gallery     string
+	PersonID    string
+	Threshold   float64
+	CallbackURL string
+}
+
+// FaceVerifyResponse là kết quả so khớp 1:1; Match khi Similarity từ Threshold trở lên
+type FaceVerifyResponse struct {
+	TaskID     int            `json:"-"`
+	Match      bool           `json:"match"`
+	Similarity float64        `json:"similarity"`
+	Threshold  float64        `json:"threshold"`
+	Probe      FaceVerifyFace `json:"probe"`
+	Reference  FaceVerifyFace `json:"reference"`
+}
+
+// FaceVerifyFace là khuôn mặt được đem so khớp. Quality và Liveness chỉ có khi khuôn mặt lấy từ ảnh;
+// Liveness chỉ là gợi ý heuristic (ảnh in, chụp lại màn hình).
+type FaceVerifyFace struct {
+	Face         FaceBox       `json:"face"`
+	FaceCount    int           `json:"face_count"`
+	Gallery      string        `json:"gallery"`
+	PersonID     string        `json:"person_id"`
+	EnrollmentID int           `json:"enrollment_id"`
+	Quality      *FaceQuality  `json:"quality"`
+	Liveness     *FaceLiveness `json:"liveness"`
+}
+
+// FaceQuality là chất lượng ảnh của vùng khuôn mặt; Score từ 0 đến 1
+type FaceQuality struct {
+	Score      float64  `json:"score"`
+	Width      int      `json:"width"`
+	Height     int      `json:"height"`
+	Brightness float64  `json:"brightness"`
+	Contrast   float64  `json:"contrast"`
+	Sharpness  float64  `json:"sharpness"`
+	Issues     []string `json:"issues"`
+}
+
+type FaceLiveness struct {
+	Suspicious bool     `json:"suspicious"`
+	Hints      []string `json:"hints"`
+	Saturation float64  `json:"saturation"`
+	Glare      float64  `json:"glare"`
+}
+
+type TranslateRequest struct {
+	Text        string `json:"text"`
+	DestLang    string `json:"dest_lang"`
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+type TranslateResponse struct {
+	TaskID         int    `json:"-"`
+	TranslatedText string `json:"translated_text"`
+}
+
+type UploadAudioResponse struct {
+	AudioURL string `json:"audio_url"`
+}
+
+// ToolInput là input của một task trong batch
+type ToolInput struct {
+	Text     string `json:"text,omitempty" yaml:"text,omitempty"`
+	Language string `json:"language,omitempty" yaml:"language,omitempty"`
+	DestLang string `json:"dest_lang,omitempty" yaml:"dest_lang,omitempty"`
+	AudioURL string `json:"audio_url,omitempty" yaml:"audio_url,omitempty"`
+	// URL là file hoặc text được server tải về trước khi chạy tool
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+	// Languages và Output là tuỳ chọn của ocr, xem OCRRequest
+	Languages string `json:"languages,omitempty" yaml:"languages,omitempty"`
+	Output    string `json:"output,omitempty" yaml:"output,omitempty"`
+	// SearchablePDF yêu cầu ocr tạo thêm PDF có lớp text
+	SearchablePDF bool `json:"searchable_pdf,omitempty" yaml:"searchable_pdf,omitempty"`
+	// Preprocess và MaxDimension là tuỳ chọn tiền xử lý ảnh của ocr, face-recognition và face-anonymize
+	Preprocess   []string `json:"preprocess,omitempty" yaml:"preprocess,omitempty"`
+	MaxDimension int      `json:"max_dimension,omitempty" yaml:"max_dimension,omitempty"`
+	// ReferenceURL, Gallery, PersonID và Threshold là tuỳ chọn của face-verify, xem FaceVerifyRequest
+	ReferenceURL string  `json:"reference_url,omitempty" yaml:"reference_url,omitempty"`
+	Gallery      string  `json:"gallery,omitempty" yaml:"gallery,omitempty"`
+	PersonID     string  `json:"person_id,omitempty" yaml:"person_id,omitempty"`
+	Threshold    float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	// Mode và Strength là tuỳ chọn của face-anonymize, xem FaceAnonymizeRequest
+	Mode     string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Strength int    `json:"strength,omitempty" yaml:"strength,omitempty"`
+	// Các trường Background*, AlphaMatting, Crop, CropPadding, MaskOnly và Format là tuỳ chọn
+	// của remove-bg, xem RemoveBackgroundRequest; BackgroundURL là ảnh nền được server tải về
+	BackgroundColor string `json:"background_color,omitempty" yaml:"background_color,omitempty"`
+	BackgroundURL   string `json:"background_url,omitempty" yaml:"background_url,omitempty"`
+	AlphaMatting    bool   `json:"alpha_matting,omitempty" yaml:"alpha_matting,omitempty"`
+	Crop            bool   `json:"crop,omitempty" yaml:"crop,omitempty"`
+	CropPadding     int    `json:"crop_padding,omitempty" yaml:"crop_padding,omitempty"`
+	MaskOnly        bool   `json:"mask_only,omitempty" yaml:"mask_only,omitempty"`
+	Format          string `json:"format,omitempty" yaml:"format,omitempty"`
+}
+
+// BatchRequest là đầu vào của POST /batches. Files được tải lên và thêm vào cuối Inputs.
+type BatchRequest struct {
+	Tool   string
+	Params ToolInput
+	Inputs []ToolInput
+	Files  []File
+}
+
+type BatchProgress struct {
+	Total      int     `json:"total"`
+	Pending    int     `json:"pending"`
+	Queued     int     `json:"queued"`
+	Processing int     `json:"processing"`
+	Completed  int     `json:"completed"`
+	Failed     int     `json:"failed"`
+	Cancelled  int     `json:"cancelled"`
+	Expired    int     `json:"expired"`
+	Percent    float64 `json:"percent"`
+}
+
+type Batch struct {
+	ID        int           `json:"id"`
+	Tool      string        `json:"tool"`
+	Status    string        `json:"status"`
+	Progress  BatchProgress `json:"progress"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// BatchDetail là kết quả của GET /batches/:id
+type BatchDetail struct {
+	Batch Batch  `json:"batch"`
+	Tasks []Task `json:"tasks"`
+}
+
+// FaceGallery là một tập người đã đăng ký khuôn mặt
+type FaceGallery struct {
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	PersonCount     int       `json:"person_count"`
+	EnrollmentCount int       `json:"enrollment_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// EnrollRequest là đầu vào của POST /face-galleries/:name/enrollments. Image phải có đúng một khuôn mặt.
+type EnrollRequest struct {
+	Image    File
+	PersonID string
+	Metadata map[string]interface{}
+}
+
+// FaceEnrollment là một khuôn mặt đã đăng ký của một người
+type FaceEnrollment struct {
+	ID        int                    `json:"id"`
+	GalleryID int                    `json:"gallery_id"`
+	PersonID  string                 `json:"person_id"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	Face      FaceBox                `json:"face"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// FaceSearchRequest là đầu vào của POST /face-galleries/:name/search.
+// TopK bằng 0 dùng mặc định của server (5); MinSimilarity nil thì không lọc theo similarity.
+type FaceSearchRequest struct {
+	Image         File
+	TopK          int
+	MinSimilarity *float64
+}
+
+// FaceMatch là một người giống khuôn mặt cần tìm; Similarity là cosine similarity (-1 đến 1)
+type FaceMatch struct {
+	PersonID     string                 `json:"person_id"`
+	EnrollmentID int                    `json:"enrollment_id"`
+	Similarity   float64                `json:"similarity"`
+	Metadata     map[string]interface{} `json:"metadata"`
+}
+
+// FaceSearchResponse là kết quả tìm kiếm: mỗi khuôn mặt trong ảnh cùng các người giống nhất
+type FaceSearchResponse struct {
+	Gallery   string `json:"gallery"`
+	FaceCount int    `json:"face_count"`
+	Faces     []struct {
+		Face    FaceBox     `json:"face"`
+		Matches []FaceMatch `json:"matches"`
+	} `json:"faces"`
+}
@@ -1,12 +1,78 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 )
 
+const (
+	// MaxDownloadSize là kích thước tối đa của file tải từ URL do client gửi
+	MaxDownloadSize = 100 << 20
+	downloadTimeout = 2 * time.Minute
+)
+
+// ErrBlockedAddress là lỗi khi URL trỏ tới địa chỉ nội bộ (loopback, mạng riêng, link-local...)
+var ErrBlockedAddress = errors.New("URL points to a blocked address")
+
+// downloadClient chỉ kết nối tới địa chỉ công khai, xem NewPublicTransport
+var downloadClient = &http.Client{
+	Timeout:   downloadTimeout,
+	Transport: NewPublicTransport(),
+}
+
+// NewPublicTransport tạo transport chỉ kết nối tới địa chỉ công khai, dùng cho URL do client gửi
+// (file cần tải, callback_url...). Địa chỉ được kiểm tra sau khi phân giải DNS nên cũng chặn cả
+// redirect và tên miền trỏ về mạng nội bộ.
+func NewPublicTransport() *http.Transport {
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || isBlockedIP(ip) {
+					return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	}
+}
+
+// CheckPublicURL kiểm tra rawURL là URL http/https và không trỏ thẳng tới địa chỉ nội bộ
+// (IP nội bộ hoặc localhost). Tên miền khác chỉ được kiểm tra khi kết nối qua NewPublicTransport.
+func CheckPublicURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid URL '%s'", rawURL)
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); (ip != nil && isBlockedIP(ip)) || strings.EqualFold(host, "localhost") {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+func isBlockedIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
 //// SaveUploadedFile lưu file từ form và trả về đường dẫn của file đã lưu
 //func SaveUploadedFile(file multipart.File, header *multipart.FileHeader, uploadPath string) (string, error) {
 //	// Tạo thư mục nếu chưa tồn tại
@@ -60,3 +126,49 @@ func SaveUploadedFile(file io.Reader, header *multipart.FileHeader, uploadPath s
 
 	return filePath, nil
 }
+
+// DownloadFile tải file từ URL về thư mục downloadPath và trả về đường dẫn file đã lưu.
+// Chỉ tải từ địa chỉ công khai, file lớn hơn MaxDownloadSize bị từ chối.
+func DownloadFile(fileURL, downloadPath string) (string, error) {
+	u, err := url.Parse(fileURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("invalid URL '%s'", fileURL)
+	}
+
+	resp, err := downloadClient.Get(fileURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download '%s' failed with StatusCode: %d", fileURL, resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(downloadPath, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	filename := path.Base(u.Path)
+	if filename == "/" || filename == "." {
+		filename = "download"
+	}
+
+	out, err := os.CreateTemp(downloadPath, "*_"+filename)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	n, err := io.Copy(out, io.LimitReader(resp.Body, MaxDownloadSize+1))
+	if err == nil && n > MaxDownloadSize {
+		err = fmt.Errorf("download '%s' exceeds %d bytes", fileURL, MaxDownloadSize)
+	}
+	if err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return "", err
+	}
+
+	return out.Name(), nil
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckPublicURL(t *testing.T) {
+	for _, tc := range []struct {
+		url     string
+		blocked bool
+		invalid bool
+	}{
+		{url: "https://example.com/hooks"},
+		{url: "http://8.8.8.8/hooks"},
+		{url: "http://127.0.0.1:8080/hooks", blocked: true},
+		{url: "http://LOCALHOST/hooks", blocked: true},
+		{url: "http://10.0.0.5/hooks", blocked: true},
+		{url: "http://169.254.169.254/latest/meta-data", blocked: true},
+		{url: "http://[::1]/hooks", blocked: true},
+		{url: "ftp://example.com/hooks", invalid: true},
+		{url: "http:///hooks", invalid: true},
+	} {
+		err := CheckPublicURL(tc.url)
+		switch {
+		case tc.blocked && !errors.Is(err, ErrBlockedAddress):
+			t.Errorf("CheckPublicURL(%q) = %v, want ErrBlockedAddress", tc.url, err)
+		case tc.invalid && (err == nil || errors.Is(err, ErrBlockedAddress)):
+			t.Errorf("CheckPublicURL(%q) = %v, want invalid URL error", tc.url, err)
+		case !tc.blocked && !tc.invalid && err != nil:
+			t.Errorf("CheckPublicURL(%q) = %v, want nil", tc.url, err)
+		}
+	}
+}
@@ -0,0 +1,529 @@
+package fakes
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+	"management-api/internal/service"
+)
+
+// contractCase là một lần gọi tool qua backend giả cùng kết quả mong đợi
+type contractCase struct {
+	Name  string
+	Tool  string
+	Input domain.ToolInput
+	// Check kiểm tra output của tool khi tool chạy thành công
+	Check func(output interface{}) error
+	// WantError khác rỗng nghĩa là backend trả lỗi: tool phải trả về lỗi có chứa thông báo này
+	WantError string
+}
+
+// contractCases trả về các case contract của mọi tool. File đầu vào (ảnh, audio) được tạo trong dir.
+func contractCases(dir string) ([]contractCase, error) {
+	imagePath := filepath.Join(dir, "contract.png")
+	if err := writePNG(imagePath); err != nil {
+		return nil, err
+	}
+	audioPath := filepath.Join(dir, "contract.wav")
+	if err := os.WriteFile(audioPath, []byte("RIFF\x00\x00\x00\x00WAVE"), 0644); err != nil {
+		return nil, err
+	}
+	brokenImagePath := filepath.Join(dir, "broken.png")
+	if err := os.WriteFile(brokenImagePath, []byte("not an image"), 0644); err != nil {
+		return nil, err
+	}
+	photoPath := filepath.Join(dir, "photo.jpg")
+	if err := writeJPEG(photoPath, 16, 8, 6); err != nil {
+		return nil, err
+	}
+	referencePath := filepath.Join(dir, "reference.png")
+	if err := writePNG(referencePath); err != nil {
+		return nil, err
+	}
+	// Backend giả trả lại chính ảnh gửi lên, nên ảnh có nền trong suốt đóng vai ảnh đã xoá nền
+	cutoutPath := filepath.Join(dir, "cutout.png")
+	if err := writeCutout(cutoutPath); err != nil {
+		return nil, err
+	}
+	tiffPath := filepath.Join(dir, "contract.tiff")
+	if err := writeTIFF(tiffPath, []image.Point{{8, 8}, {16, 8}, {8, 16}}); err != nil {
+		return nil, err
+	}
+
+	return []contractCase{
+		{
+			Name:  "tts",
+			Tool:  domain.ToolTextToVoice,
+			Input: domain.ToolInput{Text: "hello world", Language: "en"},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.TextToVoiceResult)
+				if !ok || result.AudioURL == "" {
+					return fmt.Errorf("missing 'audio_url' in %#v", output)
+				}
+				if len(result.Marks) != 2 || result.Marks[1].Value != "world" || result.Marks[1].CharStart != 6 {
+					return fmt.Errorf("unexpected marks %+v", result.Marks)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "tts error",
+			Tool:      domain.ToolTextToVoice,
+			Input:     domain.ToolInput{Text: " "},
+			WantError: "TTS conversion failed",
+		},
+		{
+			Name:  "vts",
+			Tool:  domain.ToolVoiceToText,
+			Input: domain.ToolInput{AudioURL: audioPath},
+			Check: checkText,
+		},
+		{
+			Name:      "vts-outside-uploads",
+			Tool:      domain.ToolVoiceToText,
+			Input:     domain.ToolInput{AudioURL: "/etc/passwd"},
+			WantError: "must be an http(s) URL or a file in the upload directories",
+		},
+		{
+			Name:  "speech-recognition",
+			Tool:  domain.ToolSpeechRecognition,
+			Input: domain.ToolInput{AudioURL: audioPath},
+			Check: checkText,
+		},
+		{
+			Name:  "remove-bg",
+			Tool:  domain.ToolBackgroundRemoval,
+			Input: domain.ToolInput{FilePath: imagePath},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.BackgroundRemovalResult)
+				if !ok || result.ProcessedImagePath == "" {
+					return fmt.Errorf("missing 'processed_image_path' in %#v", output)
+				}
+				if filepath.IsAbs(result.ProcessedImagePath) {
+					return fmt.Errorf("'processed_image_path' must be relative, got %s", result.ProcessedImagePath)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "remove-bg crop with background colour",
+			Tool:  domain.ToolBackgroundRemoval,
+			Input: domain.ToolInput{FilePath: cutoutPath, Crop: true, CropPadding: 2, BackgroundColor: "#ff0000"},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.BackgroundRemovalResult)
+				want := domain.BoundingBox{Left: 6, Top: 4, Width: 4, Height: 4}
+				if !ok || result.Format != domain.ImageFormatPNG || result.Width != 8 || result.Height != 8 || result.SubjectBox == nil || *result.SubjectBox != want {
+					return fmt.Errorf("unexpected result %#v", output)
+				}
+				img, err := readPNG(filepath.Join(dir, result.ProcessedImagePath))
+				if err != nil {
+					return err
+				}
+				if r, g, _, a := img.At(0, 0).RGBA(); r != 0xffff || g != 0 || a != 0xffff {
+					return fmt.Errorf("background at (0, 0) is not red")
+				}
+				if r, _, _, a := img.At(3, 3).RGBA(); r != 0 || a != 0xffff {
+					return fmt.Errorf("subject at (3, 3) was changed")
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "remove-bg mask",
+			Tool:  domain.ToolBackgroundRemoval,
+			Input: domain.ToolInput{FilePath: cutoutPath, MaskOnly: true},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.BackgroundRemovalResult)
+				if !ok || result.Width != 16 || result.Height != 16 || result.SubjectBox != nil {
+					return fmt.Errorf("unexpected result %#v", output)
+				}
+				img, err := readPNG(filepath.Join(dir, result.ProcessedImagePath))
+				if err != nil {
+					return err
+				}
+				if r, _, _, _ := img.At(7, 5).RGBA(); r != 0xffff {
+					return fmt.Errorf("subject at (7, 5) is not white in the mask")
+				}
+				if r, _, _, _ := img.At(0, 0).RGBA(); r != 0 {
+					return fmt.Errorf("background at (0, 0) is not black in the mask")
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "face-recognition",
+			Tool:  domain.ToolFaceRecognition,
+			Input: domain.ToolInput{FilePath: imagePath},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.FaceRecognitionResult)
+				if !ok || result.FaceCount != 1 {
+					return fmt.Errorf("expected 'face_count' 1 in %#v", output)
+				}
+				want := domain.FaceBox{Top: 2, Right: 6, Bottom: 6, Left: 2}
+				if len(result.Faces) != 1 || result.Faces[0] != want {
+					return fmt.Errorf("expected faces [%+v], got %+v", want, result.Faces)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "face-recognition error with status 200",
+			Tool:      domain.ToolFaceRecognition,
+			Input:     domain.ToolInput{FilePath: brokenImagePath},
+			WantError: "cannot identify image file",
+		},
+		{
+			// Backend giả tính embedding từ kích thước ảnh: hai ảnh cùng kích thước là cùng một người
+			Name:  "face-verify",
+			Tool:  domain.ToolFaceVerify,
+			Input: domain.ToolInput{FilePath: imagePath, ReferenceFilePath: referencePath, Threshold: 0.9},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.FaceVerifyResult)
+				if !ok || !result.Match || result.Similarity < 0.999 || result.Threshold != 0.9 {
+					return fmt.Errorf("expected a match with similarity 1 and threshold 0.9, got %#v", output)
+				}
+				quality := result.Probe.Quality
+				if quality == nil || result.Probe.Liveness == nil || result.Reference.Quality == nil {
+					return fmt.Errorf("missing 'quality' or 'liveness' in %+v", result)
+				}
+				if quality.Width != 4 || len(quality.Issues) == 0 || quality.Issues[0] != domain.FaceIssueTooSmall {
+					return fmt.Errorf("expected a 4px face with issue '%s', got %+v", domain.FaceIssueTooSmall, quality)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "face-verify mismatch",
+			Tool:  domain.ToolFaceVerify,
+			Input: domain.ToolInput{FilePath: imagePath, ReferenceFilePath: photoPath, Threshold: 0.9},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.FaceVerifyResult)
+				if !ok || result.Match || result.Similarity >= 0.9 {
+					return fmt.Errorf("expected no match, got %#v", output)
+				}
+				return nil
+			},
+		},
+		{
+			// Khuôn mặt giả ở giữa ảnh 8x8 (2..6) được tô đen, góc ảnh vẫn là nền trắng
+			Name:  "face-anonymize",
+			Tool:  domain.ToolFaceAnonymize,
+			Input: domain.ToolInput{FilePath: imagePath, Mode: domain.AnonymizeBox},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.FaceAnonymizeResult)
+				if !ok || result.FaceCount != 1 || result.Strength != domain.DefaultAnonymizeStrength || result.ImagePath == "" {
+					return fmt.Errorf("unexpected result %#v", output)
+				}
+				img, err := readPNG(filepath.Join(dir, result.ImagePath))
+				if err != nil {
+					return err
+				}
+				if r, _, _, _ := img.At(3, 4).RGBA(); r != 0 {
+					return fmt.Errorf("face at (3, 4) is not covered")
+				}
+				if r, _, _, _ := img.At(7, 0).RGBA(); r != 0xffff {
+					return fmt.Errorf("background at (7, 0) was changed")
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "ocr",
+			Tool:  domain.ToolOCR,
+			Input: domain.ToolInput{FilePath: imagePath},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.OCRResult)
+				if !ok || result.Text == "" || result.Languages != "eng" {
+					return fmt.Errorf("missing 'text' or default 'languages' in %#v", output)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "ocr json",
+			Tool:  domain.ToolOCR,
+			Input: domain.ToolInput{FilePath: imagePath, Languages: "vie+eng", Output: domain.OCROutputJSON},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.OCRResult)
+				if !ok || result.Languages != "vie+eng" || result.Orientation == nil {
+					return fmt.Errorf("missing 'languages' or 'orientation' in %#v", output)
+				}
+				if len(result.Blocks) != 1 || len(result.Blocks[0].Lines) != 1 {
+					return fmt.Errorf("expected one block with one line, got %+v", result.Blocks)
+				}
+				words := result.Blocks[0].Lines[0].Words
+				if len(words) == 0 || words[0].Text == "" || words[0].BBox.Height != 8 || words[0].Confidence <= 0 {
+					return fmt.Errorf("unexpected words %+v", words)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "ocr hocr",
+			Tool:  domain.ToolOCR,
+			Input: domain.ToolInput{FilePath: imagePath, Output: domain.OCROutputHOCR},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.OCRResult)
+				if !ok || !strings.Contains(result.HOCR, "ocr_page") || result.Blocks != nil {
+					return fmt.Errorf("expected only 'hocr' in %#v", output)
+				}
+				return nil
+			},
+		},
+		{
+			// Ảnh 16x8 xoay 90° theo EXIF: thu nhỏ còn 8x4 rồi xoay thành 4x8 trước khi gửi tới service
+			Name: "ocr preprocess",
+			Tool: domain.ToolOCR,
+			Input: domain.ToolInput{
+				FilePath:     photoPath,
+				Preprocess:   []string{domain.PreprocessAutoOrient, domain.PreprocessDownscale, domain.PreprocessGrayscale, domain.PreprocessDeskew, domain.PreprocessBinarize},
+				MaxDimension: 8,
+			},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.OCRResult)
+				if !ok || !strings.Contains(result.Text, "4x8") {
+					return fmt.Errorf("expected OCR of the preprocessed 4x8 image, got %#v", output)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "ocr searchable pdf",
+			Tool:  domain.ToolOCR,
+			Input: domain.ToolInput{FilePath: imagePath, SearchablePDF: true},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.OCRResult)
+				if !ok || result.SearchablePDF == "" || filepath.IsAbs(result.SearchablePDF) {
+					return fmt.Errorf("expected relative 'searchable_pdf' in %#v", output)
+				}
+				content, err := os.ReadFile(filepath.Join(dir, result.SearchablePDF))
+				if err != nil || !bytes.HasPrefix(content, []byte("%PDF-")) {
+					return fmt.Errorf("searchable PDF %s not saved: %v", result.SearchablePDF, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "ocr multi-page tiff",
+			Tool:  domain.ToolOCR,
+			Input: domain.ToolInput{FilePath: tiffPath, Languages: "vie"},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.OCRResult)
+				if !ok || result.PageCount != 3 || len(result.Pages) != 3 {
+					return fmt.Errorf("expected 3 pages in %#v", output)
+				}
+				sizes := []string{"8x8", "16x8", "8x16"}
+				for i, page := range result.Pages {
+					if page.Page != i+1 || page.Status != domain.TaskStatusCompleted || page.Result == nil {
+						return fmt.Errorf("unexpected page %+v", page)
+					}
+					if !strings.Contains(page.Result.Text, sizes[i]) || page.Result.Languages != "vie" {
+						return fmt.Errorf("page %d has text %q, expected a %s image", page.Page, page.Result.Text, sizes[i])
+					}
+				}
+				if strings.Count(result.Text, "\f") != 2 || !strings.Contains(result.Text, "16x8") {
+					return fmt.Errorf("expected page texts joined by form feed, got %q", result.Text)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "ocr language not installed",
+			Tool:      domain.ToolOCR,
+			Input:     domain.ToolInput{FilePath: imagePath, Languages: "jpn"},
+			WantError: "Failed loading language 'jpn'",
+		},
+		{
+			Name:      "ocr error with status 200",
+			Tool:      domain.ToolOCR,
+			Input:     domain.ToolInput{FilePath: brokenImagePath},
+			WantError: "cannot identify image file",
+		},
+		{
+			Name:  "translate",
+			Tool:  domain.ToolTranslation,
+			Input: domain.ToolInput{Text: "Hello", DestLang: "vi"},
+			Check: func(output interface{}) error {
+				result, ok := output.(*domain.TranslationResult)
+				if !ok || result.TranslatedText != "[vi] Hello" {
+					return fmt.Errorf("expected 'translated_text' \"[vi] Hello\" in %#v", output)
+				}
+				return nil
+			},
+		},
+	}, nil
+}
+
+// TestContract gọi mọi tool qua các backend giả (theo contract của service thật). Sai lệch dạng request
+// làm backend giả trả lỗi, sai lệch dạng response làm Check thất bại.
+func TestContract(t *testing.T) {
+	dir := t.TempDir()
+	backends := Start(dir)
+	defer backends.Close()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Backends = backends.Config()
+	cfg.Uploads.ImagePath = dir
+	cfg.Retention.SharedImagePath = dir
+
+	// RunTool chỉ gọi backend nên không cần cơ sở dữ liệu
+	run := service.NewTaskService(nil, nil, cfg).RunTool
+	cases, err := contractCases(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			output, err := run(context.Background(), c.Tool, c.Input)
+			switch {
+			case c.WantError != "" && err == nil:
+				t.Fatalf("expected error %q, got output %#v", c.WantError, output)
+			case c.WantError != "":
+				if !strings.Contains(err.Error(), c.WantError) {
+					t.Fatalf("expected error %q, got %q", c.WantError, err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			default:
+				if err := c.Check(output); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func checkText(output interface{}) error {
+	result, ok := output.(*domain.TextResult)
+	if !ok || result.Text == "" {
+		return fmt.Errorf("missing 'text' in %#v", output)
+	}
+	return nil
+}
+
+// writeJPEG ghi một ảnh JPEG kích thước w x h có tag EXIF Orientation
+func writeJPEG(path string, w, h, orientation int) error {
+	var buf bytes.Buffer
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		return err
+	}
+
+	// APP1 "Exif": khối TIFF big-endian với IFD0 chỉ có tag Orientation (SHORT)
+	be := binary.BigEndian
+	var exif bytes.Buffer
+	exif.WriteString("Exif\x00\x00MM\x00*")
+	binary.Write(&exif, be, uint32(8))
+	binary.Write(&exif, be, uint16(1))
+	binary.Write(&exif, be, []uint16{0x0112, 3})
+	binary.Write(&exif, be, uint32(1))
+	binary.Write(&exif, be, []uint16{uint16(orientation), 0})
+	binary.Write(&exif, be, uint32(0))
+
+	data := buf.Bytes()
+	var out bytes.Buffer
+	out.Write(data[:2])
+	out.Write([]byte{0xff, 0xe1})
+	binary.Write(&out, be, uint16(exif.Len()+2))
+	out.Write(exif.Bytes())
+	out.Write(data[2:])
+	return os.WriteFile(path, out.Bytes(), 0644)
+}
+
+// writeTIFF ghi một file TIFF xám không nén, mỗi phần tử của sizes là kích thước một frame (trang)
+func writeTIFF(path string, sizes []image.Point) error {
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.WriteString("II*\x00")
+	binary.Write(&buf, le, uint32(0))
+	next := 4 // vị trí con trỏ tới IFD tiếp theo cần ghi
+	for _, size := range sizes {
+		pixels := buf.Len()
+		buf.Write(bytes.Repeat([]byte{0xff}, size.X*size.Y))
+		if buf.Len()%2 == 1 {
+			buf.WriteByte(0)
+		}
+
+		ifd := buf.Len()
+		le.PutUint32(buf.Bytes()[next:], uint32(ifd))
+		entries := [][2]uint32{
+			{256, uint32(size.X)},          // ImageWidth
+			{257, uint32(size.Y)},          // ImageLength
+			{258, 8},                       // BitsPerSample
+			{259, 1},                       // Compression: không nén
+			{262, 1},                       // PhotometricInterpretation: BlackIsZero
+			{273, uint32(pixels)},          // StripOffsets
+			{277, 1},                       // SamplesPerPixel
+			{278, uint32(size.Y)},          // RowsPerStrip
+			{279, uint32(size.X * size.Y)}, // StripByteCounts
+		}
+		binary.Write(&buf, le, uint16(len(entries)))
+		for _, entry := range entries {
+			binary.Write(&buf, le, uint16(entry[0]))
+			binary.Write(&buf, le, uint16(4)) // LONG
+			binary.Write(&buf, le, uint32(1))
+			binary.Write(&buf, le, entry[1])
+		}
+		next = buf.Len()
+		binary.Write(&buf, le, uint32(0))
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// writePNG ghi một ảnh PNG nhỏ dùng làm đầu vào cho tool xử lý ảnh
+func writePNG(path string) error {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for x := 0; x < 8; x++ {
+		img.Set(x, x, color.Black)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
+
+// writeCutout ghi ảnh PNG 16x16 trong suốt với chủ thể là ô vuông đen 4x4 ở (6, 4)
+func writeCutout(path string) error {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for y := 4; y < 8; y++ {
+		for x := 6; x < 10; x++ {
+			img.Set(x, y, color.Black)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
@@ -0,0 +1,372 @@
+// Package fakes chứa các backend AI giả chạy trong process. Mỗi backend nhận request
+// và trả response theo đúng contract của service thật (đường dẫn, dạng body, tên trường,
+// cả cách service báo lỗi), nhưng không cần model. Dùng để kiểm tra contract giữa
+// management-api và các service (xem TestContract) và để chạy toàn bộ API ở máy local (cmd/fake-backends).
+package fakes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"management-api/internal/config"
+	"management-api/internal/domain"
+)
+
+// Backends là các server giả của mọi service AI
+type Backends struct {
+	TextToVoice       *httptest.Server
+	VoiceToText       *httptest.Server
+	BackgroundRemoval *httptest.Server
+	SpeechRecognition *httptest.Server
+	FaceRecognition   *httptest.Server
+	OCR               *httptest.Server
+	Translation       *httptest.Server
+}
+
+// Start chạy mọi backend giả trên cổng ngẫu nhiên của localhost.
+// Ảnh đã xoá nền được ghi vào imageDir, giống thư mục /shared/images của service thật.
+func Start(imageDir string) *Backends {
+	return &Backends{
+		TextToVoice:       httptest.NewServer(TextToVoice()),
+		VoiceToText:       httptest.NewServer(VoiceToText()),
+		BackgroundRemoval: httptest.NewServer(BackgroundRemoval(imageDir)),
+		SpeechRecognition: httptest.NewServer(SpeechRecognition()),
+		FaceRecognition:   httptest.NewServer(FaceRecognition()),
+		OCR:               httptest.NewServer(OCR()),
+		Translation:       httptest.NewServer(Translation()),
+	}
+}
+
+// Config trả về cấu hình backend trỏ tới các server giả
+func (b *Backends) Config() config.BackendConfig {
+	return config.BackendConfig{
+		TextToVoiceURL:       b.TextToVoice.URL,
+		VoiceToTextURL:       b.VoiceToText.URL,
+		BackgroundRemovalURL: b.BackgroundRemoval.URL,
+		SpeechRecognitionURL: b.SpeechRecognition.URL,
+		FaceRecognitionURL:   b.FaceRecognition.URL,
+		OCRURL:               b.OCR.URL,
+		TranslationURL:       b.Translation.URL,
+	}
+}
+
+// Close dừng mọi server giả
+func (b *Backends) Close() {
+	for _, server := range []*httptest.Server{
+		b.TextToVoice, b.VoiceToText, b.BackgroundRemoval, b.SpeechRecognition,
+		b.FaceRecognition, b.OCR, b.Translation,
+	} {
+		server.Close()
+	}
+}
+
+// TextToVoice giả lập service text-to-voice: POST /convert với JSON {text, language},
+// trả về {audio_url, marks, marks_source}. Lỗi trả về {"error": "..."} với status khác 200.
+func TextToVoice() http.Handler {
+	var taskID int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", post(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Text     string `json:"text"`
+			Language string `json:"language"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "Invalid input"})
+			return
+		}
+		if strings.TrimSpace(req.Text) == "" {
+			writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "TTS conversion failed"})
+			return
+		}
+
+		id := atomic.AddInt64(&taskID, 1)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"audio_url":    fmt.Sprintf("http://localhost:5001/audio/output_%d.mp3", id),
+			"marks":        wordMarks(req.Text),
+			"marks_source": "estimated",
+		})
+	}))
+	return mux
+}
+
+// wordMarks tạo mốc thời gian cho từng từ, mỗi từ dài 300ms
+func wordMarks(text string) []map[string]interface{} {
+	var marks []map[string]interface{}
+	offset := 0
+	for _, word := range strings.Fields(text) {
+		start := strings.Index(text[offset:], word) + offset
+		charStart := utf8.RuneCountInString(text[:start])
+		ms := len(marks) * 300
+		marks = append(marks, map[string]interface{}{
+			"type":       "word",
+			"value":      word,
+			"start_ms":   ms,
+			"end_ms":     ms + 300,
+			"char_start": charStart,
+			"char_end":   charStart + utf8.RuneCountInString(word),
+		})
+		offset = start + len(word)
+	}
+	return marks
+}
+
+// VoiceToText giả lập service voice-to-text: POST /convert với file multipart "audio", trả về {text}
+func VoiceToText() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", post(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := r.FormFile("audio"); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "No audio file provided"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"text": "Converted text from audio"})
+	}))
+	return mux
+}
+
+// BackgroundRemoval giả lập service background-removal: POST /remove-bg với file multipart "image",
+// ghi ảnh kết quả vào imageDir/<ngày>/output_<tên file> và trả về {processed_image_path} tương đối với imageDir.
+// Ảnh kết quả là bản sao của ảnh gốc; trường form alpha_matting được chấp nhận nhưng bỏ qua.
+func BackgroundRemoval(imageDir string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/remove-bg", post(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("image")
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "No image file provided"})
+			return
+		}
+		defer file.Close()
+
+		relativePath := filepath.Join(time.Now().Format("2006-01-02"), "output_"+filepath.Base(header.Filename))
+		outputPath := filepath.Join(imageDir, relativePath)
+		if err := copyFile(file, outputPath); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"processed_image_path": relativePath})
+	}))
+	return mux
+}
+
+func copyFile(src io.Reader, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, src)
+	return err
+}
+
+// SpeechRecognition giả lập service speech-recognition: POST /recognize với JSON {audio_url}, trả về {text}
+func SpeechRecognition() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/recognize", post(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AudioURL string `json:"audio_url"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "Invalid input"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"text": "Recognized speech text"})
+	}))
+	return mux
+}
+
+// FaceRecognition giả lập service face-recognition: POST /recognize-face với file multipart "image",
+// trả về {face_count, face_locations}, và POST /encode-face. Giống service thật, ảnh không đọc được trả về {"error": "..."} với status 200.
+func FaceRecognition() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/recognize-face", post(func(w http.ResponseWriter, r *http.Request) {
+		img, ok := formImage(w, r)
+		if !ok {
+			return
+		}
+		// Không chạy model nên mọi ảnh đọc được đều có đúng một khuôn mặt ở giữa ảnh,
+		// dạng [top, right, bottom, left] như face_recognition.face_locations
+		location := []int{img.Height / 4, img.Width * 3 / 4, img.Height * 3 / 4, img.Width / 4}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"face_count":     1,
+			"face_locations": [][]int{location},
+		})
+	}))
+	// POST /encode-face trả về {faces: [{location, encoding}]}; embedding được tính từ kích thước ảnh
+	// nên hai ảnh cùng kích thước là cùng một người
+	mux.HandleFunc("/encode-face", post(func(w http.ResponseWriter, r *http.Request) {
+		img, ok := formImage(w, r)
+		if !ok {
+			return
+		}
+		location := []int{img.Height / 4, img.Width * 3 / 4, img.Height * 3 / 4, img.Width / 4}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"faces": []map[string]interface{}{{"location": location, "encoding": faceEncoding(img)}},
+		})
+	}))
+	return mux
+}
+
+// faceEncoding tạo embedding 128 chiều cố định theo kích thước ảnh
+func faceEncoding(img image.Config) []float64 {
+	encoding := make([]float64, 128)
+	seed := float64(img.Width*31 + img.Height)
+	for i := range encoding {
+		encoding[i] = math.Sin(seed * float64(i+1))
+	}
+	return encoding
+}
+
+// OCR giả lập service ocr: POST /ocr với file multipart "image" và trường form languages (mặc định "eng"),
+// output (text, hocr, json, tsv; mặc định text). Trả về {text, languages} cùng orientation và blocks (json),
+// hocr hoặc tsv. Giống service thật, ảnh không đọc được hoặc ngôn ngữ chưa cài trả về {"error": "..."} với status 200.
+func OCR() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ocr", post(func(w http.ResponseWriter, r *http.Request) {
+		languages := r.FormValue("languages")
+		if languages == "" {
+			languages = "eng"
+		}
+		output := r.FormValue("output")
+		if output == "" {
+			output = "text"
+		}
+		if err := domain.ValidateOCROptions(languages, output); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+
+		img, ok := formImage(w, r)
+		if !ok {
+			return
+		}
+		for _, language := range strings.Split(languages, "+") {
+			if !ocrLanguages[language] {
+				writeJSON(w, http.StatusOK, map[string]interface{}{
+					"error": fmt.Sprintf("Failed loading language '%s'", language),
+				})
+				return
+			}
+		}
+
+		text := fmt.Sprintf("Recognized text from %dx%d image", img.Width, img.Height)
+		response := map[string]interface{}{"text": text + "\n", "languages": languages}
+		switch output {
+		case "json":
+			response["orientation"] = map[string]interface{}{"rotate": 0, "confidence": 12.5}
+			response["blocks"] = ocrBlocks(text, img)
+		case "hocr":
+			response["hocr"] = fmt.Sprintf("<div class='ocr_page' title='bbox 0 0 %d %d'><span class='ocr_line'>%s</span></div>",
+				img.Width, img.Height, text)
+		case "tsv":
+			response["tsv"] = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n"
+		}
+		if r.FormValue("pdf") == "true" {
+			pdf := fmt.Sprintf("%%PDF-1.4\n%% %s\n%%%%EOF\n", text)
+			response["pdf"] = base64.StdEncoding.EncodeToString([]byte(pdf))
+		}
+		writeJSON(w, http.StatusOK, response)
+	}))
+	return mux
+}
+
+// ocrLanguages là các ngôn ngữ đã cài trong image của service OCR
+var ocrLanguages = map[string]bool{"eng": true, "vie": true, "osd": true}
+
+// ocrBlocks tạo một block một dòng chứa các từ của text, xếp ngang trên toàn bộ chiều rộng ảnh
+func ocrBlocks(text string, img image.Config) []map[string]interface{} {
+	words := strings.Fields(text)
+	wordWidth := img.Width / len(words)
+	var items []map[string]interface{}
+	for i, word := range words {
+		items = append(items, map[string]interface{}{
+			"text":       word,
+			"bbox":       box(i*wordWidth, 0, wordWidth, img.Height),
+			"confidence": 90.0,
+		})
+	}
+	line := map[string]interface{}{
+		"text":       text,
+		"bbox":       box(0, 0, img.Width, img.Height),
+		"confidence": 90.0,
+		"words":      items,
+	}
+	return []map[string]interface{}{{
+		"text":       text,
+		"bbox":       box(0, 0, img.Width, img.Height),
+		"confidence": 90.0,
+		"lines":      []map[string]interface{}{line},
+	}}
+}
+
+func box(left, top, width, height int) map[string]interface{} {
+	return map[string]interface{}{"left": left, "top": top, "width": width, "height": height}
+}
+
+// formImage đọc ảnh trong trường multipart "image" và ghi response lỗi như service Python thật:
+// thiếu file là 400, ảnh không đọc được là 200 với {"error": "..."}
+func formImage(w http.ResponseWriter, r *http.Request) (image.Config, bool) {
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "No image file provided"})
+		return image.Config{}, false
+	}
+	defer file.Close()
+
+	img, _, err := image.DecodeConfig(file)
+	if err != nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"error": fmt.Sprintf("cannot identify image file: %v", err)})
+		return image.Config{}, false
+	}
+	return img, true
+}
+
+// Translation giả lập service translation: POST /translate với JSON {text, dest_lang},
+// trả về {translated_text} dạng "[<dest_lang>] <text>"
+func Translation() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/translate", post(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["text"] == nil || req["dest_lang"] == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "Invalid input"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"translated_text": fmt.Sprintf("[%v] %v", req["dest_lang"], req["text"]),
+		})
+	}))
+	return mux
+}
+
+// post chỉ nhận method POST, giống route của service thật
+func post(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"error": "Method not allowed"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
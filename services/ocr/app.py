@@ -1,68 +1,114 @@
 from flask import Flask, request, jsonify
 from PIL import Image
 import pytesseract
+import base64
 import os
-import psycopg2
-import json
+import re
 import time
 
 app = Flask(__name__)
 
-# Kết nối tới cơ sở dữ liệu
-def get_db_connection():
-    conn = psycopg2.connect(
-        host=os.getenv('DB_HOST', 'localhost'),
-        port=os.getenv('DB_PORT', '5432'),
-        database=os.getenv('DB_NAME', 'ai_tools'),
-        user=os.getenv('DB_USER', 'admin'),
-        password=os.getenv('DB_PASSWORD', 'password')
-    )
-    return conn
+# Định dạng output: text chỉ trả về text; json thêm block/line/word kèm bounding box, độ tin cậy
+# và hướng trang; hocr và tsv thêm kết quả thô của tesseract ở định dạng tương ứng
+OUTPUT_FORMATS = ("text", "hocr", "json", "tsv")
+LANGUAGES_PATTERN = re.compile(r'^[a-z_]+(\+[a-z_]+)*$')
+
+
+def bbox(data, i):
+    return {"left": data["left"][i], "top": data["top"][i], "width": data["width"][i], "height": data["height"][i]}
+
+
+def mean_confidence(items):
+    confidences = [item["confidence"] for item in items if item["confidence"] >= 0]
+    if not confidences:
+        return -1
+    return round(sum(confidences) / len(confidences), 2)
+
+
+def ocr_blocks(img, languages):
+    """Gom kết quả image_to_data thành block -> line -> word. Độ tin cậy từ 0 đến 100."""
+    data = pytesseract.image_to_data(img, lang=languages, output_type=pytesseract.Output.DICT)
+    blocks = {}
+    lines = {}
+    for i, level in enumerate(data["level"]):
+        block_key = (data["page_num"][i], data["block_num"][i])
+        line_key = block_key + (data["par_num"][i], data["line_num"][i])
+        if level == 2:
+            blocks[block_key] = {"bbox": bbox(data, i), "lines": []}
+        elif level == 4:
+            line = {"bbox": bbox(data, i), "words": []}
+            lines[line_key] = line
+            blocks[block_key]["lines"].append(line)
+        elif level == 5 and data["text"][i].strip():
+            lines[line_key]["words"].append({
+                "text": data["text"][i],
+                "bbox": bbox(data, i),
+                "confidence": round(float(data["conf"][i]), 2),
+            })
+
+    result = []
+    for block in blocks.values():
+        block["lines"] = [line for line in block["lines"] if line["words"]]
+        if not block["lines"]:
+            continue
+        for line in block["lines"]:
+            line["text"] = " ".join(word["text"] for word in line["words"])
+            line["confidence"] = mean_confidence(line["words"])
+        block["text"] = "\n".join(line["text"] for line in block["lines"])
+        block["confidence"] = mean_confidence(block["lines"])
+        result.append(block)
+    return result
+
+
+def page_orientation(img):
+    """Hướng trang theo tesseract OSD; None nếu ảnh có quá ít chữ để xác định"""
+    try:
+        osd = pytesseract.image_to_osd(img, output_type=pytesseract.Output.DICT)
+    except pytesseract.TesseractError:
+        return None
+    return {"rotate": osd["rotate"], "confidence": osd["orientation_conf"]}
+
+
+def run_ocr(img, languages, output, pdf=False):
+    response = {"text": pytesseract.image_to_string(img, lang=languages), "languages": languages}
+    if pdf:
+        # PDF một trang gồm ảnh gốc và lớp text ẩn (searchable PDF), mã hoá base64
+        data = pytesseract.image_to_pdf_or_hocr(img, lang=languages, extension='pdf')
+        response["pdf"] = base64.b64encode(data).decode('ascii')
+    if output == "json":
+        response["orientation"] = page_orientation(img)
+        response["blocks"] = ocr_blocks(img, languages)
+    elif output == "hocr":
+        response["hocr"] = pytesseract.image_to_pdf_or_hocr(img, lang=languages, extension='hocr').decode('utf-8')
+    elif output == "tsv":
+        response["tsv"] = pytesseract.image_to_data(img, lang=languages)
+    return response
+
 
 @app.route('/ocr', methods=['POST'])
 def ocr():
     if 'image' not in request.files:
         return jsonify({"error": "No image file provided"}), 400
 
+    languages = request.form.get('languages') or 'eng'
+    output = request.form.get('output') or 'text'
+    pdf = request.form.get('pdf', '').lower() in ('1', 'true')
+    if not LANGUAGES_PATTERN.match(languages):
+        return jsonify({"error": f"Invalid languages '{languages}'"}), 400
+    if output not in OUTPUT_FORMATS:
+        return jsonify({"error": f"Invalid output '{output}'"}), 400
+
     image = request.files['image']
     image_path = f"/tmp/{image.filename}"
     image.save(image_path)
 
-    # Insert task vào cơ sở dữ liệu
-    conn = get_db_connection()
-    cur = conn.cursor()
-    cur.execute(
-        "INSERT INTO tasks (service_name, status, input_data) VALUES (%s, %s, %s) RETURNING id",
-        ("ocr", "processing", json.dumps({"image_file": image.filename}))
-    )
-    task_id = cur.fetchone()[0]
-    conn.commit()
-
     try:
         # Thực hiện OCR
         img = Image.open(image_path)
-        text = pytesseract.image_to_string(img)
-
-        # Cập nhật task
-        cur.execute(
-            "UPDATE tasks SET status=%s, output_data=%s, updated_at=NOW() WHERE id=%s",
-            ("completed", json.dumps({"text": text}), task_id)
-        )
-        conn.commit()
-
-        response = {"text": text}
+        response = run_ocr(img, languages, output, pdf)
     except Exception as e:
-        # Cập nhật task với trạng thái lỗi
-        cur.execute(
-            "UPDATE tasks SET status=%s, output_data=%s, updated_at=NOW() WHERE id=%s",
-            ("failed", json.dumps({"error": str(e)}), task_id)
-        )
-        conn.commit()
         response = {"error": str(e)}
 
-    cur.close()
-    conn.close()
-
     # Xóa file tạm
     os.remove(image_path)
 
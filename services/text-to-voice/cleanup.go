@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// retentionTool là tên tool của text-to-voice trong RETENTION_FILE_TTLS, khớp với service_name của task
+const retentionTool = "tts"
+
+// cleanupAudio xoá định kỳ các file audio cũ hơn thời gian giữ file trong dir.
+// Dùng chung cấu hình dọn dẹp với janitor của management-api: thời gian giữ file là RETENTION_FILE_TTLS
+// của "tts" hoặc RETENTION_FILE_TTL (mặc định 7 ngày), quét mỗi RETENTION_INTERVAL (mặc định 1 giờ);
+// thời gian giữ file "0" là không xoá.
+func cleanupAudio(dir string) {
+	ttl := retentionFileTTL()
+	if ttl <= 0 {
+		log.Println("Audio cleanup disabled")
+		return
+	}
+	interval := envDuration("RETENTION_INTERVAL", time.Hour)
+	if interval <= 0 {
+		log.Printf("Invalid RETENTION_INTERVAL %s, using 1h\n", interval)
+		interval = time.Hour
+	}
+	log.Printf("Cleaning up audio files older than %s every %s\n", ttl, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			log.Printf("Audio cleanup failed: %v\n", err)
+			continue
+		}
+
+		cutoff := time.Now().Add(-ttl)
+		removed := 0
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || entry.IsDir() || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				log.Printf("Failed to remove %s: %v\n", entry.Name(), err)
+				continue
+			}
+			removed++
+		}
+		if removed > 0 {
+			log.Printf("Audio cleanup removed %d files older than %s\n", removed, ttl)
+		}
+	}
+}
+
+// retentionFileTTL đọc thời gian giữ file của text-to-voice theo cùng quy tắc với RetentionConfig.FileTTLFor
+func retentionFileTTL() time.Duration {
+	for _, item := range strings.Split(os.Getenv("RETENTION_FILE_TTLS"), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || strings.TrimSpace(name) != retentionTool {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return envDuration("RETENTION_FILE_TTL", 7*24*time.Hour)
+}
+
+func envDuration(key string, defaultVal time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultVal
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %s\n", key, value, defaultVal)
+		return defaultVal
+	}
+	return d
+}
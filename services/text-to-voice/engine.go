@@ -0,0 +1,40 @@
+package main
+
+import (
+	"log"
+
+	htgotts "github.com/hegedustibor/htgo-tts"
+	"github.com/hegedustibor/htgo-tts/handlers"
+)
+
+// speechEngine tổng hợp text thành file mp3, trả về đường dẫn file
+type speechEngine interface {
+	CreateSpeechFile(text, language, fileName string) (string, error)
+}
+
+// googleEngine dùng Google Translate TTS qua htgo-tts, không hỗ trợ mốc thời gian
+type googleEngine struct {
+	folder string
+}
+
+func (e *googleEngine) CreateSpeechFile(text, language, fileName string) (string, error) {
+	tts := htgotts.Speech{Folder: e.folder, Language: language, Handler: &handlers.MPlayer{}}
+	return tts.CreateSpeechFile(text, fileName)
+}
+
+// synthesize tạo file audio và mốc thời gian. Engine không trả về mốc thời gian nên mốc luôn là
+// ước lượng (căn chỉnh text với audio), có thể lệch so với lúc từ thực sự được đọc.
+// Lỗi khi ước lượng không làm hỏng kết quả audio, chỉ trả về danh sách mốc rỗng.
+func synthesize(engine speechEngine, text, language, fileName string) (string, []Mark, string, error) {
+	filePath, err := engine.CreateSpeechFile(text, language, fileName)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	marks, err := estimateMarks(text, filePath)
+	if err != nil {
+		log.Printf("Failed to estimate speech marks for %s: %v\n", filePath, err)
+		return filePath, nil, "", nil
+	}
+	return filePath, marks, marksSourceEstimated, nil
+}
@@ -1,8 +1,6 @@
 package main
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,9 +9,6 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	htgotts "github.com/hegedustibor/htgo-tts"
-	"github.com/hegedustibor/htgo-tts/handlers"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type ConvertRequest struct {
@@ -22,46 +17,18 @@ type ConvertRequest struct {
 }
 
 type ConvertResponse struct {
-	AudioURL string `json:"audio_url"`
+	AudioURL    string `json:"audio_url"`
+	Marks       []Mark `json:"marks"`
+	MarksSource string `json:"marks_source,omitempty"`
 }
 
-type Task struct {
-	ID          int             `json:"id"`
-	ServiceName string          `json:"service_name"`
-	Status      string          `json:"status"`
-	InputData   json.RawMessage `json:"input_data"`
-	OutputData  json.RawMessage `json:"output_data"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-}
-
-var dbPool *pgxpool.Pool
+// engine là bộ tổng hợp giọng nói đang dùng
+var engine speechEngine = &googleEngine{folder: "audio"}
 
 func main() {
 	log.Println("Starting Text-to-Voice service...")
-	//		// os.Setenv("DB_HOST", "localhost")
-	//		// os.Setenv("DB_PORT", "5433")
-	//		// os.Setenv("DB_USER", "admin")
-	//		// os.Setenv("DB_PASSWORD", "password")
-	//		// os.Setenv("DB_NAME", "ai_tools")
-	// Lấy thông tin kết nối cơ sở dữ liệu
-	dbURL := fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-	)
-	log.Printf("Connecting to database at %s\n", dbURL)
-
-	// Kết nối tới cơ sở dữ liệu
-	var err error
-	dbPool, err = pgxpool.Connect(context.Background(), dbURL)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v\n", err)
-	}
-	defer dbPool.Close()
-	log.Println("Database connected successfully")
+	// Xoá file audio cũ ở background
+	go cleanupAudio("audio")
 
 	r := gin.Default()
 
@@ -101,23 +68,9 @@ func handleConvert(c *gin.Context) {
 		req.Language = "en" // Giá trị mặc định nếu không có ngôn ngữ được cung cấp
 	}
 
-	// Insert task vào cơ sở dữ liệu
-	var taskID int
-	log.Println("Inserting task into database...")
-	err := dbPool.QueryRow(context.Background(),
-		"INSERT INTO tasks (service_name, status, input_data) VALUES ($1, $2, $3) RETURNING id",
-		"text-to-voice", "processing", map[string]string{"text": req.Text, "language": req.Language},
-	).Scan(&taskID)
-	if err != nil {
-		log.Printf("Database error during task insertion: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
-	}
-	log.Printf("Task inserted with ID: %d\n", taskID)
-
 	// Tạo thư mục nếu chưa tồn tại
 	audioDir := "audio"
-	if _, err = os.Stat(audioDir); os.IsNotExist(err) {
+	if _, err := os.Stat(audioDir); os.IsNotExist(err) {
 		log.Printf("Audio directory %s does not exist. Creating...\n", audioDir)
 		err = os.Mkdir(audioDir, 0755)
 		if err != nil {
@@ -128,33 +81,28 @@ func handleConvert(c *gin.Context) {
 		log.Printf("Audio directory %s created successfully\n", audioDir)
 	}
 
-	// Chuyển đổi Text-to-Voice
-	audioPath := fmt.Sprintf("output_%d", taskID) // Không thêm ".mp3"
-	tts := htgotts.Speech{Folder: audioDir, Language: req.Language, Handler: &handlers.MPlayer{}}
+	// Chuyển đổi Text-to-Voice. Task do management-api ghi, tên file chỉ cần không trùng giữa các request.
+	audioPath := fmt.Sprintf("output_%d", time.Now().UnixNano()) // Không thêm ".mp3"
 	log.Printf("Converting text to speech. Output file: %s\n", audioPath)
-	filePath, err := tts.CreateSpeechFile(req.Text, audioPath)
+	filePath, marks, marksSource, err := synthesize(engine, req.Text, req.Language, audioPath)
 	if err != nil {
 		log.Printf("TTS conversion failed: %v\n", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "TTS conversion failed"})
 		return
 	}
-	log.Printf("TTS conversion succeeded. File path: %s\n", filePath)
-
-	// Cập nhật task status và output_data
-	audioURL := fmt.Sprintf("http://localhost:5001/audio/output_%d.mp3", taskID)
-	log.Printf("Updating task status to 'completed' with audio URL: %s\n", audioURL)
-	_, err = dbPool.Exec(context.Background(),
-		"UPDATE tasks SET status=$1, output_data=$2, updated_at=NOW() WHERE id=$3",
-		"completed", map[string]string{"audio_url": audioURL}, taskID,
-	)
-	if err != nil {
-		log.Printf("Database update error: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database update error"})
+	log.Printf("TTS conversion succeeded. File path: %s, %d marks (%s)\n", filePath, len(marks), marksSource)
+
+	// Client (management-api) đã huỷ request: bỏ kết quả và xoá file audio
+	if err := c.Request.Context().Err(); err != nil {
+		log.Printf("Request cancelled, discarding %s: %v\n", filePath, err)
+		os.Remove(filePath)
 		return
 	}
-	log.Printf("Task ID %d updated successfully\n", taskID)
+
+	audioURL := fmt.Sprintf("http://localhost:5001/audio/%s.mp3", audioPath)
+	resp := ConvertResponse{AudioURL: audioURL, Marks: marks, MarksSource: marksSource}
 
 	// Trả về kết quả
 	log.Printf("Returning response with audio URL: %s\n", audioURL)
-	c.JSON(http.StatusOK, ConvertResponse{AudioURL: audioURL})
+	c.JSON(http.StatusOK, resp)
 }
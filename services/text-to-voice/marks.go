@@ -0,0 +1,307 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+const (
+	markTypeWord     = "word"
+	markTypeSentence = "sentence"
+
+	// marksSourceEstimated cho biết mốc được ước lượng từ audio, không phải mốc chính xác của engine
+	marksSourceEstimated = "estimated"
+
+	// Độ dài mỗi khung khi tính năng lượng tín hiệu
+	envelopeFrameMs = 10
+	// Khoảng lặng tối thiểu để coi là chỗ ngắt giữa các câu / cụm từ
+	minPauseMs = 120
+	// Khoảng cách tối đa giữa vị trí ước lượng và khoảng lặng khi căn chỉnh
+	pauseSnapWindowMs = 400
+)
+
+// Mark là mốc thời gian của một từ hoặc một câu trong file audio.
+// CharStart/CharEnd là vị trí rune trong text gốc, theo dạng [start, end).
+type Mark struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	StartMs   int    `json:"start_ms"`
+	EndMs     int    `json:"end_ms"`
+	CharStart int    `json:"char_start"`
+	CharEnd   int    `json:"char_end"`
+}
+
+// textSpan là một đoạn text (từ hoặc câu) cùng vị trí rune của nó
+type textSpan struct {
+	value      string
+	start, end int
+	// pauseAfter cho biết sau từ này có dấu câu, nơi người đọc thường ngắt hơi
+	pauseAfter bool
+	sentenceID int
+}
+
+// pause là một khoảng lặng trong audio, tính theo khung envelope
+type pause struct {
+	start, end int
+}
+
+// estimateMarks ước lượng mốc thời gian cho từng từ và từng câu bằng cách
+// căn chỉnh text với audio: bỏ khoảng lặng đầu/cuối, chia thời lượng theo độ dài
+// từ và neo các dấu câu vào những khoảng lặng gần nhất trong audio.
+func estimateMarks(text, audioPath string) ([]Mark, error) {
+	words := splitWords(text)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	envelope, err := loadEnvelope(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope) == 0 {
+		return nil, nil
+	}
+
+	threshold := silenceThreshold(envelope)
+	speechStart, speechEnd := speechBounds(envelope, threshold)
+	pauses := findPauses(envelope[speechStart:speechEnd], threshold)
+	for i := range pauses {
+		pauses[i].start += speechStart
+		pauses[i].end += speechStart
+	}
+
+	wordStarts, wordEnds := alignWords(words, float64(speechStart), float64(speechEnd), pauses)
+
+	marks := make([]Mark, 0, len(words)*2)
+	for i, w := range words {
+		marks = append(marks, Mark{
+			Type:      markTypeWord,
+			Value:     w.value,
+			StartMs:   int(math.Round(wordStarts[i] * envelopeFrameMs)),
+			EndMs:     int(math.Round(wordEnds[i] * envelopeFrameMs)),
+			CharStart: w.start,
+			CharEnd:   w.end,
+		})
+	}
+
+	return append(marks, sentenceMarks(text, words, marks)...), nil
+}
+
+// alignWords trả về thời điểm bắt đầu/kết thúc (theo khung envelope) của từng từ
+func alignWords(words []textSpan, speechStart, speechEnd float64, pauses []pause) ([]float64, []float64) {
+	starts := make([]float64, len(words))
+	ends := make([]float64, len(words))
+
+	// Ước lượng ban đầu: chia đều thời lượng theo số rune của từng từ
+	weights := make([]float64, len(words))
+	total := 0.0
+	for i, w := range words {
+		weights[i] = float64(w.end - w.start)
+		total += weights[i]
+	}
+
+	// Neo: chỉ số từ bắt đầu sau mốc -> thời điểm (khung). Luôn có neo đầu và cuối.
+	type anchor struct {
+		word      int
+		prevEnd   float64
+		nextStart float64
+	}
+	anchors := []anchor{{word: 0, prevEnd: speechStart, nextStart: speechStart}}
+
+	window := float64(pauseSnapWindowMs / envelopeFrameMs)
+	cumulative := 0.0
+	used := 0
+	for i, w := range words[:len(words)-1] {
+		cumulative += weights[i]
+		if !w.pauseAfter {
+			continue
+		}
+		predicted := speechStart + (speechEnd-speechStart)*cumulative/total
+		best := -1
+		for j := used; j < len(pauses); j++ {
+			mid := float64(pauses[j].start+pauses[j].end) / 2
+			if math.Abs(mid-predicted) > window {
+				continue
+			}
+			if best < 0 || math.Abs(mid-predicted) < math.Abs(float64(pauses[best].start+pauses[best].end)/2-predicted) {
+				best = j
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		anchors = append(anchors, anchor{
+			word:      i + 1,
+			prevEnd:   float64(pauses[best].start),
+			nextStart: float64(pauses[best].end),
+		})
+		used = best + 1
+	}
+	anchors = append(anchors, anchor{word: len(words), prevEnd: speechEnd, nextStart: speechEnd})
+
+	// Giữa hai neo liên tiếp, chia thời lượng theo trọng số của các từ
+	for a := 0; a < len(anchors)-1; a++ {
+		from, to := anchors[a], anchors[a+1]
+		segWeight := 0.0
+		for i := from.word; i < to.word; i++ {
+			segWeight += weights[i]
+		}
+		cursor := from.nextStart
+		span := to.prevEnd - from.nextStart
+		for i := from.word; i < to.word; i++ {
+			starts[i] = cursor
+			if segWeight > 0 {
+				cursor += span * weights[i] / segWeight
+			}
+			ends[i] = cursor
+		}
+	}
+
+	return starts, ends
+}
+
+// sentenceMarks gộp các mốc từ thành mốc câu
+func sentenceMarks(text string, words []textSpan, wordMarks []Mark) []Mark {
+	runes := []rune(text)
+	var marks []Mark
+	for i := 0; i < len(words); {
+		j := i
+		for j+1 < len(words) && words[j+1].sentenceID == words[i].sentenceID {
+			j++
+		}
+		marks = append(marks, Mark{
+			Type:      markTypeSentence,
+			Value:     string(runes[words[i].start:words[j].end]),
+			StartMs:   wordMarks[i].StartMs,
+			EndMs:     wordMarks[j].EndMs,
+			CharStart: words[i].start,
+			CharEnd:   words[j].end,
+		})
+		i = j + 1
+	}
+	return marks
+}
+
+// splitWords tách text thành các từ theo khoảng trắng, kèm vị trí rune và câu chứa từ
+func splitWords(text string) []textSpan {
+	var words []textSpan
+	runes := []rune(text)
+	sentence := 0
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			i++
+		}
+		value := string(runes[start:i])
+		words = append(words, textSpan{
+			value:      value,
+			start:      start,
+			end:        i,
+			pauseAfter: unicode.IsPunct(runes[i-1]),
+			sentenceID: sentence,
+		})
+		// Bỏ qua dấu đóng ngoặc / nháy khi kiểm tra kết thúc câu, ví dụ `"Xin chào."`
+		if core := []rune(strings.TrimRight(value, "\"')]»”’")); len(core) > 0 && strings.ContainsRune(".!?…", core[len(core)-1]) {
+			sentence++
+		}
+	}
+	return words
+}
+
+// loadEnvelope giải mã file mp3 và trả về năng lượng RMS cho mỗi khung envelopeFrameMs
+func loadEnvelope(audioPath string) ([]float64, error) {
+	f, err := os.Open(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	decoder, err := mp3.NewDecoder(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// go-mp3 luôn trả về PCM 16 bit, 2 kênh => 4 byte mỗi mẫu
+	samplesPerFrame := decoder.SampleRate() * envelopeFrameMs / 1000
+	buf := make([]byte, samplesPerFrame*4)
+
+	var envelope []float64
+	for {
+		n, err := io.ReadFull(decoder, buf)
+		if n >= 4 {
+			sum := 0.0
+			count := n / 4
+			for i := 0; i < count; i++ {
+				left := float64(int16(binary.LittleEndian.Uint16(buf[i*4:])))
+				right := float64(int16(binary.LittleEndian.Uint16(buf[i*4+2:])))
+				mono := (left + right) / 2
+				sum += mono * mono
+			}
+			envelope = append(envelope, math.Sqrt(sum/float64(count)))
+		}
+		if err != nil {
+			// Audio của Google TTS được ghép từ nhiều đoạn mp3, nên lỗi ở giữa
+			// luồng được coi là hết dữ liệu nếu đã đọc được một phần
+			if err == io.EOF || err == io.ErrUnexpectedEOF || len(envelope) > 0 {
+				break
+			}
+			return nil, err
+		}
+	}
+
+	return envelope, nil
+}
+
+// silenceThreshold chọn ngưỡng im lặng tương đối theo mức năng lượng cao nhất
+func silenceThreshold(envelope []float64) float64 {
+	peak := 0.0
+	for _, v := range envelope {
+		peak = math.Max(peak, v)
+	}
+	return peak * 0.05
+}
+
+// speechBounds trả về khung đầu tiên và sau khung cuối cùng có tiếng nói
+func speechBounds(envelope []float64, threshold float64) (int, int) {
+	start, end := 0, len(envelope)
+	for start < end && envelope[start] < threshold {
+		start++
+	}
+	for end > start && envelope[end-1] < threshold {
+		end--
+	}
+	if start == end {
+		return 0, len(envelope)
+	}
+	return start, end
+}
+
+// findPauses tìm các khoảng lặng đủ dài bên trong vùng có tiếng nói
+func findPauses(envelope []float64, threshold float64) []pause {
+	minFrames := minPauseMs / envelopeFrameMs
+	var pauses []pause
+	for i := 0; i < len(envelope); {
+		if envelope[i] >= threshold {
+			i++
+			continue
+		}
+		start := i
+		for i < len(envelope) && envelope[i] < threshold {
+			i++
+		}
+		if i-start >= minFrames {
+			pauses = append(pauses, pause{start: start, end: i})
+		}
+	}
+	return pauses
+}